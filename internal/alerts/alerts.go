@@ -0,0 +1,108 @@
+// Package alerts flags source addresses that log more events within a
+// sliding window than a configured threshold, a typical sign of a scan.
+package alerts
+
+import (
+	"sort"
+	"time"
+)
+
+// Window is the length of the sliding window rates are measured over.
+const Window = time.Minute
+
+// Tracker counts events per source IP over the last Window, measured by
+// event timestamp so replayed history behaves like a live tail. Timestamps
+// older than the window are pruned on every Add and idle addresses are swept
+// once per window, so memory is bounded by the traffic of one window plus
+// the set of addresses ever flagged. It is not safe for concurrent use.
+type Tracker struct {
+	threshold int
+	recent    map[string][]time.Time
+	noisy     map[string]int // flagged IP → highest count seen in one window
+	lastSweep time.Time
+}
+
+// NewTracker returns a Tracker that flags an IP once it logs more than
+// threshold events within Window. A threshold of 0 disables tracking.
+func NewTracker(threshold int) *Tracker {
+	return &Tracker{
+		threshold: threshold,
+		recent:    make(map[string][]time.Time),
+		noisy:     make(map[string]int),
+	}
+}
+
+// Threshold returns the configured events-per-window limit.
+func (t *Tracker) Threshold() int {
+	return t.threshold
+}
+
+// Add records an event from ip at ts and reports whether ip is over the
+// threshold as a result.
+func (t *Tracker) Add(ip string, ts time.Time) bool {
+	if t.threshold <= 0 || ip == "" {
+		return false
+	}
+	cutoff := ts.Add(-Window)
+	times := append(prune(t.recent[ip], cutoff), ts)
+	t.recent[ip] = times
+
+	if ts.Sub(t.lastSweep) > Window {
+		for k, v := range t.recent {
+			if v = prune(v, cutoff); len(v) == 0 {
+				delete(t.recent, k)
+			} else {
+				t.recent[k] = v
+			}
+		}
+		t.lastSweep = ts
+	}
+
+	if len(times) <= t.threshold {
+		return false
+	}
+	if len(times) > t.noisy[ip] {
+		t.noisy[ip] = len(times)
+	}
+	return true
+}
+
+// IsNoisy reports whether ip has ever crossed the threshold.
+func (t *Tracker) IsNoisy(ip string) bool {
+	_, ok := t.noisy[ip]
+	return ok
+}
+
+// Source is a flagged address and its peak count within one window.
+type Source struct {
+	IP   string
+	Peak int
+}
+
+// Noisy returns every flagged address, highest peak first.
+func (t *Tracker) Noisy() []Source {
+	out := make([]Source, 0, len(t.noisy))
+	for ip, peak := range t.noisy {
+		out = append(out, Source{ip, peak})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Peak != out[j].Peak {
+			return out[i].Peak > out[j].Peak
+		}
+		return out[i].IP < out[j].IP
+	})
+	return out
+}
+
+// prune drops timestamps at or before cutoff. Times arrive roughly in order,
+// so the oldest are at the front.
+func prune(times []time.Time, cutoff time.Time) []time.Time {
+	i := 0
+	for i < len(times) && !times[i].After(cutoff) {
+		i++
+	}
+	if i == len(times) {
+		return times[:0]
+	}
+	return times[i:]
+}
@@ -0,0 +1,57 @@
+package alerts
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTrackerFlagsBurst(t *testing.T) {
+	tr := NewTracker(3)
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 3; i++ {
+		if tr.Add("203.0.113.9", base.Add(time.Duration(i)*time.Second)) {
+			t.Fatalf("event %d flagged before crossing the threshold", i+1)
+		}
+	}
+	if !tr.Add("203.0.113.9", base.Add(3*time.Second)) {
+		t.Fatal("4th event within a minute not flagged")
+	}
+	if !tr.IsNoisy("203.0.113.9") || tr.IsNoisy("198.51.100.1") {
+		t.Error("IsNoisy mismatch")
+	}
+	if got := tr.Noisy(); len(got) != 1 || got[0].Peak != 4 {
+		t.Errorf("Noisy = %+v, want one source with peak 4", got)
+	}
+}
+
+func TestTrackerSlidingWindowPrunes(t *testing.T) {
+	tr := NewTracker(2)
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+
+	// Slow trickle: one event every 40s never exceeds 2 per minute.
+	for i := 0; i < 10; i++ {
+		if tr.Add("192.0.2.1", base.Add(time.Duration(i)*40*time.Second)) {
+			t.Fatalf("event %d flagged for a slow source", i+1)
+		}
+	}
+	if n := len(tr.recent["192.0.2.1"]); n > 2 {
+		t.Errorf("recent holds %d timestamps, want at most 2", n)
+	}
+
+	// An idle address is swept once the window has passed.
+	tr.Add("192.0.2.2", base.Add(time.Hour))
+	tr.Add("192.0.2.3", base.Add(2*time.Hour))
+	if _, ok := tr.recent["192.0.2.1"]; ok {
+		t.Error("idle address not swept")
+	}
+}
+
+func TestTrackerDisabled(t *testing.T) {
+	tr := NewTracker(0)
+	for i := 0; i < 100; i++ {
+		if tr.Add("203.0.113.9", time.Now()) {
+			t.Fatal("disabled tracker flagged an address")
+		}
+	}
+}
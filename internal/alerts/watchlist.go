@@ -0,0 +1,150 @@
+package alerts
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// DefaultOffenderTTL is how long a source stays on the watchlist after it
+// last crossed the alert threshold.
+const DefaultOffenderTTL = 30 * 24 * time.Hour
+
+// Watchlist remembers sources that crossed the alert threshold, across
+// sessions, so they can be marked as soon as they turn up again. Entries
+// expire TTL after they were last flagged. It is not safe for concurrent use.
+type Watchlist struct {
+	path string
+	ttl  time.Duration
+	// known are the offenders loaded at startup; flagged holds every entry
+	// to save, including this session's.
+	known   map[string]time.Time
+	flagged map[string]time.Time
+	seen    map[string]int // known offender → events this session
+}
+
+// Sighting is a known offender seen in this session: how many events it has
+// logged and when it was last flagged in an earlier one.
+type Sighting struct {
+	IP      string
+	Events  int
+	Flagged time.Time
+}
+
+// DefaultWatchlistPath returns $XDG_CACHE_HOME/iptables-log-tui/offenders.json
+// (or the platform equivalent reported by os.UserCacheDir).
+func DefaultWatchlistPath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "iptables-log-tui", "offenders.json"), nil
+}
+
+// LoadWatchlist reads the watchlist file at path, dropping entries older
+// than ttl. A missing file yields an empty watchlist; any other read or
+// decode error is returned alongside an empty watchlist that is still usable
+// (and will overwrite the bad file on save).
+func LoadWatchlist(path string, ttl time.Duration) (*Watchlist, error) {
+	w := &Watchlist{
+		path:    path,
+		ttl:     ttl,
+		known:   make(map[string]time.Time),
+		flagged: make(map[string]time.Time),
+		seen:    make(map[string]int),
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return w, nil
+	}
+	if err != nil {
+		return w, err
+	}
+	var saved map[string]time.Time
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return w, err
+	}
+	for ip, at := range saved {
+		if w.fresh(at) {
+			w.known[ip] = at
+			w.flagged[ip] = at
+		}
+	}
+	return w, nil
+}
+
+// SaveWatchlist writes w back to its file, dropping expired entries. The
+// write goes through a temporary file and a rename, as for the whois cache.
+func SaveWatchlist(w *Watchlist) error {
+	fresh := make(map[string]time.Time, len(w.flagged))
+	for ip, at := range w.flagged {
+		if w.fresh(at) {
+			fresh[ip] = at
+		}
+	}
+	data, err := json.MarshalIndent(fresh, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(w.path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(w.path), ".offenders-*.json")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), w.path)
+}
+
+// Known reports whether ip was flagged in an earlier session.
+func (w *Watchlist) Known(ip string) bool {
+	_, ok := w.known[ip]
+	return ok
+}
+
+// Flag records that ip crossed the threshold at ts, keeping it on the list
+// for another TTL from then.
+func (w *Watchlist) Flag(ip string, ts time.Time) {
+	if ts.After(w.flagged[ip]) {
+		w.flagged[ip] = ts
+	}
+}
+
+// See counts an event from ip if it is a known offender.
+func (w *Watchlist) See(ip string) {
+	if w.Known(ip) {
+		w.seen[ip]++
+	}
+}
+
+// Seen returns the known offenders that have logged events this session,
+// busiest first.
+func (w *Watchlist) Seen() []Sighting {
+	out := make([]Sighting, 0, len(w.seen))
+	for ip, n := range w.seen {
+		out = append(out, Sighting{IP: ip, Events: n, Flagged: w.known[ip]})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Events != out[j].Events {
+			return out[i].Events > out[j].Events
+		}
+		return out[i].IP < out[j].IP
+	})
+	return out
+}
+
+func (w *Watchlist) fresh(at time.Time) bool {
+	return time.Since(at) <= w.ttl
+}
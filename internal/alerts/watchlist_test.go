@@ -0,0 +1,70 @@
+package alerts
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestWatchlistRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "offenders.json")
+	w, err := LoadWatchlist(path, time.Hour)
+	if err != nil {
+		t.Fatalf("LoadWatchlist on missing file: %v", err)
+	}
+	now := time.Now()
+	w.Flag("203.0.113.9", now.Add(-time.Minute))
+	w.Flag("203.0.113.9", now.Add(-10*time.Minute)) // an older flag does not win
+	w.Flag("192.0.2.1", now.Add(-2*time.Hour))      // already expired
+	if w.Known("203.0.113.9") {
+		t.Error("an offender flagged this session counts as known")
+	}
+	if err := SaveWatchlist(w); err != nil {
+		t.Fatalf("SaveWatchlist: %v", err)
+	}
+
+	w2, err := LoadWatchlist(path, time.Hour)
+	if err != nil {
+		t.Fatalf("LoadWatchlist: %v", err)
+	}
+	if !w2.Known("203.0.113.9") {
+		t.Error("offender from the last session not known")
+	}
+	if w2.Known("192.0.2.1") {
+		t.Error("expired offender survived a save")
+	}
+	if !w2.known["203.0.113.9"].Equal(now.Add(-time.Minute)) {
+		t.Errorf("flagged at %v, want the newest flag", w2.known["203.0.113.9"])
+	}
+}
+
+func TestWatchlistExpiresOnLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "offenders.json")
+	old := time.Now().Add(-48 * time.Hour).Format(time.RFC3339)
+	if err := os.WriteFile(path, []byte(`{"203.0.113.9": "`+old+`"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if w, _ := LoadWatchlist(path, 24*time.Hour); w.Known("203.0.113.9") {
+		t.Error("entry older than the TTL loaded")
+	}
+	if w, _ := LoadWatchlist(path, 72*time.Hour); !w.Known("203.0.113.9") {
+		t.Error("entry within the TTL not loaded")
+	}
+}
+
+func TestWatchlistSeen(t *testing.T) {
+	flagged := time.Now().Add(-time.Hour)
+	w, _ := LoadWatchlist(filepath.Join(t.TempDir(), "offenders.json"), DefaultOffenderTTL)
+	w.known["203.0.113.9"] = flagged
+	w.known["198.51.100.7"] = flagged
+
+	w.See("198.51.100.7")
+	w.See("203.0.113.9")
+	w.See("203.0.113.9")
+	w.See("192.0.2.1") // not an offender
+	got := w.Seen()
+	if len(got) != 2 || got[0].IP != "203.0.113.9" || got[0].Events != 2 || !got[0].Flagged.Equal(flagged) || got[1].IP != "198.51.100.7" {
+		t.Errorf("Seen() = %+v", got)
+	}
+}
@@ -0,0 +1,178 @@
+// Package blocklist accumulates offending IP addresses during a session and
+// formats them for firewall automation tools.
+package blocklist
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"strings"
+)
+
+// SetName is the base name used for generated ipset and nftables sets.
+// IPv6 addresses go into a companion set with a "6" suffix.
+const SetName = "iptables_log_tui"
+
+// List is an insertion-ordered set of IP addresses.
+type List struct {
+	ips  []string
+	seen map[string]bool
+}
+
+// ErrNotAddress is returned by Add for a value that is not a plain IP
+// address, so it never reaches an exported ruleset.
+var ErrNotAddress = errors.New("not an IP address")
+
+// New returns an empty List.
+func New() *List {
+	return &List{seen: make(map[string]bool)}
+}
+
+// Add appends ip in its canonical form (an IPv4-mapped IPv6 address becomes
+// IPv4) if it is not already present. It returns that form and whether it
+// was added, or ErrNotAddress if ip is not an IP address.
+func (l *List) Add(ip string) (canonical string, added bool, err error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil || addr.Zone() != "" {
+		return "", false, ErrNotAddress
+	}
+	canonical = addr.Unmap().String()
+	if l.seen[canonical] {
+		return canonical, false, nil
+	}
+	l.seen[canonical] = true
+	l.ips = append(l.ips, canonical)
+	return canonical, true, nil
+}
+
+// Remove deletes ip from the list, preserving the order of the rest.
+func (l *List) Remove(ip string) {
+	if !l.seen[ip] {
+		return
+	}
+	delete(l.seen, ip)
+	for i, v := range l.ips {
+		if v == ip {
+			l.ips = append(l.ips[:i], l.ips[i+1:]...)
+			return
+		}
+	}
+}
+
+// Contains reports whether ip is in the list.
+func (l *List) Contains(ip string) bool { return l.seen[ip] }
+
+// Len returns the number of addresses in the list.
+func (l *List) Len() int { return len(l.ips) }
+
+// IPs returns a copy of the addresses in insertion order.
+func (l *List) IPs() []string {
+	return append([]string(nil), l.ips...)
+}
+
+// Format identifies an export format.
+type Format struct {
+	Name  string // human-readable name
+	Ext   string // file extension without the dot
+	Write func(w io.Writer, ips []string) error
+}
+
+// Formats lists the supported export formats.
+var Formats = []Format{
+	{"plain", "txt", WritePlain},
+	{"ipset", "ipset", WriteIPSet},
+	{"nftables", "nft", WriteNFT},
+	{"csv", "csv", WriteCSV},
+}
+
+// WritePlain writes one address per line.
+func WritePlain(w io.Writer, ips []string) error {
+	for _, ip := range ips {
+		if _, err := fmt.Fprintln(w, ip); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteIPSet writes an `ipset restore` file creating one hash:ip set per
+// address family and adding every address to it.
+func WriteIPSet(w io.Writer, ips []string) error {
+	v4, v6 := splitFamilies(ips)
+	var sb strings.Builder
+	if len(v4) > 0 {
+		fmt.Fprintf(&sb, "create %s hash:ip family inet -exist\n", SetName)
+		for _, ip := range v4 {
+			fmt.Fprintf(&sb, "add %s %s -exist\n", SetName, ip)
+		}
+	}
+	if len(v6) > 0 {
+		fmt.Fprintf(&sb, "create %s6 hash:ip family inet6 -exist\n", SetName)
+		for _, ip := range v6 {
+			fmt.Fprintf(&sb, "add %s6 %s -exist\n", SetName, ip)
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteNFT writes an `nft -f` script that adds the addresses to named sets in
+// the "inet filter" table, creating the table and sets if needed.
+func WriteNFT(w io.Writer, ips []string) error {
+	v4, v6 := splitFamilies(ips)
+	var sb strings.Builder
+	sb.WriteString("add table inet filter\n")
+	if len(v4) > 0 {
+		fmt.Fprintf(&sb, "add set inet filter %s { type ipv4_addr; }\n", SetName)
+		fmt.Fprintf(&sb, "add element inet filter %s { %s }\n", SetName, strings.Join(v4, ", "))
+	}
+	if len(v6) > 0 {
+		fmt.Fprintf(&sb, "add set inet filter %s6 { type ipv6_addr; }\n", SetName)
+		fmt.Fprintf(&sb, "add element inet filter %s6 { %s }\n", SetName, strings.Join(v6, ", "))
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// WriteCSV writes a header row followed by one "ip,family" row per address.
+func WriteCSV(w io.Writer, ips []string) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"ip", "family"}); err != nil {
+		return err
+	}
+	for _, ip := range ips {
+		family := "ipv4"
+		if isIPv6(ip) {
+			family = "ipv6"
+		}
+		if err := cw.Write([]string{ip, family}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// splitFamilies partitions ips into IPv4 and IPv6 addresses, preserving order.
+// Unparseable values are dropped so they never reach a firewall ruleset.
+func splitFamilies(ips []string) (v4, v6 []string) {
+	for _, ip := range ips {
+		parsed := net.ParseIP(ip)
+		switch {
+		case parsed == nil:
+		case parsed.To4() != nil:
+			v4 = append(v4, ip)
+		default:
+			v6 = append(v6, ip)
+		}
+	}
+	return v4, v6
+}
+
+func isIPv6(ip string) bool {
+	parsed := net.ParseIP(ip)
+	return parsed != nil && parsed.To4() == nil
+}
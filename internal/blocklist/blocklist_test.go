@@ -0,0 +1,103 @@
+package blocklist
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestListDedupAndOrder(t *testing.T) {
+	l := New()
+	for _, ip := range []string{"5.6.7.8", "1.2.3.4", "5.6.7.8", ""} {
+		l.Add(ip)
+	}
+	got := strings.Join(l.IPs(), ",")
+	if got != "5.6.7.8,1.2.3.4" {
+		t.Errorf("IPs: got %q", got)
+	}
+	l.Remove("5.6.7.8")
+	if l.Contains("5.6.7.8") || l.Len() != 1 {
+		t.Errorf("Remove: list still contains removed IP: %v", l.IPs())
+	}
+}
+
+func TestAddRejectsNonAddress(t *testing.T) {
+	l := New()
+	for _, ip := range []string{"1.2.3.4;rm -rf /", "-1.2.3.4", "example.com", "fe80::1%eth0", "203.0.113.0/24"} {
+		if _, added, err := l.Add(ip); added || err != ErrNotAddress {
+			t.Errorf("Add(%q) = %v, %v; want ErrNotAddress", ip, added, err)
+		}
+	}
+	if canonical, added, _ := l.Add("::ffff:203.0.113.7"); !added || canonical != "203.0.113.7" {
+		t.Errorf("mapped address: added %v as %q", added, canonical)
+	}
+	if _, added, err := l.Add("203.0.113.7"); added || err != nil {
+		t.Errorf("canonical duplicate: added %v, err %v", added, err)
+	}
+	if got := strings.Join(l.IPs(), ","); got != "203.0.113.7" {
+		t.Errorf("IPs: got %q, want the canonical form only", got)
+	}
+}
+
+var testIPs = []string{"203.0.113.7", "2001:db8::1", "198.51.100.9"}
+
+func TestWritePlain(t *testing.T) {
+	var sb strings.Builder
+	if err := WritePlain(&sb, testIPs); err != nil {
+		t.Fatal(err)
+	}
+	want := "203.0.113.7\n2001:db8::1\n198.51.100.9\n"
+	if sb.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", sb.String(), want)
+	}
+}
+
+func TestWriteIPSet(t *testing.T) {
+	var sb strings.Builder
+	if err := WriteIPSet(&sb, testIPs); err != nil {
+		t.Fatal(err)
+	}
+	want := "create iptables_log_tui hash:ip family inet -exist\n" +
+		"add iptables_log_tui 203.0.113.7 -exist\n" +
+		"add iptables_log_tui 198.51.100.9 -exist\n" +
+		"create iptables_log_tui6 hash:ip family inet6 -exist\n" +
+		"add iptables_log_tui6 2001:db8::1 -exist\n"
+	if sb.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", sb.String(), want)
+	}
+}
+
+func TestWriteNFT(t *testing.T) {
+	var sb strings.Builder
+	if err := WriteNFT(&sb, testIPs); err != nil {
+		t.Fatal(err)
+	}
+	want := "add table inet filter\n" +
+		"add set inet filter iptables_log_tui { type ipv4_addr; }\n" +
+		"add element inet filter iptables_log_tui { 203.0.113.7, 198.51.100.9 }\n" +
+		"add set inet filter iptables_log_tui6 { type ipv6_addr; }\n" +
+		"add element inet filter iptables_log_tui6 { 2001:db8::1 }\n"
+	if sb.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", sb.String(), want)
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	var sb strings.Builder
+	if err := WriteCSV(&sb, testIPs); err != nil {
+		t.Fatal(err)
+	}
+	want := "ip,family\n203.0.113.7,ipv4\n2001:db8::1,ipv6\n198.51.100.9,ipv4\n"
+	if sb.String() != want {
+		t.Errorf("got:\n%s\nwant:\n%s", sb.String(), want)
+	}
+}
+
+func TestWriteSkipsInvalidAddresses(t *testing.T) {
+	var sb strings.Builder
+	if err := WriteIPSet(&sb, []string{"not-an-ip"}); err != nil {
+		t.Fatal(err)
+	}
+	if sb.String() != "" {
+		t.Errorf("expected no output for invalid address, got:\n%s", sb.String())
+	}
+}
@@ -0,0 +1,81 @@
+package classifier
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+)
+
+// Blocklist is a set of known-bad networks loaded from a --blocklist file.
+type Blocklist struct{ nets []*net.IPNet }
+
+// LoadBlocklist reads one CIDR per line from path; blank lines and # comments
+// are ignored, and a bare address counts as a single-host network. The first
+// malformed line is an error naming the file and line.
+func LoadBlocklist(path string) (*Blocklist, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	b := &Blocklist{}
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		cidr := line
+		if !strings.Contains(cidr, "/") {
+			if ip := net.ParseIP(cidr); ip != nil && ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+		_, ipnet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid CIDR %q", path, n, line)
+		}
+		b.nets = append(b.nets, ipnet)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// Len returns the number of networks in the list.
+func (b *Blocklist) Len() int {
+	if b == nil {
+		return 0
+	}
+	return len(b.nets)
+}
+
+// Match returns the most specific network containing ipStr in CIDR form, or
+// "" when none does (or b is nil).
+func (b *Blocklist) Match(ipStr string) string {
+	if b == nil {
+		return ""
+	}
+	ip := net.ParseIP(ipStr)
+	if ip == nil {
+		return ""
+	}
+	var best *net.IPNet
+	bestBits := -1
+	for _, n := range b.nets {
+		if bits, _ := n.Mask.Size(); bits > bestBits && n.Contains(ip) {
+			best, bestBits = n, bits
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	return best.String()
+}
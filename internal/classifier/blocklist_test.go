@@ -0,0 +1,62 @@
+package classifier
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeBlocklist(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "bad.txt")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestBlocklistMatch(t *testing.T) {
+	path := writeBlocklist(t, `# known scanners
+203.0.113.0/24
+203.0.113.128/25   # narrower, wins
+198.51.100.7
+2001:db8:bad::/48
+`)
+	b, err := LoadBlocklist(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Len() != 4 {
+		t.Errorf("Len = %d, want 4", b.Len())
+	}
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{"203.0.113.5", "203.0.113.0/24"},
+		{"203.0.113.200", "203.0.113.128/25"},
+		{"198.51.100.7", "198.51.100.7/32"},
+		{"198.51.100.8", ""},
+		{"2001:db8:bad::1", "2001:db8:bad::/48"},
+		{"not-an-ip", ""},
+	}
+	for _, tc := range tests {
+		if got := b.Match(tc.ip); got != tc.want {
+			t.Errorf("Match(%q) = %q, want %q", tc.ip, got, tc.want)
+		}
+	}
+
+	var none *Blocklist
+	if got := none.Match("203.0.113.5"); got != "" {
+		t.Errorf("nil Blocklist matched %q", got)
+	}
+}
+
+func TestLoadBlocklistMalformed(t *testing.T) {
+	path := writeBlocklist(t, "10.0.0.0/8\n\n300.1.2.0/24\n")
+	_, err := LoadBlocklist(path)
+	if err == nil || !strings.Contains(err.Error(), "bad.txt:3:") || !strings.Contains(err.Error(), "300.1.2.0/24") {
+		t.Errorf("err = %v, want one naming line 3 and the bad CIDR", err)
+	}
+}
@@ -4,16 +4,35 @@ import "net"
 
 const (
 	CatInternal  = "Internal"
+	CatPrivate   = "Private"
 	CatMulticast = "Multicast"
 	CatExternal  = "External"
 )
 
-var multicastRanges []*net.IPNet
+var multicastRanges, privateRanges []*net.IPNet
 
 func init() {
-	_, v4, _ := net.ParseCIDR("224.0.0.0/4")
-	_, v6, _ := net.ParseCIDR("ff00::/8")
-	multicastRanges = []*net.IPNet{v4, v6}
+	multicastRanges = mustParseCIDRs("224.0.0.0/4", "ff00::/8")
+	// RFC 1918, loopback, link-local, and IPv6 unique-local addresses: not
+	// routable on the internet, but not necessarily on one of our interfaces.
+	privateRanges = mustParseCIDRs(
+		"10.0.0.0/8", "172.16.0.0/12", "192.168.0.0/16",
+		"127.0.0.0/8", "::1/128",
+		"169.254.0.0/16", "fe80::/10",
+		"fc00::/7",
+	)
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, 0, len(cidrs))
+	for _, c := range cidrs {
+		_, n, err := net.ParseCIDR(c)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
 }
 
 type Classifier struct{ subnets []*net.IPNet }
@@ -50,5 +69,10 @@ func (c *Classifier) Categorize(ipStr string) string {
 			return CatInternal
 		}
 	}
+	for _, r := range privateRanges {
+		if r.Contains(ip) {
+			return CatPrivate
+		}
+	}
 	return CatExternal
 }
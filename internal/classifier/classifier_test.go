@@ -0,0 +1,38 @@
+package classifier
+
+import (
+	"net"
+	"testing"
+)
+
+func TestCategorize(t *testing.T) {
+	_, local, _ := net.ParseCIDR("192.168.1.0/24")
+	c := &Classifier{subnets: []*net.IPNet{local}}
+
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{"192.168.1.20", CatInternal}, // on an interface subnet wins over private
+		{"10.1.2.3", CatPrivate},
+		{"172.16.0.1", CatPrivate},
+		{"172.31.255.254", CatPrivate},
+		{"172.32.0.1", CatExternal},
+		{"192.168.50.1", CatPrivate},
+		{"127.0.0.1", CatPrivate},
+		{"::1", CatPrivate},
+		{"169.254.10.10", CatPrivate},
+		{"fe80::1", CatPrivate},
+		{"fd12:3456::1", CatPrivate},
+		{"224.0.0.251", CatMulticast},
+		{"ff02::fb", CatMulticast},
+		{"203.0.113.7", CatExternal},
+		{"2001:db8::1", CatExternal},
+		{"not-an-ip", CatExternal},
+	}
+	for _, tc := range tests {
+		if got := c.Categorize(tc.ip); got != tc.want {
+			t.Errorf("Categorize(%q): got %q, want %q", tc.ip, got, tc.want)
+		}
+	}
+}
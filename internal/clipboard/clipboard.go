@@ -0,0 +1,54 @@
+// Package clipboard copies text to the system clipboard by piping it to
+// whichever clipboard tool is installed.
+package clipboard
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// ErrUnavailable is returned when no supported clipboard tool is found, as
+// is usual on headless servers.
+var ErrUnavailable = errors.New("no clipboard tool found (install wl-copy, xclip or xsel)")
+
+// tool is a clipboard command and the arguments that make it read stdin.
+type tool struct {
+	name string
+	args []string
+}
+
+// candidates returns the tools to try, most specific to the session first.
+func candidates() []tool {
+	if runtime.GOOS == "darwin" {
+		return []tool{{"pbcopy", nil}}
+	}
+	var ts []tool
+	if os.Getenv("WAYLAND_DISPLAY") != "" {
+		ts = append(ts, tool{"wl-copy", nil})
+	}
+	if os.Getenv("DISPLAY") != "" {
+		ts = append(ts,
+			tool{"xclip", []string{"-selection", "clipboard"}},
+			tool{"xsel", []string{"--clipboard", "--input"}},
+		)
+	}
+	return ts
+}
+
+// Copy places text on the clipboard. It returns ErrUnavailable when there is
+// no display session or none of the supported tools is installed.
+func Copy(text string) error {
+	for _, t := range candidates() {
+		path, err := exec.LookPath(t.name)
+		if err != nil {
+			continue
+		}
+		cmd := exec.Command(path, t.args...)
+		cmd.Stdin = strings.NewReader(text)
+		return cmd.Run()
+	}
+	return ErrUnavailable
+}
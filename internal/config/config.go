@@ -0,0 +1,199 @@
+// Package config reads the optional config.toml that supplies defaults for
+// the command-line flags and custom key bindings.
+//
+// Only the subset of TOML the file needs is understood: comments, a [keys]
+// table, and key = value pairs whose value is a quoted string, a bare number
+// or boolean, or a one-line array of strings.
+package config
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/espenotterstad/iptables-log-tui/internal/keymap"
+)
+
+// Setting is one top-level key = value pair, named after a flag.
+type Setting struct {
+	Name  string
+	Value string // arrays are joined with ","
+	Line  int
+}
+
+// Config is the parsed contents of a config file.
+type Config struct {
+	Path     string
+	Settings []Setting
+	// Keys maps actions from the [keys] table to the keys bound to them.
+	Keys map[keymap.Action][]string
+}
+
+// DefaultPath returns $XDG_CONFIG_HOME/iptables-log-tui/config.toml (or the
+// platform equivalent reported by os.UserConfigDir).
+func DefaultPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "iptables-log-tui", "config.toml"), nil
+}
+
+// keyRe matches a bare TOML key.
+var keyRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Load parses the file at path. Errors name the file and line, e.g.
+// "config.toml:4: unknown action "serch"".
+func Load(path string) (*Config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	cfg := &Config{Path: path, Keys: make(map[keymap.Action][]string)}
+	section := ""
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(stripComment(sc.Text()))
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			section = strings.TrimSpace(strings.Trim(line, "[]"))
+			if section != "keys" {
+				return nil, fmt.Errorf("%s:%d: unknown table [%s]", path, n, section)
+			}
+			continue
+		}
+		name, raw, ok := strings.Cut(line, "=")
+		name, raw = strings.TrimSpace(name), strings.TrimSpace(raw)
+		if !ok || !keyRe.MatchString(name) {
+			return nil, fmt.Errorf("%s:%d: expected key = value, got %q", path, n, line)
+		}
+		values, err := parseValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %s: %v", path, n, name, err)
+		}
+		if section == "keys" {
+			if !keymap.Known(name) {
+				return nil, fmt.Errorf("%s:%d: unknown action %q", path, n, name)
+			}
+			cfg.Keys[keymap.Action(name)] = values
+			continue
+		}
+		cfg.Settings = append(cfg.Settings, Setting{Name: name, Value: strings.Join(values, ","), Line: n})
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// ApplyFlags sets every flag named in the file on fs, except those already
+// given on the command line, so flags override the file. A setting that
+// names no flag, or whose value the flag rejects, is an error.
+func (c *Config) ApplyFlags(fs *flag.FlagSet) error {
+	onCLI := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { onCLI[f.Name] = true })
+	for _, s := range c.Settings {
+		if fs.Lookup(s.Name) == nil {
+			return fmt.Errorf("%s:%d: unknown setting %q", c.Path, s.Line, s.Name)
+		}
+		if onCLI[s.Name] {
+			continue
+		}
+		if err := fs.Set(s.Name, s.Value); err != nil {
+			return fmt.Errorf("%s:%d: %s: %v", c.Path, s.Line, s.Name, err)
+		}
+	}
+	return nil
+}
+
+// stripComment drops a # comment that is not inside a quoted string.
+func stripComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++ // skip the escaped character
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == 0 && c == '#':
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// parseValue returns the value(s) of raw: one for a scalar, any number for
+// an array of strings.
+func parseValue(raw string) ([]string, error) {
+	if strings.HasPrefix(raw, "[") {
+		if !strings.HasSuffix(raw, "]") {
+			return nil, fmt.Errorf("unterminated array")
+		}
+		var out []string
+		for _, item := range splitArray(raw[1 : len(raw)-1]) {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			s, err := parseString(item)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	}
+	if strings.HasPrefix(raw, `"`) || strings.HasPrefix(raw, "'") {
+		s, err := parseString(raw)
+		return []string{s}, err
+	}
+	if raw == "" || strings.ContainsAny(raw, " \t\"'") {
+		return nil, fmt.Errorf("invalid value %q", raw)
+	}
+	return []string{raw}, nil // number or boolean, checked by the flag
+}
+
+// parseString unquotes a basic ("…", with escapes) or literal ('…') string.
+func parseString(s string) (string, error) {
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return s[1 : len(s)-1], nil
+	}
+	v, err := strconv.Unquote(s)
+	if err != nil || !strings.HasPrefix(s, `"`) {
+		return "", fmt.Errorf("invalid string %s", s)
+	}
+	return v, nil
+}
+
+// splitArray splits the inside of an array on commas outside quotes.
+func splitArray(s string) []string {
+	var out []string
+	var quote byte
+	start := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0 && c == quote:
+			quote = 0
+		case quote == 0 && (c == '"' || c == '\''):
+			quote = c
+		case quote == 0 && c == ',':
+			out = append(out, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(out, s[start:])
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/espenotterstad/iptables-log-tui/internal/keymap"
+)
+
+func writeConfig(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadAndApplyFlags(t *testing.T) {
+	cfg, err := Load(writeConfig(t, `
+# defaults
+file = "/var/log/ufw.log"   # trailing comment
+history = true
+poll-interval = "1s"
+theme = 'C:\themes\dark#1.json'
+
+[keys]
+search = "f"
+up = ["up", "i"]
+`))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	file := fs.String("file", "", "")
+	history := fs.Bool("history", false, "")
+	poll := fs.Duration("poll-interval", 0, "")
+	theme := fs.String("theme", "", "")
+	if err := fs.Parse([]string{"--file=/tmp/cli.log"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ApplyFlags(fs); err != nil {
+		t.Fatalf("ApplyFlags: %v", err)
+	}
+	if *file != "/tmp/cli.log" {
+		t.Errorf("file = %q, want the command-line value", *file)
+	}
+	if !*history || *poll != time.Second || *theme != `C:\themes\dark#1.json` {
+		t.Errorf("history=%v poll=%v theme=%q not taken from the file", *history, *poll, *theme)
+	}
+	if got := cfg.Keys[keymap.Up]; len(got) != 2 || got[1] != "i" {
+		t.Errorf("keys.up = %q", got)
+	}
+}
+
+func TestLoadErrorsNameTheLine(t *testing.T) {
+	tests := []struct {
+		name, body, want string
+	}{
+		{"unknown action", "[keys]\nserch = \"f\"\n", `:2: unknown action "serch"`},
+		{"unknown table", "[colors]\n", ":1: unknown table"},
+		{"bad line", "history\n", ":1: expected key = value"},
+		{"bad string", "theme = \"unterminated\n", ":1: theme: invalid string"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Load(writeConfig(t, tt.body))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+
+	cfg, err := Load(writeConfig(t, "\nmax-entires = 5\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Int("max-entries", 0, "")
+	if err := cfg.ApplyFlags(fs); err == nil || !strings.Contains(err.Error(), `:2: unknown setting "max-entires"`) {
+		t.Errorf("ApplyFlags error = %v", err)
+	}
+}
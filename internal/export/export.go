@@ -0,0 +1,110 @@
+// Package export writes parsed log entries to files for sharing findings.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
+)
+
+// record is the exported form of a LogEntry. It includes the computed action
+// and uses stable, lower-case field names.
+type record struct {
+	Timestamp time.Time `json:"timestamp"`
+	Hostname  string    `json:"hostname"`
+	Prefix    string    `json:"prefix"`
+	Action    string    `json:"action"`
+	In        string    `json:"in"`
+	Out       string    `json:"out"`
+	MAC       string    `json:"mac,omitempty"`
+	Src       string    `json:"src"`
+	Dst       string    `json:"dst"`
+	Proto     string    `json:"proto"`
+	SrcPort   int       `json:"spt,omitempty"`
+	DstPort   int       `json:"dpt,omitempty"`
+	TTL       int       `json:"ttl,omitempty"`
+	Len       int       `json:"len,omitempty"`
+	TCPFlags  []string  `json:"tcp_flags,omitempty"`
+	Raw       string    `json:"raw"`
+}
+
+func newRecord(e parser.LogEntry) record {
+	return record{
+		Timestamp: e.Timestamp,
+		Hostname:  e.Hostname,
+		Prefix:    e.Prefix,
+		Action:    e.Action(),
+		In:        e.In,
+		Out:       e.Out,
+		MAC:       e.MAC,
+		Src:       e.Src,
+		Dst:       e.Dst,
+		Proto:     e.Proto,
+		SrcPort:   e.SrcPort,
+		DstPort:   e.DstPort,
+		TTL:       e.TTL,
+		Len:       e.Len,
+		TCPFlags:  e.TCPFlags,
+		Raw:       e.Raw,
+	}
+}
+
+// WriteJSON writes entries to w as an indented JSON array.
+func WriteJSON(w io.Writer, entries []parser.LogEntry) error {
+	records := make([]record, 0, len(entries))
+	for _, e := range entries {
+		records = append(records, newRecord(e))
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(records)
+}
+
+// csvHeader is the header row written by WriteCSV.
+var csvHeader = []string{"time", "action", "proto", "src", "spt", "dst", "dpt", "iface", "len", "ttl"}
+
+// WriteCSV writes entries to w as CSV with a header row. Numeric fields that
+// were absent from the log line (e.g. ports on ICMP) are left blank rather
+// than written as 0. iface is the IN interface, or OUT for locally generated
+// packets that have none.
+func WriteCSV(w io.Writer, entries []parser.LogEntry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		iface := e.In
+		if iface == "" {
+			iface = e.Out
+		}
+		row := []string{
+			e.Timestamp.Format(time.RFC3339),
+			e.Action(),
+			e.Proto,
+			e.Src,
+			optInt(e.SrcPort),
+			e.Dst,
+			optInt(e.DstPort),
+			iface,
+			optInt(e.Len),
+			optInt(e.TTL),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// optInt formats n in decimal, or "" for 0.
+func optInt(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,71 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
+)
+
+func mustParse(t *testing.T, line string) parser.LogEntry {
+	t.Helper()
+	e, err := parser.ParseLine(line)
+	if err != nil {
+		t.Fatalf("ParseLine: %v", err)
+	}
+	return *e
+}
+
+func TestWriteJSON(t *testing.T) {
+	entries := []parser.LogEntry{
+		mustParse(t, `Jan  2 10:01:33 myhost kernel: [UFW BLOCK] IN=eth0 OUT= SRC=1.2.3.4 DST=10.0.0.1 LEN=60 TTL=50 PROTO=TCP SPT=12345 DPT=22 WINDOW=65535 RES=0x00 SYN URGP=0`),
+		mustParse(t, `Mar 15 08:30:00 fw kernel: [ACCEPT] IN=lo OUT= SRC=127.0.0.1 DST=127.0.0.1 LEN=84 TTL=64 PROTO=ICMP`),
+	}
+	var sb strings.Builder
+	if err := WriteJSON(&sb, entries); err != nil {
+		t.Fatal(err)
+	}
+	var got []map[string]any
+	if err := json.Unmarshal([]byte(sb.String()), &got); err != nil {
+		t.Fatalf("output is not valid JSON: %v\n%s", err, sb.String())
+	}
+	if len(got) != 2 {
+		t.Fatalf("got %d records, want 2", len(got))
+	}
+	if got[0]["action"] != "DROP" || got[0]["src"] != "1.2.3.4" || got[0]["dpt"] != float64(22) {
+		t.Errorf("first record: %v", got[0])
+	}
+	if _, ok := got[1]["dpt"]; ok {
+		t.Errorf("missing port should be omitted, got %v", got[1]["dpt"])
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	entries := []parser.LogEntry{
+		mustParse(t, `2026-02-22T00:00:28+00:00 myhost kernel: [UFW BLOCK] IN=eth0 OUT= SRC=1.2.3.4 DST=10.0.0.1 LEN=60 TTL=50 PROTO=TCP SPT=12345 DPT=22 WINDOW=65535 RES=0x00 SYN URGP=0`),
+		mustParse(t, `2026-02-22T00:00:29+00:00 myhost kernel: [ACCEPT] IN= OUT=eth1 SRC=10.0.0.1 DST=8.8.8.8 LEN=84 TTL=64 PROTO=ICMP`),
+	}
+	var sb strings.Builder
+	if err := WriteCSV(&sb, entries); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(sb.String())).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+	want := [][]string{
+		{"time", "action", "proto", "src", "spt", "dst", "dpt", "iface", "len", "ttl"},
+		{entries[0].Timestamp.Format("2006-01-02T15:04:05Z07:00"), "DROP", "TCP", "1.2.3.4", "12345", "10.0.0.1", "22", "eth0", "60", "50"},
+		{entries[1].Timestamp.Format("2006-01-02T15:04:05Z07:00"), "ACCEPT", "ICMP", "10.0.0.1", "", "8.8.8.8", "", "eth1", "84", "64"},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("got %d rows, want %d:\n%s", len(rows), len(want), sb.String())
+	}
+	for i := range want {
+		if strings.Join(rows[i], ",") != strings.Join(want[i], ",") {
+			t.Errorf("row %d:\n got  %q\n want %q", i, rows[i], want[i])
+		}
+	}
+}
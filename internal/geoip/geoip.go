@@ -0,0 +1,66 @@
+// Package geoip resolves IP addresses to a country and city using a local
+// MaxMind-format (.mmdb) database such as GeoLite2-City.
+package geoip
+
+import (
+	"net"
+	"sync"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// record is the subset of the GeoIP2/GeoLite2 City and Country schemas read
+// by Lookup. Country databases simply leave City empty.
+type record struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	City struct {
+		Names map[string]string `maxminddb:"names"`
+	} `maxminddb:"city"`
+}
+
+type location struct{ country, city string }
+
+// DB is an open GeoIP database with a per-IP result cache. It is safe for
+// concurrent use.
+type DB struct {
+	reader *maxminddb.Reader
+
+	mu    sync.Mutex
+	cache map[string]location
+}
+
+// Open memory-maps the database at path.
+func Open(path string) (*DB, error) {
+	r, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &DB{reader: r, cache: make(map[string]location)}, nil
+}
+
+// Close releases the database.
+func (d *DB) Close() error {
+	return d.reader.Close()
+}
+
+// Lookup returns the ISO country code and English city name for ip. Both are
+// "" when the address is not in the database (private ranges, for example)
+// or cannot be parsed. Results, including misses, are cached.
+func (d *DB) Lookup(ip string) (country, city string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if loc, ok := d.cache[ip]; ok {
+		return loc.country, loc.city
+	}
+	var loc location
+	if parsed := net.ParseIP(ip); parsed != nil {
+		var rec record
+		if err := d.reader.Lookup(parsed, &rec); err == nil {
+			loc = location{country: rec.Country.ISOCode, city: rec.City.Names["en"]}
+		}
+	}
+	d.cache[ip] = loc
+	return loc.country, loc.city
+}
@@ -0,0 +1,243 @@
+// Package keymap names the remappable actions of the TUI and their default
+// keys, as reported by Bubble Tea's tea.KeyMsg.String.
+package keymap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Action names a remappable command; it is the key used in the config file's
+// [keys] section.
+type Action string
+
+// Actions, grouped roughly by where they apply.
+const (
+	Quit         Action = "quit"
+	Help         Action = "help"
+	Theme        Action = "theme"
+	Anonymize    Action = "anonymize"
+	NextTab      Action = "next-tab"
+	Up           Action = "up"
+	Down         Action = "down"
+	PageUp       Action = "page-up"
+	PageDown     Action = "page-down"
+	Top          Action = "top"
+	Bottom       Action = "bottom"
+	Goto         Action = "goto"
+	Follow       Action = "follow"
+	Count        Action = "count"
+	ScrollLeft   Action = "scroll-left"
+	ScrollRight  Action = "scroll-right"
+	Detail       Action = "detail"
+	FilterDrop   Action = "filter-drop"
+	FilterAccept Action = "filter-accept"
+	FilterReject Action = "filter-reject"
+	CycleAction  Action = "cycle-action"
+	FilterTCP    Action = "filter-tcp"
+	FilterUDP    Action = "filter-udp"
+	FilterExtern Action = "filter-external"
+	FilterDir    Action = "filter-direction"
+	Search       Action = "search"
+	PortFilter   Action = "port-filter"
+	TimeWindow   Action = "time-window"
+	ClearFilters Action = "clear-filters"
+	SavePreset   Action = "save-preset"
+	Presets      Action = "presets"
+	BlockAdd     Action = "block-add"
+	BlockList    Action = "block-list"
+	BlockCommand Action = "block-command"
+	Ping         Action = "ping"
+	Traceroute   Action = "traceroute"
+	CopyIP       Action = "copy-ip"
+	ExportJSON   Action = "export-json"
+	ExportCSV    Action = "export-csv"
+	Sort         Action = "sort"
+	SortReverse  Action = "sort-reverse"
+	LenTTL       Action = "len-ttl"
+	SrcPort      Action = "src-port"
+	PortNumbers  Action = "port-numbers"
+	Wide         Action = "wide"
+	GroupTime    Action = "separators"
+	Collapse     Action = "collapse"
+	DimAccept    Action = "dim-accept"
+	DstDNS       Action = "dst-dns"
+	Pause        Action = "pause"
+	Legend       Action = "legend"
+)
+
+// Defaults maps every action to the keys bound to it out of the box.
+var Defaults = map[Action][]string{
+	Quit:         {"q", "ctrl+c"},
+	Help:         {"?"},
+	Theme:        {"T"},
+	Anonymize:    {"M"},
+	NextTab:      {"tab"},
+	Up:           {"up", "k"},
+	Down:         {"down", "j"},
+	PageUp:       {"pgup"},
+	PageDown:     {"pgdown"},
+	Top:          {"home", "g"},
+	Bottom:       {"end", "G"},
+	Goto:         {":"},
+	Follow:       {"f"},
+	Count:        {"#"},
+	ScrollLeft:   {"left", "h"},
+	ScrollRight:  {"right", "l"},
+	Detail:       {"enter"},
+	FilterDrop:   {"d"},
+	FilterAccept: {"a"},
+	FilterReject: {"r"},
+	CycleAction:  {"A"},
+	FilterTCP:    {"t"},
+	FilterUDP:    {"u"},
+	FilterExtern: {"e"},
+	FilterDir:    {"i"},
+	Search:       {"/"},
+	PortFilter:   {"p"},
+	TimeWindow:   {"w"},
+	ClearFilters: {"c"},
+	SavePreset:   {"N"},
+	Presets:      {"R"},
+	BlockAdd:     {"+"},
+	BlockList:    {"B"},
+	BlockCommand: {"b"},
+	Ping:         {"I"},
+	Traceroute:   {"U"},
+	CopyIP:       {"y"},
+	ExportJSON:   {"x"},
+	ExportCSV:    {"X"},
+	Sort:         {"s"},
+	SortReverse:  {"S"},
+	LenTTL:       {"L"},
+	SrcPort:      {"O"},
+	PortNumbers:  {"P"},
+	Wide:         {"W"},
+	GroupTime:    {"m"},
+	Collapse:     {"z"},
+	DimAccept:    {"H"},
+	DstDNS:       {"n"},
+	Pause:        {" "},
+	Legend:       {"C"},
+}
+
+// Known reports whether name is an action.
+func Known(name string) bool {
+	_, ok := Defaults[Action(name)]
+	return ok
+}
+
+// Reserved are keys the TUI handles itself, whatever the bindings: the tab
+// numbers, and Esc, which backs out of overlays and prompts.
+var Reserved = []string{"1", "2", "3", "4", "5", "esc"}
+
+// Keymap binds keys to actions. The zero value binds nothing; use Default or
+// New.
+type Keymap struct {
+	keys  map[Action][]string
+	byKey map[string]Action
+}
+
+// Default returns the built-in bindings.
+func Default() Keymap {
+	k, _ := New(nil) // the defaults never conflict
+	return k
+}
+
+// New returns the default bindings with every action in overrides rebound to
+// the given keys (an empty list unbinds it). Key names are those of
+// tea.KeyMsg.String, plus "space" for the space bar. It is an error for two
+// actions to end up on the same key, or for an action to claim a Reserved
+// key.
+func New(overrides map[Action][]string) (Keymap, error) {
+	k := Keymap{keys: make(map[Action][]string), byKey: make(map[string]Action)}
+	reserved := make(map[string]bool, len(Reserved))
+	for _, key := range Reserved {
+		reserved[key] = true
+	}
+	for a, keys := range Defaults {
+		if o, ok := overrides[a]; ok {
+			keys = o
+		}
+		for _, key := range keys {
+			if key == "space" {
+				key = " "
+			}
+			k.keys[a] = append(k.keys[a], key)
+		}
+	}
+	// Check in a fixed order so the same conflict is always reported.
+	actions := make([]string, 0, len(k.keys))
+	for a := range k.keys {
+		actions = append(actions, string(a))
+	}
+	sort.Strings(actions)
+	for _, a := range actions {
+		for _, key := range k.keys[Action(a)] {
+			if reserved[key] {
+				return Keymap{}, fmt.Errorf("key %q (bound to %s) is reserved", Label(key), a)
+			}
+			if prev, taken := k.byKey[key]; taken {
+				return Keymap{}, fmt.Errorf("key %q is bound to both %s and %s", Label(key), prev, a)
+			}
+			k.byKey[key] = Action(a)
+		}
+	}
+	return k, nil
+}
+
+// Lookup returns the action bound to key, or "" for none.
+func (k Keymap) Lookup(key string) Action {
+	return k.byKey[key]
+}
+
+// Keys returns the keys bound to a.
+func (k Keymap) Keys(a Action) []string {
+	return k.keys[a]
+}
+
+// Label returns a for display: the labels of its keys joined by a space,
+// e.g. "↑ k".
+func (k Keymap) Label(a Action) string {
+	labels := make([]string, len(k.keys[a]))
+	for i, key := range k.keys[a] {
+		labels[i] = Label(key)
+	}
+	return strings.Join(labels, " ")
+}
+
+// Short returns the label of a's first key, for the compact footer, or ""
+// if a is unbound.
+func (k Keymap) Short(a Action) string {
+	if keys := k.keys[a]; len(keys) > 0 {
+		return Label(keys[0])
+	}
+	return ""
+}
+
+// keyLabels are display names for keys whose tea.KeyMsg.String form is not
+// what a user would look for on the keyboard.
+var keyLabels = map[string]string{
+	" ":      "Space",
+	"up":     "↑",
+	"down":   "↓",
+	"left":   "←",
+	"right":  "→",
+	"pgup":   "PgUp",
+	"pgdown": "PgDn",
+	"home":   "Home",
+	"end":    "End",
+	"enter":  "Enter",
+	"tab":    "Tab",
+	"esc":    "Esc",
+	"ctrl+c": "Ctrl+C",
+}
+
+// Label returns key for display.
+func Label(key string) string {
+	if l, ok := keyLabels[key]; ok {
+		return l
+	}
+	return key
+}
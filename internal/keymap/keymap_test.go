@@ -0,0 +1,54 @@
+package keymap
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewOverrides(t *testing.T) {
+	k, err := New(map[Action][]string{
+		Search: {"F"},
+		Pause:  {"space", "ctrl+p"},
+		DstDNS: {}, // unbind
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]Action{"F": Search, "/": "", " ": Pause, "ctrl+p": Pause, "n": "", "d": FilterDrop} {
+		if got := k.Lookup(key); got != want {
+			t.Errorf("Lookup(%q) = %q, want %q", key, got, want)
+		}
+	}
+	if got := k.Label(Pause); got != "Space ctrl+p" {
+		t.Errorf("Label(Pause) = %q", got)
+	}
+	if got := k.Short(DstDNS); got != "" {
+		t.Errorf("Short(DstDNS) = %q for an unbound action", got)
+	}
+}
+
+func TestNewSwap(t *testing.T) {
+	k, err := New(map[Action][]string{Pause: {"x"}, ExportJSON: {"space"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if k.Lookup("x") != Pause || k.Lookup(" ") != ExportJSON {
+		t.Errorf("swap not applied: x → %q, space → %q", k.Lookup("x"), k.Lookup(" "))
+	}
+}
+
+func TestNewConflicts(t *testing.T) {
+	for _, tc := range []struct {
+		overrides map[Action][]string
+		want      string
+	}{
+		{map[Action][]string{Search: {"d"}}, `key "d" is bound to both filter-drop and search`},
+		{map[Action][]string{Quit: {"esc"}}, `key "Esc" (bound to quit) is reserved`},
+		{map[Action][]string{Pause: {"ctrl+p", "d"}}, `key "d" is bound to both filter-drop and pause`},
+	} {
+		_, err := New(tc.overrides)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("New(%v) error = %v, want %q", tc.overrides, err, tc.want)
+		}
+	}
+}
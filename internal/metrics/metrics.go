@@ -0,0 +1,79 @@
+// Package metrics exposes the running event counters in the Prometheus text
+// format for scraping by external dashboards.
+package metrics
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Metrics holds the exported collectors in a private registry, so only the
+// tool's own series are served. All methods are safe on a nil *Metrics and do
+// nothing, letting callers skip nil checks when the endpoint is disabled.
+type Metrics struct {
+	registry *prometheus.Registry
+	total    prometheus.Counter
+	byAction *prometheus.CounterVec
+	byProto  *prometheus.CounterVec
+	filtered prometheus.Gauge
+}
+
+// New returns a Metrics with all collectors registered.
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		total: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "iptables_log_tui_events_total",
+			Help: "Log entries parsed since startup.",
+		}),
+		byAction: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "iptables_log_tui_events_by_action_total",
+			Help: "Log entries parsed since startup, by action.",
+		}, []string{"action"}),
+		byProto: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "iptables_log_tui_events_by_proto_total",
+			Help: "Log entries parsed since startup, by protocol.",
+		}, []string{"proto"}),
+		filtered: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "iptables_log_tui_filtered_entries",
+			Help: "Entries currently shown in the Logs tab after filtering.",
+		}),
+	}
+	m.registry.MustRegister(m.total, m.byAction, m.byProto, m.filtered)
+	return m
+}
+
+// Observe counts one entry with the given action and protocol. It mirrors
+// the Total, ByAction and ByProto fields of ui.Stats.
+func (m *Metrics) Observe(action, proto string) {
+	if m == nil {
+		return
+	}
+	m.total.Inc()
+	m.byAction.WithLabelValues(action).Inc()
+	m.byProto.WithLabelValues(proto).Inc()
+}
+
+// SetFiltered records the current number of filtered entries.
+func (m *Metrics) SetFiltered(n int) {
+	if m == nil {
+		return
+	}
+	m.filtered.Set(float64(n))
+}
+
+// Handler returns an http.Handler serving the metrics at any path.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Serve answers /metrics on ln until it fails. It is meant to run in its own
+// goroutine; it always returns a non-nil error.
+func (m *Metrics) Serve(ln net.Listener) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m.Handler())
+	return http.Serve(ln, mux)
+}
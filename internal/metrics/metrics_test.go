@@ -0,0 +1,37 @@
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandlerExposesCounters(t *testing.T) {
+	m := New()
+	m.Observe("DROP", "TCP")
+	m.Observe("DROP", "UDP")
+	m.Observe("ACCEPT", "TCP")
+	m.SetFiltered(2)
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+
+	for _, want := range []string{
+		"iptables_log_tui_events_total 3",
+		`iptables_log_tui_events_by_action_total{action="DROP"} 2`,
+		`iptables_log_tui_events_by_proto_total{proto="TCP"} 2`,
+		"iptables_log_tui_filtered_entries 2",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("metrics output missing %q", want)
+		}
+	}
+}
+
+func TestNilMetricsIsNoop(t *testing.T) {
+	var m *Metrics
+	m.Observe("DROP", "TCP")
+	m.SetFiltered(1)
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// activitySeconds is the length of the sliding window behind the top bar's
+// events-per-second meter.
+const activitySeconds = 5
+
+// activity counts arrivals per wall-clock second over the last
+// activitySeconds seconds. Like ui.Rate it is a fixed ring, so recording and
+// reading are O(1) and it copies with the Model by value.
+type activity struct {
+	buckets [activitySeconds]int
+	head    int64 // Unix second of the newest bucket; 0 before the first event
+}
+
+// advance moves the window to now, clearing the buckets it skips.
+func (a *activity) advance(now time.Time) {
+	sec := now.Unix()
+	if sec <= a.head {
+		return
+	}
+	for i := a.head + 1; i <= sec && i-a.head <= activitySeconds; i++ {
+		a.buckets[i%activitySeconds] = 0
+	}
+	a.head = sec
+}
+
+// add records one arrival at now.
+func (a *activity) add(now time.Time) {
+	a.advance(now)
+	a.buckets[a.head%activitySeconds]++
+}
+
+// rate returns the mean events per second over the window ending at now.
+func (a activity) rate(now time.Time) float64 {
+	a.advance(now)
+	total := 0
+	for _, n := range a.buckets {
+		total += n
+	}
+	return float64(total) / activitySeconds
+}
+
+// activityTickMsg redraws the meter so it decays while no lines arrive.
+type activityTickMsg struct{}
+
+// scheduleActivityTick returns a command that wakes the model in a second,
+// or nil when a tick is already pending or the meter has fallen to zero.
+func (m *Model) scheduleActivityTick() tea.Cmd {
+	if m.activityTicking || m.activity.rate(time.Now()) == 0 {
+		return nil
+	}
+	m.activityTicking = true
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return activityTickMsg{}
+	})
+}
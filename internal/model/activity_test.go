@@ -0,0 +1,35 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestActivityRate(t *testing.T) {
+	var a activity
+	t0 := time.Unix(1_700_000_000, 0)
+	if got := a.rate(t0); got != 0 {
+		t.Errorf("empty rate = %v, want 0", got)
+	}
+	for i := range 10 {
+		a.add(t0.Add(time.Duration(i) * 100 * time.Millisecond)) // 10 in the first second
+	}
+	for range 5 {
+		a.add(t0.Add(2 * time.Second))
+	}
+	if got := a.rate(t0.Add(2 * time.Second)); got != 3 {
+		t.Errorf("rate = %v, want 15 events / 5s = 3", got)
+	}
+	// The first second leaves the window, then the second one does.
+	if got := a.rate(t0.Add(5 * time.Second)); got != 1 {
+		t.Errorf("rate after 5s = %v, want 1", got)
+	}
+	if got := a.rate(t0.Add(time.Minute)); got != 0 {
+		t.Errorf("rate after a minute = %v, want 0", got)
+	}
+	// Reading does not advance the window; a late add still clears it.
+	a.add(t0.Add(time.Minute))
+	if got := a.rate(t0.Add(time.Minute)); got != 0.2 {
+		t.Errorf("rate after a quiet minute = %v, want 0.2", got)
+	}
+}
@@ -0,0 +1,21 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
+)
+
+func TestBlockListStatusShowsCanonicalAddress(t *testing.T) {
+	m := newTestModel(Options{})
+	m.addEntry(parser.LogEntry{Proto: "TCP", Src: "::ffff:203.0.113.5"})
+
+	m.addToBlockList(m.filtered[0].Src)
+	if m.status != "Added 203.0.113.5 to block list (1)" {
+		t.Errorf("status = %q", m.status)
+	}
+	m.addToBlockList("203.0.113.5")
+	if m.status != "203.0.113.5 is already on the block list" {
+		t.Errorf("status = %q", m.status)
+	}
+}
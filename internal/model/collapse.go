@@ -0,0 +1,111 @@
+package model
+
+import (
+	"time"
+
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
+)
+
+// collapseWindow is the largest gap between two identical entries that
+// collapse mode still merges into one row. Kernel retransmits of a dropped
+// packet arrive well within it.
+const collapseWindow = 10 * time.Second
+
+// repeats reports whether e repeats row closely enough to be merged into it:
+// same source, destination, protocol, destination port and action, logged
+// at most collapseWindow after the newest entry in row.
+func repeats(row, e parser.LogEntry) bool {
+	gap := e.Timestamp.Sub(row.Timestamp)
+	return gap >= 0 && gap <= collapseWindow &&
+		e.Src == row.Src && e.Dst == row.Dst && e.Proto == row.Proto &&
+		e.DstPort == row.DstPort && e.Action() == row.Action()
+}
+
+// merge folds e into row: the result is e, carrying row's repeat count plus
+// one and the timestamp of the first entry in the run.
+func merge(row, e parser.LogEntry) parser.LogEntry {
+	e.Repeats = row.Repeats + 1
+	e.First = row.First
+	if row.Repeats == 0 {
+		e.First = row.Timestamp
+	}
+	return e
+}
+
+// collapseRuns merges each run of repeated entries into its last entry, in
+// place, and returns the shortened slice.
+func collapseRuns(entries []parser.LogEntry) []parser.LogEntry {
+	out := entries[:0]
+	for _, e := range entries {
+		if n := len(out); n > 0 && repeats(out[n-1], e) {
+			out[n-1] = merge(out[n-1], e)
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// collapseInto merges e into the row of filtered that holds the newest
+// arrival, when collapse mode is on and e repeats it. It reports whether e
+// was merged; if not the caller adds it as a row of its own.
+func (m *Model) collapseInto(e parser.LogEntry) bool {
+	if !m.collapse || !m.hasLastRow || !repeats(m.lastRow, e) {
+		return false
+	}
+	i := m.rowOf(m.lastRow)
+	if i < 0 {
+		return false
+	}
+	merged := merge(m.lastRow, e)
+	m.lastRow = merged
+	if !m.sorted() || m.sortKey == sortArrival {
+		// The row keeps its place: last, or first when newest-first.
+		m.filtered[i] = merged
+		return true
+	}
+	// Any other order may move the row, so take it out and insert it anew.
+	m.filtered = append(m.filtered[:i], m.filtered[i+1:]...)
+	if i < m.cursor {
+		m.cursor--
+	}
+	m.insertSorted(merged)
+	return true
+}
+
+// rowOf returns the index in filtered of row, which must be the newest
+// arrival: found without searching in arrival order, else by scanning.
+func (m Model) rowOf(row parser.LogEntry) int {
+	if len(m.filtered) == 0 {
+		return -1
+	}
+	switch {
+	case !m.sorted():
+		return len(m.filtered) - 1
+	case m.sortKey == sortArrival:
+		return 0
+	}
+	for i := len(m.filtered) - 1; i >= 0; i-- {
+		f := m.filtered[i]
+		if f.Raw == row.Raw && f.Timestamp.Equal(row.Timestamp) && f.Repeats == row.Repeats {
+			return i
+		}
+	}
+	return -1
+}
+
+// exportEntries returns the entries an export writes: the filtered view or,
+// with collapse on, every entry its merged rows stand for, in the same order.
+func (m Model) exportEntries() []parser.LogEntry {
+	if !m.collapse {
+		return m.filtered
+	}
+	var entries []parser.LogEntry
+	for i := 0; i < m.viewLen(); i++ {
+		if e := m.all.At(i); m.matchesFilter(e) {
+			entries = append(entries, e)
+		}
+	}
+	m.sortEntries(entries)
+	return entries
+}
@@ -0,0 +1,97 @@
+package model
+
+import (
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
+)
+
+func TestCollapseRepeats(t *testing.T) {
+	m := newTestModel(Options{})
+	t0 := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	syn := func(sec int, src string) parser.LogEntry {
+		return parser.LogEntry{
+			Timestamp: t0.Add(time.Duration(sec) * time.Second),
+			Prefix:    "DROP", Proto: "TCP", Src: src, Dst: "192.0.2.1", DstPort: 22,
+		}
+	}
+	m.addEntry(syn(0, "203.0.113.5"))
+	m.addEntry(syn(1, "203.0.113.5"))
+	m.addEntry(syn(3, "203.0.113.5"))
+	m.addEntry(syn(4, "198.51.100.9"))
+	m.addEntry(syn(30, "198.51.100.9")) // too late to merge
+
+	m = press(m, "z")
+	if len(m.filtered) != 3 {
+		t.Fatalf("collapsed to %d rows, want 3", len(m.filtered))
+	}
+	row := m.filtered[0]
+	if row.Repeats != 2 || !row.First.Equal(t0) || !row.Timestamp.Equal(t0.Add(3*time.Second)) {
+		t.Errorf("first row: Repeats=%d First=%v Timestamp=%v", row.Repeats, row.First, row.Timestamp)
+	}
+
+	// Live repeats merge into the newest row; anything else adds one.
+	m.addEntry(syn(35, "198.51.100.9"))
+	m.addEntry(syn(36, "203.0.113.5"))
+	if len(m.filtered) != 4 || m.filtered[2].Repeats != 1 || m.filtered[3].Repeats != 0 {
+		t.Errorf("after live entries: %d rows, repeats %d/%d", len(m.filtered), m.filtered[2].Repeats, m.filtered[3].Repeats)
+	}
+	if m.cursor != 3 {
+		t.Errorf("cursor = %d, want to follow the newest row (3)", m.cursor)
+	}
+
+	m = press(m, "z")
+	if len(m.filtered) != 7 {
+		t.Errorf("uncollapsed: %d rows, want all 7", len(m.filtered))
+	}
+}
+
+func TestCollapseSorted(t *testing.T) {
+	m := newTestModel(Options{})
+	m.collapse, m.sortKey = true, sortDstPort
+	t0 := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i, port := range []int{80, 22, 22, 443, 443, 443} {
+		m.addEntry(parser.LogEntry{Timestamp: t0.Add(time.Duration(i) * time.Second), Proto: "TCP", Src: "203.0.113.5", DstPort: port, Raw: string(rune('a' + i))})
+	}
+	want := []struct{ port, repeats int }{{22, 1}, {80, 0}, {443, 2}}
+	if len(m.filtered) != len(want) {
+		t.Fatalf("%d rows, want %d", len(m.filtered), len(want))
+	}
+	for i, w := range want {
+		if e := m.filtered[i]; e.DstPort != w.port || e.Repeats != w.repeats {
+			t.Errorf("row %d = port %d x%d, want port %d x%d", i, e.DstPort, e.Repeats, w.port, w.repeats)
+		}
+	}
+}
+
+func TestCollapseExportsEveryEntry(t *testing.T) {
+	m := newTestModel(Options{})
+	m.collapse, m.sortKey, m.sortDesc = true, sortArrival, true
+	t0 := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i, port := range []int{22, 22, 22, 80} {
+		m.addEntry(parser.LogEntry{Timestamp: t0.Add(time.Duration(i) * time.Second), Proto: "TCP", Src: "203.0.113.5", DstPort: port, Raw: string(rune('a' + i))})
+	}
+	if len(m.filtered) != 2 {
+		t.Fatalf("%d rows, want 2", len(m.filtered))
+	}
+
+	t.Chdir(t.TempDir())
+	var written []parser.LogEntry
+	m.exportFiltered("json", func(_ io.Writer, entries []parser.LogEntry) error {
+		written = entries
+		return nil
+	})
+	var raws []string
+	for _, e := range written {
+		raws = append(raws, e.Raw)
+	}
+	if got := strings.Join(raws, ""); got != "dcba" {
+		t.Errorf("export wrote %q, want every entry newest first (dcba)", got)
+	}
+	if !strings.HasPrefix(m.status, "Wrote 4 entries") {
+		t.Errorf("status = %q", m.status)
+	}
+}
@@ -0,0 +1,133 @@
+package model
+
+import (
+	"sort"
+
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
+	"github.com/espenotterstad/iptables-log-tui/internal/ui"
+)
+
+// connKey identifies a connection for the Connections tab.
+type connKey struct {
+	src, dst string
+	dstPort  int
+	proto    string
+}
+
+// keyOf returns the key c is counted under.
+func keyOf(c ui.Conn) connKey {
+	return connKey{c.Src, c.Dst, c.DstPort, c.Proto}
+}
+
+// connOrder caches the aggregates in tab order. It is held by pointer so
+// that sortedConns, called from View, can fill it for later frames.
+type connOrder struct {
+	conns []ui.Conn
+	stale bool
+}
+
+// countConn folds e into the per-connection aggregate. Like the stats it
+// counts every entry read, including ones later evicted from the table.
+func (m *Model) countConn(e parser.LogEntry) {
+	k := connKey{e.Src, e.Dst, e.DstPort, e.Proto}
+	c, ok := m.conns[k]
+	if !ok {
+		c = &ui.Conn{Src: e.Src, Dst: e.Dst, DstPort: e.DstPort, Proto: e.Proto}
+		m.conns[k] = c
+		if limit := m.opts.MaxEntries; limit > 0 && len(m.conns) > limit+max(1, limit/trimFraction) {
+			m.pruneConns(limit)
+		}
+	}
+	c.Count++
+	if e.Timestamp.After(c.LastSeen) {
+		c.LastSeen = e.Timestamp
+	}
+	m.connOrder.stale = true
+}
+
+// pruneConns keeps the limit connections that sort first on the tab and
+// forgets the rest, least busy and oldest, so that a port scan cannot grow
+// the map without bound. It runs once a batch past the limit, like the
+// table's trims; a forgotten connection that turns up again starts a new
+// count.
+func (m *Model) pruneConns(limit int) {
+	for _, c := range m.sortedConns()[limit:] {
+		delete(m.conns, keyOf(c))
+	}
+	m.connOrder.stale = true
+}
+
+// sortedConns returns the aggregates busiest first, most recent first among
+// equal counts. The order is sorted again only after the aggregates change;
+// callers must not modify the slice.
+func (m Model) sortedConns() []ui.Conn {
+	if !m.connOrder.stale {
+		return m.connOrder.conns
+	}
+	out := make([]ui.Conn, 0, len(m.conns))
+	for _, c := range m.conns {
+		out = append(out, *c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		if !out[i].LastSeen.Equal(out[j].LastSeen) {
+			return out[i].LastSeen.After(out[j].LastSeen)
+		}
+		return compareIP(out[i].Src, out[j].Src) < 0
+	})
+	m.connOrder.conns, m.connOrder.stale = out, false
+	return out
+}
+
+// connRow returns the row of conns holding the selected connection, or the
+// first row when none is selected or it has been forgotten.
+func (m Model) connRow(conns []ui.Conn) int {
+	if m.hasConnSel {
+		for i, c := range conns {
+			if keyOf(c) == m.connSel {
+				return i
+			}
+		}
+	}
+	return 0
+}
+
+// moveConn selects the connection delta rows from the selected one, staying
+// within the tab.
+func (m *Model) moveConn(delta int) {
+	conns := m.sortedConns()
+	if len(conns) == 0 {
+		return
+	}
+	i := min(max(m.connRow(conns)+delta, 0), len(conns)-1)
+	m.connSel, m.hasConnSel = keyOf(conns[i]), true
+}
+
+// anonymizeConns returns a copy of conns with the addresses masked, for
+// display only; showConn still needs the real ones.
+func anonymizeConns(conns []ui.Conn) []ui.Conn {
+	out := make([]ui.Conn, len(conns))
+	for i, c := range conns {
+		c.Src, c.Dst = parser.AnonymizeIP(c.Src), parser.AnonymizeIP(c.Dst)
+		out[i] = c
+	}
+	return out
+}
+
+// showConn switches to the Logs tab filtered to exactly the connection c.
+func (m *Model) showConn(c ui.Conn) {
+	m.filters = ui.Filters{
+		Proto:      []string{c.Proto},
+		Src:        c.Src,
+		Dst:        c.Dst,
+		DstPortMin: c.DstPort,
+		DstPortMax: c.DstPort,
+	}
+	m.searchInput.SetValue("")
+	m.tab = TabLogs
+	m.cursor = 0
+	m.follow = true
+	m.applyFilters()
+}
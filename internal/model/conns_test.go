@@ -0,0 +1,191 @@
+package model
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/espenotterstad/iptables-log-tui/internal/alerts"
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
+	"github.com/espenotterstad/iptables-log-tui/internal/ui"
+)
+
+func TestConnsAggregateAndDrillDown(t *testing.T) {
+	m := newTestModel(Options{})
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	ssh := parser.LogEntry{Proto: "TCP", Src: "203.0.113.5", Dst: "192.168.1.1", DstPort: 22}
+	web := parser.LogEntry{Proto: "TCP", Src: "203.0.113.5", Dst: "192.168.1.1", DstPort: 443}
+	for i := range 3 {
+		ssh.Timestamp = base.Add(time.Duration(i) * time.Second)
+		m.addEntry(ssh)
+	}
+	web.Timestamp = base
+	m.addEntry(web)
+
+	conns := m.sortedConns()
+	if len(conns) != 2 {
+		t.Fatalf("len(conns) = %d, want 2", len(conns))
+	}
+	if conns[0].DstPort != 22 || conns[0].Count != 3 || !conns[0].LastSeen.Equal(base.Add(2*time.Second)) {
+		t.Errorf("busiest conn = %+v, want port 22 x3 last seen +2s", conns[0])
+	}
+
+	m.tab = TabConns
+	m.showConn(conns[1])
+	if m.tab != TabLogs {
+		t.Errorf("tab = %d, want TabLogs", m.tab)
+	}
+	if len(m.filtered) != 1 || m.filtered[0].DstPort != 443 {
+		t.Errorf("filtered = %+v, want the single port-443 entry", m.filtered)
+	}
+}
+
+func TestConnsSelectionFollowsConnection(t *testing.T) {
+	m := newTestModel(Options{})
+	m.tab = TabConns
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	conn := func(port, sec int) parser.LogEntry {
+		return parser.LogEntry{Proto: "TCP", Src: "203.0.113.5", Dst: "192.168.1.1", DstPort: port, Timestamp: base.Add(time.Duration(sec) * time.Second)}
+	}
+	m.addEntry(conn(22, 0))
+	m.addEntry(conn(443, 1))
+	if first, again := m.sortedConns(), m.sortedConns(); &first[0] != &again[0] {
+		t.Error("order sorted again without a change")
+	}
+
+	m = press(m, "j") // onto port 22, below the more recent 443
+	if c := m.sortedConns(); c[m.connRow(c)].DstPort != 22 {
+		t.Fatalf("j selected port %d", c[m.connRow(c)].DstPort)
+	}
+	// Port 22 gets busier and moves to the top; the selection moves with it.
+	m.addEntry(conn(22, 2))
+	c := m.sortedConns()
+	if row := m.connRow(c); row != 0 || c[row].DstPort != 22 || c[row].Count != 2 {
+		t.Errorf("selection on row %d = %+v, want port 22 x2 on row 0", row, c[row])
+	}
+	m = press(m, "enter")
+	if m.filters.DstPortMin != 22 {
+		t.Errorf("Enter drilled into port %d, want 22", m.filters.DstPortMin)
+	}
+}
+
+func TestConnsBounded(t *testing.T) {
+	m := newTestModel(Options{MaxEntries: 100})
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	busy := parser.LogEntry{Proto: "TCP", Src: "198.51.100.9", Dst: "192.168.1.1", DstPort: 22, Timestamp: base}
+	m.addEntry(busy)
+	m.addEntry(busy)
+	// A port scan: one entry for each of 1000 ports.
+	for p := 1; p <= 1000; p++ {
+		m.addEntry(parser.LogEntry{Proto: "TCP", Src: "203.0.113.5", Dst: "192.168.1.1", DstPort: p,
+			Timestamp: base.Add(time.Duration(p) * time.Millisecond)})
+		if limit := 100 + 100/trimFraction; len(m.conns) > limit {
+			t.Fatalf("after port %d: %d connections, want at most %d", p, len(m.conns), limit)
+		}
+	}
+	conns := m.sortedConns()
+	if conns[0].Src != busy.Src || conns[0].Count != 2 {
+		t.Errorf("busiest = %+v, want the twice-seen connection kept", conns[0])
+	}
+	// The scan's newest ports are kept over its oldest.
+	if last := conns[1]; last.DstPort != 1000 {
+		t.Errorf("newest scan connection = %+v, want port 1000", last)
+	}
+
+	unbounded := newTestModel(Options{})
+	for p := 1; p <= 500; p++ {
+		unbounded.addEntry(parser.LogEntry{Proto: "TCP", Src: "203.0.113.5", Dst: "192.168.1.1", DstPort: p})
+	}
+	if len(unbounded.conns) != 500 {
+		t.Errorf("unbounded: %d connections, want 500", len(unbounded.conns))
+	}
+}
+
+func TestAnonymize(t *testing.T) {
+	m := newTestModel(Options{})
+	m.width, m.height = 140, 30
+	m.addEntry(parser.LogEntry{Proto: "TCP", Prefix: "DROP", Src: "198.51.100.23", Dst: "192.168.1.1", DstPort: 22,
+		Raw: "kernel: [DROP] SRC=198.51.100.23 DST=192.168.1.1 PROTO=TCP DPT=22"})
+
+	m.reject("kernel: [DROP] SRC=198.51.100.23 PROTO=", "test", errors.New("no DST= field"))
+	m.filters.Src = "198.51.100.23"
+	m.presets = []ui.Preset{{Name: "scan", Filters: ui.Filters{Dst: "198.51.100.23"}}}
+
+	m = press(m, "M")
+	if !m.anonymize || !m.tableOptions().Anonymize {
+		t.Fatal("M did not turn masking on")
+	}
+	for _, tab := range []int{TabLogs, TabStats, TabFilters, TabConns, TabRaw} {
+		m.tab = tab
+		out := m.View()
+		if strings.Contains(out, "198.51.100.23") || !strings.Contains(out, "198.51.x.x") {
+			t.Errorf("%s tab does not mask the source", tabNames[tab])
+		}
+	}
+	m.tab = TabLogs
+	m.addToBlockList("198.51.100.23")
+	if out := m.View(); strings.Contains(out, "198.51.100.23") {
+		t.Error("status line does not mask the address added to the block list")
+	}
+	m.status, m.blockListOpen = "", true
+	if out := m.View(); strings.Contains(out, "198.51.100.23") || !strings.Contains(out, "198.51.x.x") {
+		t.Error("block list does not mask its addresses")
+	}
+	m.blockListOpen = false
+	// Only the view is masked: drilling down from Connections still filters
+	// on the real addresses.
+	if c := m.sortedConns(); c[0].Src != "198.51.100.23" {
+		t.Errorf("conn Src = %s", c[0].Src)
+	}
+
+	t.Chdir(t.TempDir())
+	var written []parser.LogEntry
+	m.exportFiltered("json", func(_ io.Writer, entries []parser.LogEntry) error {
+		written = entries
+		return nil
+	})
+	if len(written) != 1 || written[0].Src != "198.51.x.x" || strings.Contains(written[0].Raw, "192.168.1.1") {
+		t.Errorf("export wrote %+v", written)
+	}
+	if m.filtered[0].Src != "198.51.100.23" {
+		t.Error("export masked the entries themselves")
+	}
+}
+
+func TestWatchlist(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "offenders.json")
+	flagged := time.Now().Add(-24 * time.Hour).UTC().Format(time.RFC3339)
+	if err := os.WriteFile(path, []byte(`{"198.51.100.7": "`+flagged+`"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	wl, err := alerts.LoadWatchlist(path, alerts.DefaultOffenderTTL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newTestModel(Options{AlertRate: 2, Watchlist: wl})
+	now := time.Now()
+	m.addEntry(parser.LogEntry{Timestamp: now, Proto: "TCP", Src: "198.51.100.7"})
+	for i := range 3 {
+		m.addEntry(parser.LogEntry{Timestamp: now.Add(time.Duration(i) * time.Second), Proto: "TCP", Src: "203.0.113.5"})
+	}
+
+	if known := m.tableOptions().KnownOffender; known == nil || !known("198.51.100.7") || known("203.0.113.5") {
+		t.Error("KnownOffender does not mark exactly the earlier session's offender")
+	}
+	if seen := wl.Seen(); len(seen) != 1 || seen[0].IP != "198.51.100.7" || seen[0].Events != 1 {
+		t.Errorf("Seen() = %+v", seen)
+	}
+
+	// This session's offender is saved for the next one.
+	if err := alerts.SaveWatchlist(wl); err != nil {
+		t.Fatal(err)
+	}
+	next, _ := alerts.LoadWatchlist(path, alerts.DefaultOffenderTTL)
+	if !next.Known("203.0.113.5") || !next.Known("198.51.100.7") {
+		t.Error("watchlist did not keep both offenders")
+	}
+}
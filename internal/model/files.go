@@ -0,0 +1,36 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// writeTimestampedFile creates a new file named "<prefix>-<timestamp>.<ext>"
+// in the working directory and fills it using write. The file is opened with
+// O_EXCL; if the name is already taken a numeric suffix is appended, so an
+// existing file is never overwritten. Returns the path written.
+func writeTimestampedFile(prefix, ext string, write func(io.Writer) error) (string, error) {
+	base := fmt.Sprintf("%s-%s", prefix, time.Now().Format("20060102-150405"))
+	for i := 0; i < 100; i++ {
+		name := base + "." + ext
+		if i > 0 {
+			name = fmt.Sprintf("%s-%d.%s", base, i, ext)
+		}
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", err
+		}
+		if err := write(f); err != nil {
+			f.Close()
+			return "", err
+		}
+		return name, f.Close()
+	}
+	return "", fmt.Errorf("could not find a free file name for %s.%s", base, ext)
+}
@@ -0,0 +1,34 @@
+package model
+
+import tea "github.com/charmbracelet/bubbletea"
+
+// newTestModel returns a Model without tailers whose classifier calls every
+// address External.
+func newTestModel(opts Options) Model {
+	return New(nil, func(string) string { return "External" }, opts)
+}
+
+// namedKeys are the keys press takes by name rather than as typed runes.
+var namedKeys = map[string]tea.KeyType{
+	"enter":  tea.KeyEnter,
+	"esc":    tea.KeyEsc,
+	"pgup":   tea.KeyPgUp,
+	"pgdown": tea.KeyPgDown,
+}
+
+// keyMsg returns the message for pressing k: a named key or typed runes.
+func keyMsg(k string) tea.KeyMsg {
+	if t, ok := namedKeys[k]; ok {
+		return tea.KeyMsg{Type: t}
+	}
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(k)}
+}
+
+// press feeds keys to m's key handler in order and returns the result.
+func press(m Model, keys ...string) Model {
+	for _, k := range keys {
+		next, _ := m.handleKey(keyMsg(k))
+		m = next.(Model)
+	}
+	return m
+}
@@ -0,0 +1,33 @@
+package model
+
+import (
+	"strings"
+
+	"github.com/espenotterstad/iptables-log-tui/internal/keymap"
+)
+
+// hint formats a footer entry such as "[+/B]block list" from the first key
+// bound to each action, or returns "" when none of them is bound.
+func (m Model) hint(desc string, actions ...keymap.Action) string {
+	var keys []string
+	for _, a := range actions {
+		if k := m.keys.Short(a); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	return "[" + strings.Join(keys, "/") + "]" + desc
+}
+
+// hints joins footer entries, skipping empty ones.
+func hints(entries ...string) string {
+	var out []string
+	for _, e := range entries {
+		if e != "" {
+			out = append(out, e)
+		}
+	}
+	return strings.Join(out, "  ")
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"sort"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
+)
+
+// mergeInterval is how long lines from several files are buffered before
+// being merged into the table in timestamp order.
+const mergeInterval = 100 * time.Millisecond
+
+// mergeTickMsg flushes the lines buffered since the last tick.
+type mergeTickMsg struct{}
+
+func mergeTick() tea.Cmd {
+	return tea.Tick(mergeInterval, func(time.Time) tea.Msg {
+		return mergeTickMsg{}
+	})
+}
+
+// queueEntry buffers e for the next merge and schedules one if needed. Only
+// used when more than one file is read; a single file is already in order.
+func (m *Model) queueEntry(e parser.LogEntry) tea.Cmd {
+	m.mergePending = append(m.mergePending, e)
+	if m.mergeTicking {
+		return nil
+	}
+	m.mergeTicking = true
+	return mergeTick()
+}
+
+// flushMerge adds the buffered entries in timestamp order. If any of them is
+// older than what the table already holds — one file lagging behind another,
+// or --history replaying files one after the other — the whole buffer is
+// re-sorted so entries from all files interleave correctly. While paused the
+// re-sort waits for resume so the frozen view is not disturbed.
+func (m *Model) flushMerge() {
+	m.mergeTicking = false
+	pending := m.mergePending
+	m.mergePending = nil
+	sort.SliceStable(pending, func(i, j int) bool {
+		return pending[i].Timestamp.Before(pending[j].Timestamp)
+	})
+	for _, e := range pending {
+		if newest, ok := m.all.Newest(); ok && e.Timestamp.Before(newest.Timestamp) {
+			m.needResort = true
+		}
+		m.addEntry(e)
+	}
+	if m.needResort && !m.paused {
+		m.resortByTime()
+	}
+}
+
+// resortByTime re-sorts all by timestamp and rebuilds the view, which stays
+// on the newest entry while following.
+func (m *Model) resortByTime() {
+	m.needResort = false
+	m.all.SortByTime()
+	m.applyFilters()
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
+)
+
+func TestFlushMergeInterleavesByTimestamp(t *testing.T) {
+	m := newTestModel(Options{})
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	at := func(sec int, src string) parser.LogEntry {
+		return parser.LogEntry{Timestamp: base.Add(time.Duration(sec) * time.Second), Source: src}
+	}
+
+	// First batch: file A's history.
+	m.queueEntry(at(1, "a"))
+	m.queueEntry(at(3, "a"))
+	m.queueEntry(at(5, "a"))
+	m.flushMerge()
+	// Second batch: file B's history arrives afterwards, out of order overall.
+	m.queueEntry(at(4, "b"))
+	m.queueEntry(at(2, "b"))
+	m.flushMerge()
+
+	var got []int
+	for _, e := range m.filtered {
+		got = append(got, int(e.Timestamp.Sub(base)/time.Second))
+	}
+	want := []int{1, 2, 3, 4, 5}
+	if len(got) != len(want) {
+		t.Fatalf("filtered = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("filtered = %v, want %v", got, want)
+		}
+	}
+	if m.cursor != len(m.filtered)-1 {
+		t.Errorf("cursor = %d, want following the newest entry", m.cursor)
+	}
+}
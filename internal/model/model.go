@@ -3,98 +3,426 @@ package model
 
 import (
 	"fmt"
+	"io"
+	"maps"
+	"os/user"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/espenotterstad/iptables-log-tui/internal/alerts"
+	"github.com/espenotterstad/iptables-log-tui/internal/blocklist"
 	"github.com/espenotterstad/iptables-log-tui/internal/classifier"
+	"github.com/espenotterstad/iptables-log-tui/internal/clipboard"
+	"github.com/espenotterstad/iptables-log-tui/internal/export"
+	"github.com/espenotterstad/iptables-log-tui/internal/geoip"
+	"github.com/espenotterstad/iptables-log-tui/internal/keymap"
+	"github.com/espenotterstad/iptables-log-tui/internal/metrics"
+	"github.com/espenotterstad/iptables-log-tui/internal/nettools"
 	"github.com/espenotterstad/iptables-log-tui/internal/parser"
 	"github.com/espenotterstad/iptables-log-tui/internal/tailer"
 	"github.com/espenotterstad/iptables-log-tui/internal/ui"
 	"github.com/espenotterstad/iptables-log-tui/internal/whois"
 )
 
+// hScrollStep is how many cells h/l scroll the log table sideways.
+const hScrollStep = 8
+
 // Tab indices.
 const (
 	TabLogs    = 0
 	TabStats   = 1
 	TabFilters = 2
+	TabConns   = 3
+	TabRaw     = 4
 )
 
-// NewLineMsg is sent by the tailer goroutine when a new raw log line arrives.
-type NewLineMsg string
+// tabNames are the --tab values, indexed by tab.
+var tabNames = []string{"logs", "stats", "filters", "conns", "raw"}
+
+// ParseTab returns the tab index for a --tab value such as "stats".
+func ParseTab(name string) (int, error) {
+	if i := slices.Index(tabNames, strings.ToLower(name)); i >= 0 {
+		return i, nil
+	}
+	return 0, fmt.Errorf("unknown tab %q (want %s)", name, strings.Join(tabNames, ", "))
+}
+
+// NewLineMsg is sent by a tailer goroutine when a new raw log line arrives.
+// Source is the file it was read from.
+type NewLineMsg struct {
+	Line   string
+	Source string
+}
 
 // TailerErrMsg is sent when the tailer encounters a fatal error.
 type TailerErrMsg struct{ Err error }
 
+// TailerWaitingMsg is sent when the log file Source does not exist yet; the
+// tailer keeps retrying and the footer says so until its first line arrives.
+type TailerWaitingMsg struct{ Source string }
+
+// TailerSkippedMsg is sent when a rotated file read with --history could not
+// be read; the tailer carries on with the next file, and the status line
+// names the one skipped.
+type TailerSkippedMsg struct{ Err error }
+
+// windowTickMsg periodically re-applies filters while a time window is active,
+// so entries age out of the view even when no new lines arrive.
+type windowTickMsg struct{}
+
+// windowTickInterval is how often the time-window filter is re-evaluated.
+const windowTickInterval = 10 * time.Second
+
+// timeWindows are the presets cycled by the time-window key, in minutes.
+var timeWindows = []int{5, 15, 60}
+
 // WhoisMsg carries the result of an async whois lookup.
 type WhoisMsg struct {
 	IP   string
 	Info whois.Result
 }
 
+// Options carries startup configuration computed by main.
+type Options struct {
+	// LogPath is the absolute, symlink-resolved path of the file being read,
+	// or a comma-separated list when several are.
+	LogPath string
+	// Elevated is true when running as root; Sudo when that came from sudo.
+	Elevated bool
+	Sudo     bool
+	// WhoisCache, when non-nil, is consulted before running whois and
+	// receives every completed lookup so results persist across sessions.
+	WhoisCache *whois.Cache
+	// RDAP makes whois lookups try RDAP first, falling back to the whois
+	// binary when it fails or returns nothing.
+	RDAP bool
+	// WhoisReferrals makes whois lookups follow a registry's referral to
+	// the server it names, which then also learns the address.
+	WhoisReferrals bool
+	// MaxEntries caps how many entries are kept for the table; the oldest
+	// are dropped beyond it. Stats keep counting regardless. 0 is unbounded.
+	MaxEntries int
+	// AlertRate is the events-per-minute threshold above which a source is
+	// flagged as noisy; 0 disables alerts.
+	AlertRate int
+	// Watchlist, when non-nil, remembers flagged sources across sessions;
+	// those flagged before are marked in the table and listed on the Stats
+	// tab when they log again.
+	Watchlist *alerts.Watchlist
+	// Blocklist, when non-nil, marks sources in its networks in the table
+	// and names the matching network on the detail page.
+	Blocklist *classifier.Blocklist
+	// GeoIP, when non-nil, adds the CC column and Country/City detail rows.
+	GeoIP *geoip.DB
+	// Theme is the palette in effect at startup (built-in or --theme); the
+	// T key toggles between it and ui.LightTheme.
+	Theme ui.Theme
+	// Metrics, when non-nil, mirrors the stats counters and the filtered
+	// count for the --metrics-addr endpoint.
+	Metrics *metrics.Metrics
+	// Keys are the key bindings; nil means keymap.Default.
+	Keys *keymap.Keymap
+	// Tab is the tab shown at startup, e.g. TabStats; see ParseTab.
+	Tab int
+	// Presets are the saved filter presets, and PresetsPath the file new
+	// ones are saved to; an empty path disables saving.
+	Presets     []ui.Preset
+	PresetsPath string
+	// Anonymize starts with addresses masked, as the anonymize key toggles.
+	Anonymize bool
+	// DropsOnly starts with the action filter set to DROP and REJECT; it is
+	// an ordinary filter, changed or cleared with the usual keys.
+	DropsOnly bool
+}
+
+// PTRMsg carries the result of an async reverse-DNS lookup.
+type PTRMsg struct {
+	IP   string
+	Name string
+}
+
+// UserMsg carries the login name of a UID, "" when it has no account.
+type UserMsg struct {
+	UID  int
+	Name string
+}
+
 // Model is the root Bubble Tea model.
 type Model struct {
-	// All parsed entries (unfiltered).
-	all []parser.LogEntry
+	// The most recent parsed entries (unfiltered), capped at
+	// opts.MaxEntries. evictedSinceTrim counts evictions not yet reflected
+	// in filtered; see trimEvicted.
+	all              entryRing
+	evictedSinceTrim int
 
 	// Filtered view of entries.
 	filtered []parser.LogEntry
 
+	// collapse merges runs of repeated entries into one row each (see
+	// collapse.go). lastRow is the row holding the newest arrival, which the
+	// next repeat is merged into; hasLastRow is false while there is none.
+	collapse   bool
+	lastRow    parser.LogEntry
+	hasLastRow bool
+
 	// Cursor position within filtered.
 	cursor int
 
+	// follow keeps the cursor on the newest entry as lines arrive (in
+	// arrival order). Moving up turns it off; moving onto the last row, End
+	// or f turns it back on. See setFollow.
+	follow bool
+
 	// Active tab.
 	tab int
 
+	// paused freezes the table: new entries are still stored in all and
+	// counted in stats, but only the first pauseLen entries of all are
+	// shown until the user resumes.
+	paused   bool
+	pauseLen int
+
 	// Active filters.
 	filters ui.Filters
 
+	// columns selects the DPT or LEN/TTL column region of the table.
+	columns ui.ColumnMode
+	// srcPort adds the SPT column to the table.
+	srcPort bool
+	// wide shows the extra columns of ui.TableOptions.Wide when the
+	// terminal is wide enough.
+	wide bool
+	// portNumbers shows ports as "22 (ssh)" instead of just the service name.
+	portNumbers bool
+	// legendOpen shows the color legend in place of the Filters tab.
+	legendOpen bool
+	// accepts is how ACCEPT rows are shown: as usual, dimmed or hidden.
+	accepts acceptMode
+
+	// hOffset is how many cells the log table is scrolled to the right.
+	hOffset int
+
+	// group is the time bucket (a minute or an hour) between whose entries
+	// the log table draws separators; 0 keeps the dense view.
+	group time.Duration
+
+	// Sort order of the filtered view; see sort.go.
+	sortKey  sortKey
+	sortDesc bool
+
 	// True while the IP search input is open.
 	searching bool
 	searchInput textinput.Model
+	searchMode  searchMode
+	searchErr   string // why the typed regex is invalid, shown in the prompt
+
+	// True while the destination-port (or range) input is open. portErr
+	// holds the validation message for the last rejected value.
+	portEntry bool
+	portInput textinput.Model
+	portErr   string
+
+	// True while the ":" go-to-row prompt is open; see nav.go.
+	gotoEntry bool
+	gotoInput textinput.Model
+	gotoErr   string
+
+	// Saved filter presets, and the prompt that names a new one or picks
+	// one to apply (presetSave tells which); see presets.go.
+	presets     []ui.Preset
+	presetEntry bool
+	presetSave  bool
+	presetInput textinput.Model
+	presetErr   string
+
+	// counting is true while a count prefix for the next motion is being
+	// typed; count holds the digits so far. See handleCountKey.
+	counting bool
+	count    int
 
 	// detailOpen is true while the detail page is visible.
 	detailOpen bool
 	// detailEntry is a plain value-copy of the entry the user selected.
-	// It is never updated after being set, so incoming log lines cannot
-	// change what is displayed on the detail page.
+	// Incoming log lines never update it, so they cannot change what is
+	// displayed on the detail page; only j/k replace it with a neighbour.
+	// While the page is open cursor keeps indexing that entry in filtered:
+	// new lines do not move it, and rebuilds of filtered (evictions
+	// included) find it again, so j/k step from the entry on the page.
 	detailEntry parser.LogEntry
+	// suggestOpen shows the block-command box over the detail page.
+	suggestOpen bool
+	// probeOpen shows the ping/traceroute box over the detail page, with
+	// probe's output once probeRunning is false; see probe.go.
+	probeOpen    bool
+	probeRunning bool
+	probe        nettools.Result
+
+	// helpOpen is true while the ? key reference covers the body;
+	// helpScroll is how many of its lines are scrolled off the top.
+	helpOpen   bool
+	helpScroll int
+
+	// windowTicking is true while a windowTickMsg is scheduled.
+	windowTicking bool
+
+	// activity feeds the events-per-second meter in the top bar;
+	// activityTicking is true while an activityTickMsg is scheduled.
+	activity        activity
+	activityTicking bool
 
 	// Running stats.
 	stats ui.Stats
 
+	// Per-connection aggregates for the Conns tab, their cached order and
+	// the selected connection, kept by key so the selection stays on it as
+	// the order changes; see conns.go.
+	conns      map[connKey]*ui.Conn
+	connOrder  *connOrder
+	connSel    connKey
+	hasConnSel bool
+
+	// Lines the parser rejected, oldest first and capped at maxRejected,
+	// and the Raw tab's selection (0 is the newest); see raw.go.
+	rejected  []ui.RawLine
+	rawCursor int
+	// parseErrors counts every rejected line, including those dropped from
+	// rejected, for the Stats tab.
+	parseErrors int
+
+	// alerts flags sources exceeding opts.AlertRate events per minute.
+	alerts *alerts.Tracker
+
 	// Terminal dimensions.
 	width, height int
 
-	// The tailer (kept for Stop on quit).
-	tail *tailer.Tailer
+	// One tailer per log file (kept for Stop on quit).
+	tails []*tailer.Tailer
+
+	// waiting lists sources whose file did not exist yet and which have not
+	// produced a line since.
+	waiting []string
+
+	// With several files, lines are buffered and merged by timestamp; see
+	// merge.go. needResort records an out-of-order entry seen while paused.
+	mergePending []parser.LogEntry
+	mergeTicking bool
+	needResort   bool
+
+	// Startup configuration, including the effective log source.
+	opts Options
+
+	// keys maps key presses to actions; see handleKey.
+	keys keymap.Keymap
 
 	// categorize maps a source IP string to "Internal", "Multicast", or "External".
 	categorize func(string) string
 
+	// whois runs lookups with bounded concurrency and per-server pacing.
+	whois *whois.Service
+
 	// Whois cache and in-flight tracker.
 	whoisCache   map[string]whois.Result
 	whoisPending map[string]bool
 
+	// Reverse-DNS cache and in-flight tracker. A cached "" means no PTR.
+	ptrCache   map[string]string
+	ptrPending map[string]bool
+
+	// Login names by UID for the detail page and in-flight lookups; a
+	// cached "" means no account.
+	userNames   map[int]string
+	userPending map[int]bool
+
+	// dstNames shows resolved names in the DST column; the visible rows'
+	// destinations are resolved in the background, see resolveVisibleDsts.
+	dstNames bool
+
+	// Block list accumulated during the session, and its review overlay.
+	blockList     *blocklist.List
+	blockListOpen bool
+	blockCursor   int
+
+	// lightTheme is true while ui.LightTheme replaces opts.Theme.
+	lightTheme bool
+
+	// anonymize masks IP addresses wherever they are shown or exported; the
+	// entries themselves keep the real ones.
+	anonymize bool
+
+	// status is a one-off message shown in the footer until the next key
+	// press or statusUntil, whichever comes first; see status.go.
+	status        string
+	statusUntil   time.Time
+	statusTicking bool
+
 	// Any fatal error to display.
 	err error
 }
 
-// New creates and returns the initial model.
-func New(t *tailer.Tailer, categorize func(string) string) Model {
+// New creates and returns the initial model. tails are the tailers feeding
+// it, one per log file; they are stopped on quit.
+func New(tails []*tailer.Tailer, categorize func(string) string, opts Options) Model {
 	ti := textinput.New()
 	ti.Placeholder = "IP substring…"
 	ti.CharLimit = 64
 	ti.Width = 30
 
+	pi := textinput.New()
+	pi.Placeholder = "22 or 1024-65535"
+	pi.CharLimit = 11
+	pi.Width = 16
+
+	gi := textinput.New()
+	gi.Placeholder = "row"
+	gi.CharLimit = 9
+	gi.Width = 10
+
+	fi := textinput.New()
+	fi.CharLimit = 32
+	fi.Width = 24
+
+	keys := keymap.Default()
+	if opts.Keys != nil {
+		keys = *opts.Keys
+	}
+
+	var filters ui.Filters
+	if opts.DropsOnly {
+		filters.Action = []string{"DROP", "REJECT"}
+	}
+
 	return Model{
+		all:          newEntryRing(opts.MaxEntries),
+		keys:         keys,
 		stats:        ui.NewStats(),
-		tail:         t,
+		conns:        make(map[connKey]*ui.Conn),
+		connOrder:    &connOrder{},
+		alerts:       alerts.NewTracker(opts.AlertRate),
+		tails:        tails,
+		opts:         opts,
 		categorize:   categorize,
 		searchInput:  ti,
+		portInput:    pi,
+		gotoInput:    gi,
+		presets:      opts.Presets,
+		presetInput:  fi,
+		whois:        whois.NewService(opts.RDAP, opts.WhoisReferrals),
 		whoisCache:   make(map[string]whois.Result),
 		whoisPending: make(map[string]bool),
+		ptrCache:     make(map[string]string),
+		ptrPending:   make(map[string]bool),
+		userNames:    make(map[int]string),
+		userPending:  make(map[int]bool),
+		blockList:    blocklist.New(),
+		follow:       true,
+		tab:          opts.Tab,
+		anonymize:    opts.Anonymize,
+		filters:      filters,
 	}
 }
 
@@ -116,30 +444,101 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.err = msg.Err
 		return m, nil
 
+	case TailerWaitingMsg:
+		m.waiting = append(m.waiting, msg.Source)
+		return m, nil
+
+	case TailerSkippedMsg:
+		m.setStatus("Skipped " + msg.Err.Error())
+		return m, m.scheduleStatusTick()
+
 	case NewLineMsg:
-		entry, err := parser.ParseLine(string(msg))
+		m.waiting = slices.DeleteFunc(m.waiting, func(s string) bool { return s == msg.Source })
+		entry, err := parser.ParseLine(msg.Line)
 		if err != nil {
-			// Skip unparseable lines silently.
+			m.reject(msg.Line, msg.Source, err)
 			return m, nil
 		}
+		entry.Source = msg.Source
+		m.activity.add(time.Now())
+		if len(m.tails) > 1 {
+			return m, tea.Batch(m.queueEntry(*entry), m.scheduleActivityTick())
+		}
 		m.addEntry(*entry)
+		return m, tea.Batch(m.resolveVisibleDsts(), m.scheduleActivityTick())
+
+	case activityTickMsg:
+		m.activityTicking = false
+		return m, m.scheduleActivityTick()
+
+	case mergeTickMsg:
+		m.flushMerge()
+		return m, m.resolveVisibleDsts()
+
+	case PTRMsg:
+		m.ptrCache[msg.IP] = msg.Name
+		delete(m.ptrPending, msg.IP)
+		return m, m.resolveVisibleDsts()
+
+	case UserMsg:
+		m.userNames[msg.UID] = msg.Name
+		delete(m.userPending, msg.UID)
 		return m, nil
 
+	case windowTickMsg:
+		if m.filters.SinceMinutes == 0 {
+			m.windowTicking = false
+			return m, nil
+		}
+		m.applyFilters()
+		return m, windowTick()
+
 	case WhoisMsg:
 		m.whoisCache[msg.IP] = msg.Info
 		delete(m.whoisPending, msg.IP)
+		if m.opts.WhoisCache != nil {
+			m.opts.WhoisCache.Put(msg.IP, msg.Info)
+		}
+		return m, nil
+
+	case ProbeMsg:
+		m.finishProbe(msg)
 		return m, nil
 
+	case tea.MouseMsg:
+		return m.handleMouse(msg)
+
+	case statusTickMsg:
+		return m, m.expireStatus()
+
 	case tea.KeyMsg:
-		return m.handleKey(msg)
+		next, cmd := m.handleKey(msg)
+		if nm, ok := next.(Model); ok {
+			return nm, tea.Batch(cmd, nm.resolveVisibleDsts(), nm.scheduleStatusTick())
+		}
+		return next, cmd
 	}
 
 	// Propagate to search input when active.
 	if m.searching {
 		var cmd tea.Cmd
 		m.searchInput, cmd = m.searchInput.Update(msg)
-		m.filters.IPSubstr = m.searchInput.Value()
-		m.applyFilters()
+		m.setSearch(m.searchInput.Value())
+		return m, cmd
+	}
+	if m.portEntry {
+		var cmd tea.Cmd
+		m.portInput, cmd = m.portInput.Update(msg)
+		return m, cmd
+	}
+	if m.gotoEntry {
+		var cmd tea.Cmd
+		m.gotoInput, cmd = m.gotoInput.Update(msg)
+		return m, cmd
+	}
+	if m.presetEntry {
+		var cmd tea.Cmd
+		m.presetInput, cmd = m.presetInput.Update(msg)
 		return m, cmd
 	}
 
@@ -148,40 +547,117 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 // handleKey dispatches keyboard events.
 func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	action := m.keys.Lookup(msg.String())
+
 	// Global: quit.
-	if msg.String() == "q" || msg.String() == "ctrl+c" {
-		if m.tail != nil {
-			m.tail.Stop()
+	if action == keymap.Quit {
+		for _, t := range m.tails {
+			t.Stop()
 		}
 		return m, tea.Quit
 	}
 
+	// Any key press dismisses the previous status message.
+	m.status = ""
+
+	// Help overlay: ? opens it from anywhere but a text prompt, where it is
+	// just a character; while open it swallows every key but ?, Esc, quit
+	// and the ones that scroll it.
+	if m.helpOpen {
+		maxScroll := ui.HelpMaxScroll(m.width, m.bodyHeight(), m.keys)
+		switch {
+		case action == keymap.Help || msg.String() == "esc":
+			m.helpOpen = false
+		case action == keymap.Up:
+			m.helpScroll--
+		case action == keymap.Down:
+			m.helpScroll++
+		case action == keymap.PageUp:
+			m.helpScroll -= m.bodyHeight() - 2
+		case action == keymap.PageDown:
+			m.helpScroll += m.bodyHeight() - 2
+		case action == keymap.Top:
+			m.helpScroll = 0
+		case action == keymap.Bottom:
+			m.helpScroll = maxScroll
+		}
+		m.helpScroll = min(max(m.helpScroll, 0), maxScroll)
+		return m, nil
+	}
+	if action == keymap.Help && !m.searching && !m.portEntry && !m.gotoEntry && !m.presetEntry {
+		m.helpOpen = true
+		m.helpScroll = 0
+		return m, nil
+	}
+
+	if m.blockListOpen {
+		return m.handleBlockListKey(msg)
+	}
+
 	// Detail overlay: close on Esc or Enter.
+	if m.detailOpen && m.suggestOpen {
+		if action == keymap.BlockCommand || msg.String() == "esc" {
+			m.suggestOpen = false
+		}
+		return m, nil
+	}
+	if m.detailOpen && m.probeOpen {
+		if msg.String() == "esc" {
+			m.probeOpen = false
+		}
+		return m, nil
+	}
 	if m.detailOpen {
+		switch action {
+		case keymap.BlockCommand:
+			m.suggestOpen = true
+		case keymap.Ping, keymap.Traceroute:
+			return m, m.startProbe(action)
+		case keymap.BlockAdd:
+			m.addToBlockList(m.detailEntry.Src)
+		case keymap.CopyIP:
+			m.copyToClipboard(m.detailEntry.Src)
+		case keymap.Up, keymap.Down:
+			next := m.cursor + 1
+			if action == keymap.Up {
+				next = m.cursor - 1
+			}
+			if next < 0 || next >= len(m.filtered) {
+				return m, nil // no wrapping at either end
+			}
+			m.cursor = next
+			m.detailEntry = m.filtered[next]
+			return m, m.enrichDetail()
+		}
 		if msg.String() == "esc" || msg.String() == "enter" {
 			m.detailOpen = false
 			// Jump cursor to the latest entry so live-tail resumes naturally.
 			if len(m.filtered) > 0 {
 				m.cursor = len(m.filtered) - 1
 			}
+			m.follow = true
 		}
 		return m, nil
 	}
 
-	// Search input open: handle Esc / Enter to close.
 	if m.searching {
-		switch msg.String() {
-		case "esc", "enter":
-			m.searching = false
-			m.searchInput.Blur()
-			m.filters.IPSubstr = m.searchInput.Value()
-			m.applyFilters()
-		}
-		var cmd tea.Cmd
-		m.searchInput, cmd = m.searchInput.Update(msg)
-		m.filters.IPSubstr = m.searchInput.Value()
-		m.applyFilters()
-		return m, cmd
+		return m.handleSearchKey(msg)
+	}
+
+	if m.portEntry {
+		return m.handlePortKey(msg)
+	}
+
+	if m.gotoEntry {
+		return m.handleGotoKey(msg)
+	}
+
+	if m.presetEntry {
+		return m.handlePresetKey(msg)
+	}
+
+	if m.counting && m.handleCountKey(msg, action) {
+		return m, nil
 	}
 
 	// Tab switching.
@@ -195,134 +671,737 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "3":
 		m.tab = TabFilters
 		return m, nil
-	case "tab":
-		m.tab = (m.tab + 1) % 3
+	case "4":
+		m.tab = TabConns
+		return m, nil
+	case "5":
+		m.tab = TabRaw
+		return m, nil
+	}
+	switch action {
+	case keymap.NextTab:
+		m.tab = (m.tab + 1) % len(tabNames)
+		return m, nil
+	case keymap.Theme:
+		m.lightTheme = !m.lightTheme
+		if m.lightTheme {
+			ui.ApplyTheme(ui.LightTheme())
+			m.setStatus("Theme: light")
+		} else {
+			ui.ApplyTheme(m.opts.Theme)
+			m.setStatus("Theme: dark")
+		}
+		return m, nil
+	case keymap.Anonymize:
+		m.anonymize = !m.anonymize
+		if m.anonymize {
+			m.setStatus("IP addresses masked")
+		} else {
+			m.setStatus("IP addresses shown")
+		}
 		return m, nil
 	}
 
 	// Logs-tab specific actions.
 	if m.tab == TabLogs {
-		switch msg.String() {
-		case "up", "k":
+		switch action {
+		case keymap.Up:
 			if m.cursor > 0 {
 				m.cursor--
 			}
-		case "down", "j":
+			m.setFollow(true)
+		case keymap.Down:
 			if m.cursor < len(m.filtered)-1 {
 				m.cursor++
 			}
-		case "pgup":
-			m.cursor -= 20
-			if m.cursor < 0 {
-				m.cursor = 0
-			}
-		case "pgdown":
-			m.cursor += 20
-			if m.cursor >= len(m.filtered) {
-				m.cursor = len(m.filtered) - 1
-			}
-		case "enter":
+			m.setFollow(false)
+		case keymap.PageUp:
+			m.moveCursor(-m.pageRows())
+		case keymap.PageDown:
+			m.moveCursor(m.pageRows())
+		case keymap.Count:
+			m.counting = true
+		case keymap.Top:
+			m.cursor = 0
+			m.setFollow(true)
+		case keymap.Bottom, keymap.Follow:
+			// The last row again follows new entries in arrival order.
+			m.cursor = max(len(m.filtered)-1, 0)
+			m.follow = true
+		case keymap.Goto:
+			m.openGoto()
+		case keymap.Detail:
 			if len(m.filtered) > 0 && m.cursor < len(m.filtered) {
 				m.detailEntry = m.filtered[m.cursor] // plain value copy
 				m.detailOpen = true
-				src := m.detailEntry.Src
-				if m.categorize(src) == classifier.CatExternal && !m.whoisPending[src] {
-					if _, cached := m.whoisCache[src]; !cached {
-						m.whoisPending[src] = true
-						ip := src
-						return m, func() tea.Msg {
-							return WhoisMsg{IP: ip, Info: whois.Lookup(ip)}
-						}
-					}
-				}
+				return m, m.enrichDetail()
+			}
+		case keymap.FilterDrop:
+			m.filters.Action = ui.Toggle(m.filters.Action, "DROP")
+			m.applyFilters()
+		case keymap.FilterAccept:
+			m.filters.Action = ui.Toggle(m.filters.Action, "ACCEPT")
+			m.applyFilters()
+		case keymap.FilterReject:
+			m.filters.Action = ui.Toggle(m.filters.Action, "REJECT")
+			m.applyFilters()
+		case keymap.CycleAction:
+			m.filters.Action = nextAction(m.filters.Action, m.stats.ByAction)
+			m.applyFilters()
+		case keymap.FilterTCP:
+			m.filters.Proto = ui.Toggle(m.filters.Proto, "TCP")
+			m.applyFilters()
+		case keymap.FilterUDP:
+			m.filters.Proto = ui.Toggle(m.filters.Proto, "UDP")
+			m.applyFilters()
+		case keymap.BlockAdd:
+			if len(m.filtered) > 0 && m.cursor < len(m.filtered) {
+				m.addToBlockList(m.filtered[m.cursor].Src)
+			}
+		case keymap.CopyIP:
+			if len(m.filtered) > 0 && m.cursor < len(m.filtered) {
+				m.copyToClipboard(m.filtered[m.cursor].Src)
 			}
-		case "d":
-			if m.filters.Action == "DROP" {
-				m.filters.Action = ""
+		case keymap.Pause:
+			if m.paused {
+				m.paused = false
+				if m.needResort {
+					m.all.SortByTime()
+				}
+				m.needResort = false
+				m.applyFilters()
+				if len(m.filtered) > 0 {
+					m.cursor = len(m.filtered) - 1
+				}
+				m.follow = true
 			} else {
-				m.filters.Action = "DROP"
+				m.paused = true
+				m.pauseLen = m.all.Len()
 			}
+		case keymap.Sort:
+			m.sortKey = m.sortKey.next()
 			m.applyFilters()
-		case "a":
-			if m.filters.Action == "ACCEPT" {
-				m.filters.Action = ""
+		case keymap.SortReverse:
+			m.sortDesc = !m.sortDesc
+			m.applyFilters()
+		case keymap.DstDNS:
+			m.dstNames = !m.dstNames
+			if m.dstNames {
+				m.setStatus("DST shows reverse-DNS names (resolving in the background)")
 			} else {
-				m.filters.Action = "ACCEPT"
+				m.setStatus("DST shows addresses")
+			}
+		case keymap.ScrollLeft, keymap.ScrollRight:
+			// The limit shrinks when wide rows scroll out, so clamp both ways.
+			limit := ui.MaxHScroll(m.filtered, m.cursor, m.width, m.bodyHeight(), m.tableOptions())
+			step := hScrollStep
+			if action == keymap.ScrollLeft {
+				step = -step
 			}
+			m.hOffset = max(min(m.hOffset, limit)+step, 0)
+			m.hOffset = min(m.hOffset, limit)
+		case keymap.Collapse:
+			m.collapse = !m.collapse
 			m.applyFilters()
-		case "t":
-			if m.filters.Proto == "TCP" {
-				m.filters.Proto = ""
+			if m.collapse {
+				m.setStatus("Repeated entries collapsed")
 			} else {
-				m.filters.Proto = "TCP"
+				m.setStatus("Every entry shown")
 			}
+		case keymap.DimAccept:
+			m.accepts = (m.accepts + 1) % (acceptsHidden + 1)
 			m.applyFilters()
-		case "u":
-			if m.filters.Proto == "UDP" {
-				m.filters.Proto = ""
+			switch m.accepts {
+			case acceptsDimmed:
+				m.setStatus("ACCEPT rows dimmed")
+			case acceptsHidden:
+				m.setStatus("ACCEPT rows hidden")
+			default:
+				m.setStatus("ACCEPT rows shown")
+			}
+		case keymap.GroupTime:
+			switch m.group {
+			case 0:
+				m.group = time.Minute
+				m.setStatus("Separators every minute")
+			case time.Minute:
+				m.group = time.Hour
+				m.setStatus("Separators every hour")
+			default:
+				m.group = 0
+				m.setStatus("Separators off")
+			}
+		case keymap.SrcPort:
+			m.srcPort = !m.srcPort
+			if m.srcPort {
+				m.setStatus("SPT column shown")
 			} else {
-				m.filters.Proto = "UDP"
+				m.setStatus("SPT column hidden")
 			}
-			m.applyFilters()
-		case "/":
+		case keymap.PortNumbers:
+			m.portNumbers = !m.portNumbers
+			if m.portNumbers {
+				m.setStatus("Ports show numbers with service names")
+			} else {
+				m.setStatus("Ports show service names")
+			}
+		case keymap.Wide:
+			m.wide = !m.wide
+			switch {
+			case !m.wide:
+				m.setStatus("Wide mode off")
+			case m.width < ui.WideMinWidth:
+				m.setStatus(fmt.Sprintf("Wide mode on; shown from %d columns (now %d)", ui.WideMinWidth, m.width))
+			default:
+				m.setStatus("Wide mode on")
+			}
+		case keymap.LenTTL:
+			if m.columns == ui.ColumnsPort {
+				m.columns = ui.ColumnsLenTTL
+			} else {
+				m.columns = ui.ColumnsPort
+			}
+		case keymap.BlockList:
+			m.blockListOpen = true
+			m.blockCursor = 0
+		case keymap.ExportJSON:
+			m.exportFiltered("json", export.WriteJSON)
+		case keymap.ExportCSV:
+			m.exportFiltered("csv", export.WriteCSV)
+		case keymap.Search:
 			m.searching = true
 			m.searchInput.Focus()
 			return m, textinput.Blink
-		case "esc":
-			m.filters = ui.Filters{}
-			m.searchInput.SetValue("")
+		case keymap.TimeWindow:
+			m.filters.SinceMinutes = nextWindow(m.filters.SinceMinutes)
 			m.applyFilters()
+			if m.filters.SinceMinutes != 0 && !m.windowTicking {
+				m.windowTicking = true
+				return m, windowTick()
+			}
+		case keymap.FilterExtern:
+			if m.filters.Category == classifier.CatExternal {
+				m.filters.Category = ""
+			} else {
+				m.filters.Category = classifier.CatExternal
+			}
+			m.applyFilters()
+		case keymap.FilterDir:
+			m.filters.Direction = nextDirection(m.filters.Direction)
+			m.applyFilters()
+		case keymap.PortFilter:
+			m.portEntry = true
+			m.portErr = ""
+			m.portInput.SetValue(ui.PortRangeLabel(m.filters.DstPortMin, m.filters.DstPortMax))
+			m.portInput.Focus()
+			return m, textinput.Blink
 		}
 	}
 
-	// Filter-tab: clear all.
-	if m.tab == TabFilters && msg.String() == "c" {
+	// Clear all filters: Esc in the Logs tab, or the clear-filters key in
+	// the Logs or Filters tab.
+	if (m.tab == TabLogs && msg.String() == "esc") ||
+		((m.tab == TabLogs || m.tab == TabFilters) && action == keymap.ClearFilters) {
 		m.filters = ui.Filters{}
 		m.searchInput.SetValue("")
 		m.applyFilters()
 	}
+	if (m.tab == TabLogs || m.tab == TabFilters) &&
+		(action == keymap.SavePreset || action == keymap.Presets) {
+		if m.openPreset(action == keymap.SavePreset) {
+			return m, textinput.Blink
+		}
+		return m, nil
+	}
+
+	if m.tab == TabFilters && action == keymap.Legend {
+		m.legendOpen = !m.legendOpen
+		return m, nil
+	}
+
+	if m.tab == TabConns {
+		switch action {
+		case keymap.Up:
+			m.moveConn(-1)
+		case keymap.Down:
+			m.moveConn(1)
+		case keymap.Detail:
+			if conns := m.sortedConns(); len(conns) > 0 {
+				m.showConn(conns[m.connRow(conns)])
+				return m, m.resolveVisibleDsts()
+			}
+		}
+	}
+
+	if m.tab == TabRaw {
+		switch action {
+		case keymap.Up:
+			if m.rawCursor > 0 {
+				m.rawCursor--
+			}
+		case keymap.Down:
+			if m.rawCursor < len(m.rejected)-1 {
+				m.rawCursor++
+			}
+		case keymap.CopyIP:
+			m.copyRaw()
+		}
+	}
+
+	return m, nil
+}
+
+// enrichDetail starts the lookups for the entry on the detail page: whois
+// for both addresses (lookupWhois skips those that are not External), PTR
+// for the source and the login name of a logged UID.
+func (m *Model) enrichDetail() tea.Cmd {
+	src, dst := m.detailEntry.Src, m.detailEntry.Dst
+	cmds := []tea.Cmd{m.lookupWhois(src), m.lookupWhois(dst), m.lookupPTR(src)}
+	if uid := m.detailEntry.UID; uid != nil {
+		cmds = append(cmds, m.lookupUser(*uid))
+	}
+	return tea.Batch(cmds...)
+}
+
+// lookupWhois returns a command that runs whois for ip, or nil when ip is not
+// External, a lookup is already in flight, or a result is already known —
+// either in memory or, failing that, fresh in the on-disk cache.
+func (m *Model) lookupWhois(ip string) tea.Cmd {
+	if m.categorize(ip) != classifier.CatExternal || m.whoisPending[ip] {
+		return nil
+	}
+	if _, cached := m.whoisCache[ip]; cached {
+		return nil
+	}
+	if m.opts.WhoisCache != nil {
+		if info, ok := m.opts.WhoisCache.Get(ip); ok {
+			m.whoisCache[ip] = info
+			return nil
+		}
+	}
+	m.whoisPending[ip] = true
+	svc := m.whois
+	return func() tea.Msg {
+		return WhoisMsg{IP: ip, Info: svc.Lookup(ip)}
+	}
+}
+
+// lookupUser returns a command resolving the login name of uid on this host,
+// or nil when it is already known or being resolved. The lookup may go to
+// NSS (LDAP, sssd), so it never runs inside Update or View.
+func (m *Model) lookupUser(uid int) tea.Cmd {
+	if _, cached := m.userNames[uid]; cached || m.userPending[uid] {
+		return nil
+	}
+	m.userPending[uid] = true
+	return func() tea.Msg {
+		var name string
+		if u, err := user.LookupId(strconv.Itoa(uid)); err == nil {
+			name = u.Username
+		}
+		return UserMsg{UID: uid, Name: name}
+	}
+}
+
+// lookupPTR returns a command resolving the PTR record for ip, or nil when
+// ip is not External or the name is already known or being resolved.
+func (m *Model) lookupPTR(ip string) tea.Cmd {
+	if m.categorize(ip) != classifier.CatExternal || m.ptrPending[ip] {
+		return nil
+	}
+	if _, cached := m.ptrCache[ip]; cached {
+		return nil
+	}
+	return m.resolvePTR(ip)
+}
+
+// resolvePTR marks ip as pending and returns the command resolving it.
+func (m *Model) resolvePTR(ip string) tea.Cmd {
+	m.ptrPending[ip] = true
+	return func() tea.Msg {
+		return PTRMsg{IP: ip, Name: whois.ReverseDNS(ip)}
+	}
+}
+
+// maxDNSInFlight caps concurrent reverse-DNS lookups so scrolling through a
+// busy table does not flood the resolver.
+const maxDNSInFlight = 4
+
+// resolveVisibleDsts starts reverse-DNS lookups for destination addresses in
+// the rows currently on screen, while DST names are enabled, keeping at most
+// maxDNSInFlight lookups running. It is called again as each lookup
+// completes, so the rest of the screen is filled in progressively.
+func (m *Model) resolveVisibleDsts() tea.Cmd {
+	if !m.dstNames || m.tab != TabLogs || m.detailOpen {
+		return nil
+	}
+	var cmds []tea.Cmd
+	for _, i := range ui.TableRows(m.filtered, m.cursor, m.bodyHeight(), m.group) {
+		if len(m.ptrPending) >= maxDNSInFlight {
+			break
+		}
+		if i < 0 {
+			continue // separator
+		}
+		ip := m.filtered[i].Dst
+		if _, cached := m.ptrCache[ip]; cached || m.ptrPending[ip] {
+			continue
+		}
+		cmds = append(cmds, m.resolvePTR(ip))
+	}
+	return tea.Batch(cmds...)
+}
+
+// tableOptions describes how the Logs tab should draw its rows.
+func (m Model) tableOptions() ui.TableOptions {
+	opts := ui.TableOptions{
+		Offset:      m.hOffset,
+		Group:       m.group,
+		SrcPort:     m.srcPort,
+		PortNumbers: m.portNumbers,
+		Wide:        m.wide,
+		Collapsed:   m.collapse,
+		DimAccept:   m.accepts == acceptsDimmed,
+		Columns:     m.columns,
+		Categorize:  m.categorize,
+		Match:       m.filters.IPSubstr + m.filters.TextSearch, // at most one is set
+		MatchRe:     m.filters.Regex,
+		Noisy:       m.alerts.IsNoisy,
+		Anonymize:   m.anonymize,
+	}
+	if m.dstNames {
+		opts.DstName = func(ip string) string { return m.ptrCache[ip] }
+	}
+	if db := m.opts.GeoIP; db != nil {
+		opts.Country = func(ip string) string {
+			country, _ := db.Lookup(ip)
+			return country
+		}
+	}
+	if bl := m.opts.Blocklist; bl != nil {
+		opts.Blocklisted = func(ip string) bool { return bl.Match(ip) != "" }
+	}
+	if wl := m.opts.Watchlist; wl != nil {
+		opts.KnownOffender = wl.Known
+	}
+	return opts
+}
+
+// nextWindow returns the time-window preset following cur (off → 5m → 15m →
+// 1h → off).
+func nextWindow(cur int) int {
+	for i, w := range timeWindows {
+		if w == cur && i+1 < len(timeWindows) {
+			return timeWindows[i+1]
+		}
+	}
+	if cur == 0 {
+		return timeWindows[0]
+	}
+	return 0
+}
+
+// acceptMode is how the table shows ACCEPT rows, cycled by the dim-accept
+// key so DROPs stand out on a permissive ruleset.
+type acceptMode int
+
+const (
+	acceptsShown  acceptMode = iota
+	acceptsDimmed            // drawn in the muted color
+	acceptsHidden            // left out of the view, like a filter
+)
+
+// directions are the values cycled by the direction-filter key.
+var directions = []string{"in", "out", "fwd"}
+
+// nextDirection returns the direction filter following cur (any → in → out
+// → fwd → any).
+func nextDirection(cur string) string {
+	for i, d := range directions {
+		if d == cur && i+1 < len(directions) {
+			return directions[i+1]
+		}
+	}
+	if cur == "" {
+		return directions[0]
+	}
+	return ""
+}
+
+// nextAction returns the action filter following cur when cycling through
+// the actions seen so far, in alphabetical order: any → the first → … → the
+// last → any. A set of several actions, or one no longer in seen, starts
+// again from the first.
+func nextAction(cur []string, seen map[string]int) []string {
+	actions := slices.Sorted(maps.Keys(seen))
+	if len(actions) == 0 {
+		return nil
+	}
+	if len(cur) == 0 {
+		return actions[:1:1]
+	}
+	i := slices.Index(actions, cur[0])
+	switch {
+	case len(cur) > 1 || i < 0:
+		return actions[:1:1]
+	case i+1 < len(actions):
+		return []string{actions[i+1]}
+	}
+	return nil
+}
+
+// windowTick schedules the next time-window re-evaluation.
+func windowTick() tea.Cmd {
+	return tea.Tick(windowTickInterval, func(time.Time) tea.Msg {
+		return windowTickMsg{}
+	})
+}
+
+// handlePortKey handles keys while the destination-port input is open.
+// Enter applies the value — a single port or an inclusive "min-max" range;
+// an empty value or 0 clears the filter, and anything else is rejected with
+// the prompt left open. Esc closes the prompt without changing the filter.
+func (m Model) handlePortKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.portEntry = false
+		m.portErr = ""
+		m.portInput.Blur()
+		return m, nil
+	case "enter":
+		lo, hi, err := ui.ParsePortRange(m.portInput.Value())
+		if err != nil {
+			m.portErr = err.Error()
+			return m, nil
+		}
+		m.portEntry = false
+		m.portErr = ""
+		m.portInput.Blur()
+		m.filters.DstPortMin, m.filters.DstPortMax = lo, hi
+		m.applyFilters()
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.portInput, cmd = m.portInput.Update(msg)
+	return m, cmd
+}
 
+// handleBlockListKey handles keys while the block list overlay is open.
+func (m Model) handleBlockListKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	ips := m.blockList.IPs()
+	action := m.keys.Lookup(msg.String())
+	switch {
+	case msg.String() == "esc" || action == keymap.BlockList:
+		m.blockListOpen = false
+	case action == keymap.Up:
+		if m.blockCursor > 0 {
+			m.blockCursor--
+		}
+	case action == keymap.Down:
+		if m.blockCursor < len(ips)-1 {
+			m.blockCursor++
+		}
+	case msg.String() == "x" || msg.String() == "delete" || msg.String() == "backspace":
+		if m.blockCursor < len(ips) {
+			m.blockList.Remove(ips[m.blockCursor])
+			if m.blockCursor >= m.blockList.Len() && m.blockCursor > 0 {
+				m.blockCursor--
+			}
+		}
+	default:
+		for _, f := range blocklist.Formats {
+			if msg.String() != f.Name[:1] {
+				continue
+			}
+			if len(ips) == 0 {
+				m.setStatus("Block list is empty — nothing to export")
+				break
+			}
+			path, err := writeTimestampedFile("blocklist", f.Ext, func(w io.Writer) error {
+				return f.Write(w, ips)
+			})
+			if err != nil {
+				m.setStatus(fmt.Sprintf("Export failed: %v", err))
+			} else {
+				m.setStatus(fmt.Sprintf("Wrote %d addresses to %s (%s)", len(ips), path, f.Name))
+			}
+		}
+	}
 	return m, nil
 }
 
+// exportFiltered writes the current filtered view to a timestamped file with
+// the given extension and reports the outcome in the status line. Collapsed
+// rows are written as the entries they stand for. While addresses are masked
+// on screen they are masked in the file too.
+func (m *Model) exportFiltered(ext string, write func(io.Writer, []parser.LogEntry) error) {
+	entries := m.exportEntries()
+	if m.anonymize {
+		masked := make([]parser.LogEntry, len(entries))
+		for i, e := range entries {
+			masked[i] = e.Anonymized()
+		}
+		entries = masked
+	}
+	path, err := writeTimestampedFile("iptables-log", ext, func(w io.Writer) error {
+		return write(w, entries)
+	})
+	if err != nil {
+		m.setStatus(fmt.Sprintf("Export failed: %v", err))
+		return
+	}
+	m.setStatus(fmt.Sprintf("Wrote %d entries to %s", len(entries), path))
+}
+
+// copyToClipboard puts ip on the system clipboard and reports the outcome in
+// the status line.
+func (m *Model) copyToClipboard(ip string) {
+	if err := clipboard.Copy(ip); err != nil {
+		m.setStatus(fmt.Sprintf("Copy failed: %v", err))
+		return
+	}
+	m.setStatus(fmt.Sprintf("Copied %s to clipboard", ip))
+}
+
+// addToBlockList adds ip to the session block list and reports the outcome
+// in the status line.
+func (m *Model) addToBlockList(ip string) {
+	switch canonical, added, err := m.blockList.Add(ip); {
+	case err != nil:
+		m.setStatus(fmt.Sprintf("Not blocking %s: %v", strconv.Quote(ip), err))
+	case added:
+		m.setStatus(fmt.Sprintf("Added %s to block list (%d)", canonical, m.blockList.Len()))
+	default:
+		m.setStatus(fmt.Sprintf("%s is already on the block list", canonical))
+	}
+}
+
 // addEntry appends a parsed entry to all, updates stats, and refreshes filtered.
 func (m *Model) addEntry(e parser.LogEntry) {
-	m.all = append(m.all, e)
+	if m.all.Push(e) {
+		// The oldest entry is gone. A frozen view loses it from the
+		// front of its prefix too.
+		if m.pauseLen > 0 {
+			m.pauseLen--
+		}
+		m.evictedSinceTrim++
+		if m.evictedSinceTrim >= max(1, m.opts.MaxEntries/trimFraction) {
+			m.trimEvicted()
+		}
+	}
 
 	// Update stats.
 	m.stats.Total++
 	m.stats.ByAction[e.Action()]++
 	m.stats.ByProto[e.Proto]++
+	m.opts.Metrics.Observe(e.Action(), e.Proto)
 	if e.In != "" {
 		m.stats.ByIface[e.In]++
 	}
 	m.stats.BySrcIP[e.Src]++
+	if subnet := ui.SourceSubnet(e.Src); subnet != "" {
+		m.stats.BySubnet[subnet]++
+	}
+	if e.Dst != "" {
+		m.stats.ByDstIP[e.Dst]++
+	}
 	if e.DstPort != 0 {
 		key := fmt.Sprintf("%d", e.DstPort)
 		m.stats.ByDstPort[key]++
 	}
+	m.stats.Rate.Add(e.Timestamp)
+	m.stats.Bytes.AddN(e.Timestamp, e.Len)
+	flagged := m.alerts.Add(e.Src, e.Timestamp)
+	if wl := m.opts.Watchlist; wl != nil {
+		if flagged {
+			wl.Flag(e.Src, e.Timestamp)
+		}
+		wl.See(e.Src)
+	}
+	m.countConn(e)
 
-	// Append to filtered if it passes the current filter.
+	// While paused the view is frozen; the entry is picked up on resume.
+	if m.paused {
+		return
+	}
+
+	// Append to filtered if it passes the current filter. A non-default
+	// sort places the entry in order instead and never follows the tail.
 	if m.matchesFilter(e) {
+		defer func() { m.opts.Metrics.SetFiltered(len(m.filtered)) }()
+		if m.collapseInto(e) {
+			return
+		}
+		m.lastRow, m.hasLastRow = e, true
+		if m.sorted() {
+			m.insertSorted(e)
+			return
+		}
 		m.filtered = append(m.filtered, e)
-		// Follow the tail only when the cursor was already at the bottom
-		// before this entry arrived (len-2 is the old last index).
-		// If the user has scrolled up, leave the cursor alone.
-		if !m.detailOpen && m.cursor == len(m.filtered)-2 {
+		// Follow the tail unless the user has scrolled up, or the detail
+		// page is indexing the current row.
+		if !m.detailOpen && m.follow {
 			m.cursor = len(m.filtered) - 1
 		}
 	}
 }
 
+// trimFraction sets how many evictions are batched before filtered is rebuilt:
+// one rebuild per 1/trimFraction of the cap keeps the cost per line constant
+// while letting filtered lag all by at most that many entries.
+const trimFraction = 100
+
+// trimEvicted rebuilds filtered so it no longer holds evicted entries.
+// applyFilters keeps the cursor on the same entry (or on the newest one when
+// following).
+func (m *Model) trimEvicted() {
+	m.evictedSinceTrim = 0
+	m.applyFilters()
+}
+
+// viewLen returns how many entries of all the view covers: all of them, or
+// while paused only those that existed when the pause began.
+func (m Model) viewLen() int {
+	if m.paused {
+		return m.pauseLen
+	}
+	return m.all.Len()
+}
+
 // applyFilters rebuilds the filtered slice from all.
 func (m *Model) applyFilters() {
+	n := m.viewLen()
+	var selected parser.LogEntry
+	hasSelected := m.cursor >= 0 && m.cursor < len(m.filtered)
+	switch {
+	case m.detailOpen:
+		selected, hasSelected = m.detailEntry, true
+	case hasSelected:
+		selected = m.filtered[m.cursor]
+	}
 	m.filtered = m.filtered[:0]
-	for _, e := range m.all {
-		if m.matchesFilter(e) {
+	for i := 0; i < n; i++ {
+		if e := m.all.At(i); m.matchesFilter(e) {
 			m.filtered = append(m.filtered, e)
 		}
 	}
-	// Clamp cursor.
+	if m.collapse {
+		m.filtered = collapseRuns(m.filtered)
+	}
+	m.hasLastRow = len(m.filtered) > 0
+	if m.hasLastRow {
+		m.lastRow = m.filtered[len(m.filtered)-1]
+	}
+	m.sortFiltered()
+	m.opts.Metrics.SetFiltered(len(m.filtered))
+	// A followed view stays on its newest entry; otherwise the cursor stays
+	// on the entry it was on, or where that entry would have been.
+	switch {
+	case m.follow && !m.sorted() && !m.detailOpen:
+		m.cursor = len(m.filtered) - 1
+	case hasSelected:
+		m.cursor = m.reselect(selected)
+	}
 	if m.cursor >= len(m.filtered) {
 		m.cursor = len(m.filtered) - 1
 	}
@@ -333,10 +1412,42 @@ func (m *Model) applyFilters() {
 
 // matchesFilter returns true if e satisfies all active filters.
 func (m Model) matchesFilter(e parser.LogEntry) bool {
-	if m.filters.Action != "" && e.Action() != m.filters.Action {
+	if len(m.filters.Action) > 0 && !slices.Contains(m.filters.Action, e.Action()) {
+		return false
+	}
+	if m.accepts == acceptsHidden && e.Action() == "ACCEPT" {
+		return false
+	}
+	if len(m.filters.Proto) > 0 && !slices.Contains(m.filters.Proto, e.Proto) {
+		return false
+	}
+	if m.filters.Category != "" && m.categorize(e.Src) != m.filters.Category {
 		return false
 	}
-	if m.filters.Proto != "" && e.Proto != m.filters.Proto {
+	if m.filters.Direction != "" && e.Direction() != m.filters.Direction {
+		return false
+	}
+	if m.filters.DstPortMax != 0 &&
+		(e.DstPort < m.filters.DstPortMin || e.DstPort > m.filters.DstPortMax) {
+		return false
+	}
+	if m.filters.SinceMinutes > 0 {
+		cutoff := time.Now().Add(-time.Duration(m.filters.SinceMinutes) * time.Minute)
+		if e.Timestamp.Before(cutoff) {
+			return false
+		}
+	}
+	if m.filters.Regex != nil && !m.filters.Regex.MatchString(e.Raw) {
+		return false
+	}
+	if m.filters.TextSearch != "" &&
+		!strings.Contains(m.searchText(e), strings.ToLower(m.filters.TextSearch)) {
+		return false
+	}
+	if m.filters.Src != "" && e.Src != m.filters.Src {
+		return false
+	}
+	if m.filters.Dst != "" && e.Dst != m.filters.Dst {
 		return false
 	}
 	if m.filters.IPSubstr != "" {
@@ -349,6 +1460,12 @@ func (m Model) matchesFilter(e parser.LogEntry) bool {
 	return true
 }
 
+// bodyHeight returns the rows available to the active tab: the terminal
+// height minus the top bar, its divider, the footer divider and the footer.
+func (m Model) bodyHeight() int {
+	return m.height - 4
+}
+
 // View renders the entire TUI.
 func (m Model) View() string {
 	if m.err != nil {
@@ -358,7 +1475,7 @@ func (m Model) View() string {
 	var sb strings.Builder
 
 	// ── Top bar ─────────────────────────────────────────────────────────────
-	tabs := []string{"1: Logs", "2: Stats", "3: Filters"}
+	tabs := []string{"1: Logs", "2: Stats", "3: Filters", "4: Conns", "5: Raw"}
 	tabBar := ""
 	for i, t := range tabs {
 		if i == m.tab {
@@ -368,8 +1485,10 @@ func (m Model) View() string {
 		}
 		tabBar += "  "
 	}
-	title := ui.StyleTitle.Render("iptables-log-tui v0.4")
-	spacer := m.width - len(tabBar) - len("iptables-log-tui v0.4") - 2
+	// Arrivals per second over the last few seconds, next to the title.
+	meter := fmt.Sprintf("%.1f ev/s  ", m.activity.rate(time.Now()))
+	title := ui.StyleStatValue.Render(meter) + ui.StyleTitle.Render("iptables-log-tui v0.4")
+	spacer := m.width - len(tabBar) - len(meter) - len("iptables-log-tui v0.4") - 2
 	if spacer < 0 {
 		spacer = 0
 	}
@@ -377,38 +1496,194 @@ func (m Model) View() string {
 	sb.WriteString(ui.StyleDivider.Render(strings.Repeat("─", m.width)) + "\n")
 
 	// ── Body ─────────────────────────────────────────────────────────────────
-	contentHeight := m.height - 4 // top bar(2) + divider(1) + help(1)
+	contentHeight := m.bodyHeight()
 
-	switch m.tab {
-	case TabLogs:
-		if m.detailOpen {
+	switch {
+	case m.helpOpen:
+		sb.WriteString(ui.RenderHelpOverlay(m.width, contentHeight, m.helpScroll, m.keys))
+	case m.tab == TabLogs:
+		if m.blockListOpen {
+			ips := m.blockList.IPs()
+			if m.anonymize {
+				for i, ip := range ips {
+					ips[i] = parser.AnonymizeIP(ip)
+				}
+			}
+			sb.WriteString(ui.RenderBlockListOverlay(ips, m.blockCursor, m.width, contentHeight))
+		} else if m.detailOpen && m.suggestOpen {
+			sb.WriteString(lipgloss.Place(m.width, contentHeight, lipgloss.Center, lipgloss.Center,
+				ui.RenderBlockSuggestion(m.detailEntry)) + "\n")
+		} else if m.detailOpen && m.probeOpen {
+			probe := m.probe
+			if m.anonymize {
+				probe.IP, probe.Output = parser.AnonymizeIP(probe.IP), parser.AnonymizeText(probe.Output)
+			}
+			sb.WriteString(lipgloss.Place(m.width, contentHeight, lipgloss.Center, lipgloss.Center,
+				ui.RenderProbe(probe, m.probeRunning, m.width, contentHeight)) + "\n")
+		} else if m.detailOpen {
 			src := m.detailEntry.Src
-			var wi *whois.Result
-			if info, ok := m.whoisCache[src]; ok {
-				wi = &info
+			info := ui.DetailInfo{
+				ShowSource:   len(m.tails) > 1,
+				WhoisLoading: m.whoisPending[src],
+				PTR:          m.ptrCache[src],
+				PTRLoading:   m.ptrPending[src],
+				Anonymize:    m.anonymize,
+			}
+			if wi, ok := m.whoisCache[src]; ok {
+				info.Whois = &wi
+			}
+			if dst := m.detailEntry.Dst; dst != src {
+				info.DstWhoisLoading = m.whoisPending[dst]
+				if wi, ok := m.whoisCache[dst]; ok {
+					info.DstWhois = &wi
+				}
 			}
-			loading := m.whoisPending[src]
-			sb.WriteString(ui.RenderDetailPage(m.detailEntry, m.width, contentHeight, wi, loading))
+			if m.opts.GeoIP != nil {
+				info.Country, info.City = m.opts.GeoIP.Lookup(src)
+			}
+			info.Blocklist = m.opts.Blocklist.Match(src)
+			if uid := m.detailEntry.UID; uid != nil {
+				info.User = m.userNames[*uid]
+			}
+			sb.WriteString(ui.RenderDetailPage(m.detailEntry, m.width, contentHeight, info))
 		} else {
-			sb.WriteString(ui.RenderLogsTab(m.filtered, m.cursor, m.width, contentHeight, m.categorize))
+			sb.WriteString(ui.RenderLogsTab(m.filtered, m.cursor, m.width, contentHeight, m.tableOptions()))
+		}
+	case m.tab == TabStats:
+		stats := m.stats
+		stats.Noisy, stats.AlertRate = m.alerts.Noisy(), m.alerts.Threshold()
+		stats.Unparsed = m.parseErrors
+		stats.Anonymize = m.anonymize
+		if wl := m.opts.Watchlist; wl != nil {
+			stats.Offenders = wl.Seen()
+		}
+		sb.WriteString(ui.RenderStatsTab(stats, m.width))
+	case m.tab == TabFilters && m.legendOpen:
+		sb.WriteString(ui.RenderLegend(m.keys, m.width, contentHeight))
+	case m.tab == TabFilters:
+		src := ui.SourceInfo{Path: m.opts.LogPath, Elevated: m.opts.Elevated, Sudo: m.opts.Sudo}
+		for _, t := range m.tails {
+			src.Reopens += t.Reopens()
+		}
+		filters, presets := m.filters, m.presets
+		if m.anonymize {
+			filters = filters.Anonymized()
+			presets = make([]ui.Preset, len(m.presets))
+			for i, p := range m.presets {
+				presets[i] = ui.Preset{Name: p.Name, Filters: p.Filters.Anonymized()}
+			}
 		}
-	case TabStats:
-		sb.WriteString(ui.RenderStatsTab(m.stats, m.width))
-	case TabFilters:
-		sb.WriteString(ui.RenderFilterTab(m.filters))
+		sb.WriteString(ui.RenderFilterTab(filters, presets, src, m.keys, m.accepts == acceptsHidden, m.width, contentHeight))
+	case m.tab == TabConns:
+		conns := m.sortedConns()
+		row := m.connRow(conns)
+		if m.anonymize {
+			conns = anonymizeConns(conns)
+		}
+		sb.WriteString(ui.RenderConnsTab(conns, row, m.width, contentHeight))
+	case m.tab == TabRaw:
+		rejected := m.rejected
+		if m.anonymize {
+			rejected = anonymizeRaw(rejected)
+		}
+		sb.WriteString(ui.RenderRawTab(rejected, m.rawCursor, m.width, contentHeight, maxRejected))
 	}
 
 	// ── Help footer ──────────────────────────────────────────────────────────
 	sb.WriteString(ui.StyleDivider.Render(strings.Repeat("─", m.width)) + "\n")
 	switch {
+	case m.status != "" && m.anonymize:
+		sb.WriteString(ui.StyleFilter.Render(parser.AnonymizeText(m.status)))
+	case m.status != "":
+		sb.WriteString(ui.StyleFilter.Render(m.status))
+	case m.helpOpen:
+		sb.WriteString(ui.StyleHelp.Render(hints(m.hint("scroll", keymap.Up, keymap.Down), "["+m.keys.Short(keymap.Help)+"/Esc] close help", m.hint("quit", keymap.Quit))))
+	case m.blockListOpen:
+		sb.WriteString(ui.StyleHelp.Render(hints(m.hint("select", keymap.Up, keymap.Down), "[x]remove  [p/i/n/c]export  [Esc]back")))
 	case m.detailOpen:
-		sb.WriteString(ui.StyleHelp.Render("[Esc] or [Enter] — back to log list"))
+		sb.WriteString(ui.StyleHelp.Render(hints(
+			m.hint("next/prev", keymap.Down, keymap.Up), m.hint("block list", keymap.BlockAdd),
+			m.hint("copy IP", keymap.CopyIP), m.hint("block command", keymap.BlockCommand),
+			m.hint("ping", keymap.Ping), m.hint("traceroute", keymap.Traceroute),
+			"[Esc] or [Enter] — back to log list")))
 	case m.searching:
-		sb.WriteString("  IP filter: " + m.searchInput.View() + "  " + ui.StyleHelp.Render("[Esc/Enter] done"))
+		sb.WriteString("  " + searchPrompts[m.searchMode] + m.searchInput.View() + "  ")
+		if m.searchErr != "" {
+			sb.WriteString(ui.StyleDrop.Render("invalid regex: " + m.searchErr))
+		} else {
+			sb.WriteString(ui.StyleHelp.Render("[Tab] mode  [Esc/Enter] done"))
+		}
+	case m.portEntry:
+		sb.WriteString("  Dst port(s): " + m.portInput.View() + "  ")
+		if m.portErr != "" {
+			sb.WriteString(ui.StyleDrop.Render(m.portErr))
+		} else {
+			sb.WriteString(ui.StyleHelp.Render("[Enter] apply  [Esc] cancel"))
+		}
+	case m.gotoEntry:
+		sb.WriteString("  Go to row: " + m.gotoInput.View() + "  ")
+		if m.gotoErr != "" {
+			sb.WriteString(ui.StyleDrop.Render(m.gotoErr))
+		} else {
+			sb.WriteString(ui.StyleHelp.Render(fmt.Sprintf("1-%d  [Enter] jump  [Esc] cancel", len(m.filtered))))
+		}
+	case m.presetEntry:
+		sb.WriteString(m.presetPrompt())
+	case m.tab == TabRaw:
+		sb.WriteString(ui.StyleHelp.Render(hints(
+			m.hint("select", keymap.Up, keymap.Down), m.hint("copy line", keymap.CopyIP),
+			m.hint("theme", keymap.Theme), m.hint("anonymize", keymap.Anonymize), m.hint("switch", keymap.NextTab),
+			m.hint("help", keymap.Help), m.hint("quit", keymap.Quit))))
+	case m.tab == TabConns:
+		sb.WriteString(ui.StyleHelp.Render(hints(
+			m.hint("select", keymap.Up, keymap.Down), m.hint("show in Logs", keymap.Detail),
+			m.hint("theme", keymap.Theme), m.hint("anonymize", keymap.Anonymize), m.hint("switch", keymap.NextTab),
+			m.hint("help", keymap.Help), m.hint("quit", keymap.Quit))))
 	default:
-		sb.WriteString(ui.StyleHelp.Render(
-			"[d]DROP  [a]ACCEPT  [t]TCP  [u]UDP  [/]IP search  [Enter]detail  [Tab]switch  [q]quit",
-		))
+		switch {
+		case m.paused:
+			sb.WriteString(ui.StyleDrop.Bold(true).Render(
+				fmt.Sprintf("PAUSED (+%d)", m.all.Len()-m.pauseLen)) + "  ")
+		case m.follow && !m.sorted():
+			sb.WriteString(ui.StyleAccept.Bold(true).Render("LIVE") + "  ")
+		default:
+			sb.WriteString(ui.StyleMuted.Render("SCROLL") + "  ")
+		}
+		for _, src := range m.waiting {
+			sb.WriteString(ui.StyleFilter.Render("waiting for "+src+"…") + "  ")
+		}
+		if m.counting {
+			sb.WriteString(ui.StyleFilter.Render(fmt.Sprintf("count: %d", m.count)) + "  ")
+		}
+		if len(m.filters.Action) > 0 {
+			sb.WriteString(ui.StyleFilter.Render("action: "+strings.Join(m.filters.Action, " or ")) + "  ")
+		}
+		if m.accepts == acceptsHidden {
+			sb.WriteString(ui.StyleFilter.Render("ACCEPT hidden") + "  ")
+		}
+		if m.sorted() {
+			dir := "↑"
+			if m.sortDesc {
+				dir = "↓"
+			}
+			sb.WriteString(ui.StyleFilter.Render("sort: "+sortLabels[m.sortKey]+" "+dir) + "  ")
+		}
+		sb.WriteString(ui.StyleHelp.Render(hints(
+			m.hint("DROP", keymap.FilterDrop), m.hint("ACCEPT", keymap.FilterAccept),
+			m.hint("REJECT", keymap.FilterReject), m.hint("cycle action", keymap.CycleAction),
+			m.hint("TCP", keymap.FilterTCP), m.hint("UDP", keymap.FilterUDP),
+			m.hint("external", keymap.FilterExtern), m.hint("direction", keymap.FilterDir),
+			m.hint("IP search", keymap.Search), m.hint("port", keymap.PortFilter),
+			m.hint("window", keymap.TimeWindow), m.hint("presets", keymap.SavePreset, keymap.Presets),
+			m.hint("detail", keymap.Detail),
+			m.hint("block list", keymap.BlockAdd, keymap.BlockList), m.hint("copy", keymap.CopyIP),
+			m.hint("export", keymap.ExportJSON, keymap.ExportCSV), m.hint("top/end", keymap.Top, keymap.Bottom),
+			m.hint("go to", keymap.Goto), m.hint("follow", keymap.Follow), m.hint("count", keymap.Count), m.hint("sort", keymap.Sort, keymap.SortReverse),
+			m.hint("len/ttl", keymap.LenTTL), m.hint("src port", keymap.SrcPort), m.hint("port no.", keymap.PortNumbers), m.hint("wide", keymap.Wide), m.hint("separators", keymap.GroupTime), m.hint("collapse", keymap.Collapse), m.hint("dim ACCEPT", keymap.DimAccept), m.hint("scroll", keymap.ScrollLeft, keymap.ScrollRight),
+			m.hint("dst DNS", keymap.DstDNS), m.hint("pause", keymap.Pause),
+			m.hint("theme", keymap.Theme), m.hint("anonymize", keymap.Anonymize), m.hint("switch", keymap.NextTab),
+			m.hint("help", keymap.Help), m.hint("quit", keymap.Quit),
+		)))
 	}
 
 	return sb.String()
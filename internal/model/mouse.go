@@ -0,0 +1,38 @@
+package model
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/espenotterstad/iptables-log-tui/internal/ui"
+)
+
+// tableTop is the screen row of the first data row in the Logs tab, below
+// the top bar, its divider, the column header and the header divider.
+const tableTop = 4
+
+// handleMouse moves the Logs-tab cursor: the wheel steps it like ↑/↓ and a
+// left click selects the row under the pointer, so Enter then opens it.
+// Events anywhere else, or while an overlay or prompt is open, are ignored.
+func (m Model) handleMouse(msg tea.MouseMsg) (tea.Model, tea.Cmd) {
+	if m.tab != TabLogs || m.helpOpen || m.detailOpen || m.blockListOpen || m.searching || m.portEntry || m.gotoEntry || m.presetEntry {
+		return m, nil
+	}
+	switch msg.Button {
+	case tea.MouseButtonWheelUp:
+		m.moveCursor(-1)
+	case tea.MouseButtonWheelDown:
+		m.moveCursor(1)
+	case tea.MouseButtonLeft:
+		if msg.Action != tea.MouseActionPress || msg.Y < tableTop {
+			return m, nil
+		}
+		// Separator rows (-1) are not selectable.
+		rows := ui.TableRows(m.filtered, m.cursor, m.bodyHeight(), m.group)
+		if r := msg.Y - tableTop; r < len(rows) && rows[r] >= 0 {
+			m.cursor = rows[r]
+			m.setFollow(false)
+		}
+	default:
+		return m, nil
+	}
+	return m, m.resolveVisibleDsts()
+}
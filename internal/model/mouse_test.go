@@ -0,0 +1,62 @@
+package model
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
+)
+
+func TestMouseClickAndWheel(t *testing.T) {
+	m := newTestModel(Options{})
+	m.width, m.height = 80, 24
+	for i := range 5 {
+		m.addEntry(parser.LogEntry{Proto: "TCP", Src: "203.0.113.5", DstPort: 1000 + i})
+	}
+
+	click := func(y int) {
+		next, _ := m.handleMouse(tea.MouseMsg{X: 10, Y: y, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+		m = next.(Model)
+	}
+	click(tableTop + 1)
+	if m.cursor != 1 {
+		t.Errorf("click on second row: cursor = %d, want 1", m.cursor)
+	}
+	click(tableTop - 1)  // column header
+	click(tableTop + 10) // blank space below the rows
+	if m.cursor != 1 {
+		t.Errorf("clicks outside the rows moved cursor to %d", m.cursor)
+	}
+
+	next, _ := m.handleMouse(tea.MouseMsg{Button: tea.MouseButtonWheelDown, Action: tea.MouseActionPress})
+	m = next.(Model)
+	if m.cursor != 2 {
+		t.Errorf("wheel down: cursor = %d, want 2", m.cursor)
+	}
+}
+
+func TestMouseClickSkipsSeparators(t *testing.T) {
+	m := newTestModel(Options{})
+	m.width, m.height = 80, 24
+	m.group = time.Minute
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	for i := range 4 {
+		// Two entries in each of two minutes: rows are e0 e1 ── e2 e3.
+		m.addEntry(parser.LogEntry{Timestamp: base.Add(time.Duration(i/2) * time.Minute), Proto: "TCP", Src: "203.0.113.5", DstPort: 1000 + i})
+	}
+	m.cursor = 0
+
+	click := func(y int) {
+		next, _ := m.handleMouse(tea.MouseMsg{X: 10, Y: y, Button: tea.MouseButtonLeft, Action: tea.MouseActionPress})
+		m = next.(Model)
+	}
+	click(tableTop + 2) // the separator
+	if m.cursor != 0 {
+		t.Errorf("click on separator moved cursor to %d", m.cursor)
+	}
+	click(tableTop + 3)
+	if m.cursor != 2 {
+		t.Errorf("click below separator: cursor = %d, want 2", m.cursor)
+	}
+}
@@ -0,0 +1,109 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/espenotterstad/iptables-log-tui/internal/keymap"
+)
+
+// pageRows is how far PgUp and PgDn move the cursor in the Logs tab: the
+// table's visible rows less one, so the last row of one page is the first of
+// the next.
+func (m Model) pageRows() int {
+	return max(m.bodyHeight()-4-1, 1)
+}
+
+// openGoto opens the ":" prompt for jumping to a row of the Logs tab.
+func (m *Model) openGoto() {
+	m.gotoEntry = true
+	m.gotoErr = ""
+	m.gotoInput.SetValue("")
+	m.gotoInput.Focus()
+}
+
+// handleGotoKey handles a key while the ":" prompt is open. Enter jumps to
+// the typed 1-based row of the filtered view; rows past the end go to the
+// last one, as in less.
+func (m Model) handleGotoKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.gotoEntry = false
+		m.gotoErr = ""
+		m.gotoInput.Blur()
+		return m, nil
+	case "enter":
+		n, err := strconv.Atoi(strings.TrimSpace(m.gotoInput.Value()))
+		if err != nil || n < 1 {
+			m.gotoErr = "enter a row number from 1"
+			return m, nil
+		}
+		m.gotoEntry = false
+		m.gotoErr = ""
+		m.gotoInput.Blur()
+		m.jumpTo(n - 1)
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.gotoInput, cmd = m.gotoInput.Update(msg)
+	return m, cmd
+}
+
+// jumpTo moves the cursor to filtered row i, clamped to the view, and
+// reports the new position. The table scrolls to it on the next render.
+func (m *Model) jumpTo(i int) {
+	if len(m.filtered) == 0 {
+		return
+	}
+	m.cursor = min(max(i, 0), len(m.filtered)-1)
+	m.setFollow(false)
+	m.setStatus(fmt.Sprintf("Row %d of %d", m.cursor+1, len(m.filtered)))
+}
+
+// maxCount caps a count prefix so a held digit cannot overflow it.
+const maxCount = 99999
+
+// handleCountKey handles a key while a count prefix is being typed, after
+// the count key (# by default). Digits, including the tab numbers, extend
+// the count; a cursor motion then moves that many rows (pages for PgUp/PgDn)
+// and ends it. Esc drops the count, and any other key drops it and is then
+// handled as usual, which handled reports as false.
+func (m *Model) handleCountKey(msg tea.KeyMsg, action keymap.Action) (handled bool) {
+	if k := msg.String(); len(k) == 1 && k[0] >= '0' && k[0] <= '9' {
+		m.count = min(m.count*10+int(k[0]-'0'), maxCount)
+		return true
+	}
+	n := max(m.count, 1)
+	m.counting, m.count = false, 0
+	switch {
+	case msg.String() == "esc":
+	case action == keymap.Up:
+		m.moveCursor(-n)
+	case action == keymap.Down:
+		m.moveCursor(n)
+	case action == keymap.PageUp:
+		m.moveCursor(-n * m.pageRows())
+	case action == keymap.PageDown:
+		m.moveCursor(n * m.pageRows())
+	default:
+		return false
+	}
+	return true
+}
+
+// setFollow updates follow after the user moves the cursor: a move up always
+// stops following, any other move follows again if it lands on the last row.
+func (m *Model) setFollow(up bool) {
+	m.follow = !up && m.cursor >= len(m.filtered)-1
+}
+
+// moveCursor moves the cursor by delta rows, stopping at either end.
+func (m *Model) moveCursor(delta int) {
+	if len(m.filtered) == 0 {
+		return
+	}
+	m.cursor = min(max(m.cursor+delta, 0), len(m.filtered)-1)
+	m.setFollow(delta < 0)
+}
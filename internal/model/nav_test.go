@@ -0,0 +1,307 @@
+package model
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/espenotterstad/iptables-log-tui/internal/classifier"
+	"github.com/espenotterstad/iptables-log-tui/internal/keymap"
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
+	"github.com/espenotterstad/iptables-log-tui/internal/ui"
+	"github.com/espenotterstad/iptables-log-tui/internal/whois"
+)
+
+func TestGotoRowAndEnds(t *testing.T) {
+	m := newTestModel(Options{})
+	m.width, m.height = 80, 24
+	for i := range 100 {
+		m.addEntry(parser.LogEntry{Proto: "TCP", Src: "203.0.113.5", DstPort: 1000 + i})
+	}
+
+	m = press(m, "g")
+	if m.cursor != 0 {
+		t.Errorf("g: cursor = %d, want 0", m.cursor)
+	}
+	m = press(m, ":", "4", "2", "enter")
+	if m.gotoEntry || m.cursor != 41 {
+		t.Errorf(":42: cursor = %d (prompt open %v), want 41", m.cursor, m.gotoEntry)
+	}
+	if start, end := ui.VisibleRows(len(m.filtered), m.cursor, m.bodyHeight()); m.cursor < start || m.cursor >= end {
+		t.Errorf("row 42 not visible: rows %d-%d", start, end)
+	}
+	m = press(m, ":", "0", "enter")
+	if !m.gotoEntry || m.gotoErr == "" {
+		t.Error(":0 was accepted")
+	}
+	m = press(m, "9", "9", "9", "enter") // prompt now holds "0999"
+	if m.cursor != 99 {
+		t.Errorf("past the end: cursor = %d, want 99", m.cursor)
+	}
+	m = press(m, "g", "G")
+	if m.cursor != 99 {
+		t.Errorf("G: cursor = %d, want 99", m.cursor)
+	}
+}
+
+func TestDetailStepsThroughFiltered(t *testing.T) {
+	m := New(nil, func(string) string { return "Internal" }, Options{})
+	for i := range 3 {
+		m.addEntry(parser.LogEntry{Proto: "TCP", Src: "10.0.0.1", DstPort: 1000 + i})
+	}
+	m.cursor = 1
+	m.detailOpen, m.detailEntry = true, m.filtered[1]
+
+	m = press(m, "j")
+	if m.detailEntry.DstPort != 1002 {
+		t.Errorf("j: detail shows port %d, want 1002", m.detailEntry.DstPort)
+	}
+	m = press(m, "j") // already last: no wrap
+	if m.detailEntry.DstPort != 1002 || m.cursor != 2 {
+		t.Errorf("j at the end moved to port %d (cursor %d)", m.detailEntry.DstPort, m.cursor)
+	}
+	m = press(m, "k", "k", "k")
+	if m.detailEntry.DstPort != 1000 || !m.detailOpen {
+		t.Errorf("k: detail shows port %d (open %v), want 1000", m.detailEntry.DstPort, m.detailOpen)
+	}
+}
+
+func TestDetailWhoisForDestination(t *testing.T) {
+	m := New(nil, classifier.New().Categorize, Options{})
+	m.width, m.height = 100, 50
+	m.addEntry(parser.LogEntry{Proto: "UDP", Src: "192.168.1.10", Dst: "224.0.0.251", DstPort: 5353})
+	m.addEntry(parser.LogEntry{Proto: "TCP", Src: "192.168.1.10", Dst: "198.51.100.7", DstPort: 443})
+	m.follow = false
+	m.cursor = 0
+
+	m = press(m, "enter")
+	if len(m.whoisPending) != 0 {
+		t.Errorf("multicast destination looked up: pending %v", m.whoisPending)
+	}
+	m = press(m, "j")
+	if !m.whoisPending["198.51.100.7"] || m.whoisPending["192.168.1.10"] {
+		t.Errorf("external destination: pending %v, want 198.51.100.7 only", m.whoisPending)
+	}
+	if out := ansi.Strip(m.View()); !strings.Contains(out, "WHOIS (dst)") || strings.Contains(out, "WHOIS (src)") {
+		t.Errorf("detail page while looking up:\n%s", out)
+	}
+
+	next, _ := m.Update(WhoisMsg{IP: "198.51.100.7", Info: whois.Result{Org: "Example CDN"}})
+	m = next.(Model)
+	if out := ansi.Strip(m.View()); !strings.Contains(out, "Example CDN") {
+		t.Errorf("destination whois not shown:\n%s", out)
+	}
+}
+
+func TestDetailPageResize(t *testing.T) {
+	m := New(nil, func(string) string { return "Internal" }, Options{})
+	m.addEntry(parser.LogEntry{Proto: "TCP", Src: "10.0.0.1", Dst: "10.0.0.2", DstPort: 22, Raw: strings.Repeat("SRC=10.0.0.1 ", 40)})
+	m.detailOpen, m.detailEntry = true, m.filtered[0]
+
+	for _, size := range []tea.WindowSizeMsg{{Width: 120, Height: 50}, {Width: 60, Height: 14}} {
+		next, _ := m.Update(size)
+		m = next.(Model)
+		if rows := strings.Count(m.View(), "\n") + 1; rows != size.Height {
+			t.Errorf("%dx%d: frame is %d rows", size.Width, size.Height, rows)
+		}
+	}
+}
+
+func TestRemappedKeys(t *testing.T) {
+	keys, err := keymap.New(map[keymap.Action][]string{keymap.FilterDrop: {"D"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := newTestModel(Options{Keys: &keys})
+	m.addEntry(parser.LogEntry{Proto: "TCP", Prefix: "DROP", Src: "203.0.113.5"})
+	m.addEntry(parser.LogEntry{Proto: "TCP", Prefix: "ACCEPT", Src: "203.0.113.6"})
+
+	m = press(m, "d")
+	if len(m.filters.Action) != 0 {
+		t.Errorf("old key d still filters: Action = %q", m.filters.Action)
+	}
+	m = press(m, "D")
+	if !slices.Equal(m.filters.Action, []string{"DROP"}) || len(m.filtered) != 1 {
+		t.Errorf("D: Action = %q, %d rows", m.filters.Action, len(m.filtered))
+	}
+	m = press(m, "c") // clear-filters now works in the Logs tab too
+	if len(m.filters.Action) != 0 || len(m.filtered) != 2 {
+		t.Errorf("c: Action = %q, %d rows", m.filters.Action, len(m.filtered))
+	}
+}
+
+func TestCountPrefix(t *testing.T) {
+	m := newTestModel(Options{})
+	for i := range 100 {
+		m.addEntry(parser.LogEntry{Proto: "TCP", Src: "203.0.113.5", DstPort: 1000 + i})
+	}
+	m.cursor = 0
+
+	m = press(m, "#", "1", "0", "j")
+	if m.cursor != 10 || m.tab != TabLogs || m.counting {
+		t.Errorf("#10j: cursor = %d, tab %d, counting %v", m.cursor, m.tab, m.counting)
+	}
+	m = press(m, "#", "3", "k")
+	if m.cursor != 7 {
+		t.Errorf("#3k: cursor = %d, want 7", m.cursor)
+	}
+	m = press(m, "#", "5", "0", "0", "j")
+	if m.cursor != 99 {
+		t.Errorf("#500j: cursor = %d, want 99 (clamped)", m.cursor)
+	}
+	m = press(m, "#", "2", "d", "j") // d drops the count and still filters
+	if !slices.Equal(m.filters.Action, []string{"DROP"}) || m.counting {
+		t.Errorf("#2d: Action = %q, counting %v", m.filters.Action, m.counting)
+	}
+	m = press(m, "2")
+	if m.tab != TabStats {
+		t.Errorf("digit after a dropped count: tab = %d, want Stats", m.tab)
+	}
+}
+
+func TestPageByScreen(t *testing.T) {
+	m := newTestModel(Options{})
+	m.width, m.height = 80, 24 // a 16-row table
+	for i := range 100 {
+		m.addEntry(parser.LogEntry{Proto: "TCP", Src: "203.0.113.5", DstPort: 1000 + i})
+	}
+	m.cursor = 0
+
+	m = press(m, "pgdown")
+	if m.cursor != 15 {
+		t.Errorf("PgDn on a 16-row table: cursor = %d, want 15", m.cursor)
+	}
+	m = press(m, "pgup")
+	if m.cursor != 0 || m.follow {
+		t.Errorf("PgUp: cursor = %d, follow %v", m.cursor, m.follow)
+	}
+
+	m.height = 60 // the terminal grew to a 52-row table
+	m = press(m, "#", "1", "pgdown")
+	if m.cursor != 51 {
+		t.Errorf("#1 PgDn on a 52-row table: cursor = %d, want 51", m.cursor)
+	}
+	m = press(m, "pgdown")
+	if m.cursor != 99 || !m.follow {
+		t.Errorf("PgDn past the end: cursor = %d, follow %v; want the last row, following", m.cursor, m.follow)
+	}
+
+	m.height = 3 // too short for any rows still moves by one
+	m = press(m, "pgup")
+	if m.cursor != 98 {
+		t.Errorf("PgUp on a tiny terminal: cursor = %d, want 98", m.cursor)
+	}
+}
+
+func TestFollowLock(t *testing.T) {
+	m := newTestModel(Options{})
+	m.width, m.height = 80, 24
+	add := func(n int) {
+		for range n {
+			m.addEntry(parser.LogEntry{Proto: "TCP", Src: "203.0.113.5"})
+		}
+	}
+
+	add(10)
+	if !m.follow || m.cursor != 9 {
+		t.Fatalf("new model: follow %v, cursor %d", m.follow, m.cursor)
+	}
+	m = press(m, "k")
+	add(5)
+	if m.follow || m.cursor != 8 {
+		t.Errorf("after k: follow %v, cursor %d, want 8", m.follow, m.cursor)
+	}
+	m = press(m, "f")
+	add(1)
+	if !m.follow || m.cursor != 15 {
+		t.Errorf("after f: follow %v, cursor %d, want 15", m.follow, m.cursor)
+	}
+	m = press(m, "k")
+	m = press(m, "j") // back on the last row follows again
+	add(1)
+	if !m.follow || m.cursor != 16 {
+		t.Errorf("after k j: follow %v, cursor %d, want 16", m.follow, m.cursor)
+	}
+	if !strings.Contains(m.View(), "LIVE") {
+		t.Error("footer does not show LIVE")
+	}
+	m = press(m, "g")
+	if m.follow || !strings.Contains(m.View(), "SCROLL") {
+		t.Errorf("after g: follow %v, footer lacks SCROLL", m.follow)
+	}
+}
+
+func TestStartTab(t *testing.T) {
+	for name, want := range map[string]int{"logs": TabLogs, "Stats": TabStats, "filters": TabFilters, "conns": TabConns, "raw": TabRaw} {
+		got, err := ParseTab(name)
+		if err != nil || got != want {
+			t.Errorf("ParseTab(%q) = %d, %v; want %d", name, got, err, want)
+		}
+	}
+	if _, err := ParseTab("log"); err == nil {
+		t.Error("ParseTab(\"log\") should fail")
+	}
+
+	m := newTestModel(Options{Tab: TabStats})
+	if m.tab != TabStats {
+		t.Errorf("tab = %d, want TabStats", m.tab)
+	}
+}
+
+func TestDetailUserResolvedAsync(t *testing.T) {
+	m := New(nil, func(string) string { return "Internal" }, Options{})
+	m.width, m.height = 100, 50
+	uid := 4242
+	m.addEntry(parser.LogEntry{Proto: "TCP", Src: "10.0.0.1", Dst: "10.0.0.2", DstPort: 22, UID: &uid})
+
+	next, cmd := m.handleKey(keyMsg("enter"))
+	m = next.(Model)
+	if cmd == nil || !m.userPending[uid] {
+		t.Fatalf("opening the detail page did not start the UID lookup")
+	}
+	m.View()
+	if _, cached := m.userNames[uid]; cached {
+		t.Error("View looked the user up itself")
+	}
+
+	next, _ = m.Update(UserMsg{UID: uid, Name: "alice"})
+	m = next.(Model)
+	if out := ansi.Strip(m.View()); !strings.Contains(out, "4242 (alice)") {
+		t.Errorf("user name not shown:\n%s", out)
+	}
+	if m.lookupUser(uid) != nil {
+		t.Error("known UID looked up again")
+	}
+}
+
+func TestHelpOverlayScrolls(t *testing.T) {
+	m := newTestModel(Options{})
+	m.width, m.height = 80, 24
+	maxScroll := ui.HelpMaxScroll(m.width, m.bodyHeight(), m.keys)
+	if maxScroll == 0 {
+		t.Fatal("help overlay fits on 80x24; nothing to scroll")
+	}
+
+	m = press(m, "?", "k")
+	if !m.helpOpen || m.helpScroll != 0 {
+		t.Fatalf("open=%v scroll=%d, want open at the top", m.helpOpen, m.helpScroll)
+	}
+	m = press(m, "j", "j")
+	if m.helpScroll != 2 {
+		t.Errorf("scroll = %d after two downs, want 2", m.helpScroll)
+	}
+	m = press(m, "G", "j")
+	if m.helpScroll != maxScroll {
+		t.Errorf("scroll = %d, want clamped to %d", m.helpScroll, maxScroll)
+	}
+	if out := ansi.Strip(m.View()); !strings.Contains(out, "copy the raw line") {
+		t.Errorf("last binding not reachable:\n%s", out)
+	}
+
+	m = press(m, "?", "?")
+	if !m.helpOpen || m.helpScroll != 0 {
+		t.Errorf("reopened help at scroll %d, want 0", m.helpScroll)
+	}
+}
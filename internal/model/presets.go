@@ -0,0 +1,129 @@
+package model
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/espenotterstad/iptables-log-tui/internal/ui"
+)
+
+// openPreset opens the prompt that saves the current filters under a name
+// (save) or applies a saved preset. It reports false, with a status saying
+// why, when there is nothing to save or apply.
+func (m *Model) openPreset(save bool) bool {
+	switch {
+	case save && !m.filters.Active():
+		m.setStatus("No filters set — nothing to save")
+		return false
+	case save && m.opts.PresetsPath == "":
+		m.setStatus("Presets cannot be saved: no config directory")
+		return false
+	case !save && len(m.presets) == 0:
+		m.setStatus("No saved presets")
+		return false
+	}
+	m.presetEntry = true
+	m.presetSave = save
+	m.presetErr = ""
+	m.presetInput.SetValue("")
+	m.presetInput.Placeholder = "number or name"
+	if save {
+		m.presetInput.Placeholder = "name"
+	}
+	m.presetInput.Focus()
+	return true
+}
+
+// handlePresetKey handles a key while the preset prompt is open. Enter saves
+// the filters under the typed name, replacing a preset of that name, or
+// applies the preset with the typed number (as listed on the Filters tab) or
+// name. Errors keep the prompt open; Esc closes it.
+func (m Model) handlePresetKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.closePreset()
+		return m, nil
+	case "enter":
+		v := strings.TrimSpace(m.presetInput.Value())
+		if m.presetSave {
+			presets, err := ui.SavePreset(m.opts.PresetsPath, v, m.filters)
+			if err != nil {
+				m.presetErr = err.Error()
+				return m, nil
+			}
+			m.closePreset()
+			m.presets = presets
+			m.setStatus(fmt.Sprintf("Saved preset %d: %s", ui.FindPreset(presets, v)+1, v))
+			return m, nil
+		}
+		i := ui.FindPreset(m.presets, v)
+		if n, err := strconv.Atoi(v); err == nil && n >= 1 && n <= len(m.presets) {
+			i = n - 1
+		}
+		if i < 0 {
+			m.presetErr = fmt.Sprintf("no preset %q", v)
+			return m, nil
+		}
+		m.closePreset()
+		return m, m.applyPreset(m.presets[i])
+	}
+	var cmd tea.Cmd
+	m.presetInput, cmd = m.presetInput.Update(msg)
+	return m, cmd
+}
+
+func (m *Model) closePreset() {
+	m.presetEntry = false
+	m.presetErr = ""
+	m.presetInput.Blur()
+}
+
+// applyPreset replaces the filters with p's and refilters at once. The search
+// prompt is set up to show the preset's search, so editing it carries on
+// from there. The returned command starts the time-window ticker if the
+// preset has a window and it is not already running.
+func (m *Model) applyPreset(p ui.Preset) tea.Cmd {
+	m.filters = p.Filters
+	switch {
+	case p.Filters.Regex != nil:
+		m.searchMode = searchRegex
+		m.searchInput.SetValue(p.Filters.Regex.String())
+	case p.Filters.TextSearch != "":
+		m.searchMode = searchText
+		m.searchInput.SetValue(p.Filters.TextSearch)
+	default:
+		m.searchMode = searchIP
+		m.searchInput.SetValue(p.Filters.IPSubstr)
+	}
+	m.applyFilters()
+	m.setStatus("Preset applied: " + p.Name)
+	if m.filters.SinceMinutes != 0 && !m.windowTicking {
+		m.windowTicking = true
+		return windowTick()
+	}
+	return nil
+}
+
+// presetPrompt renders the footer while the preset prompt is open; when
+// applying, it lists the presets by number.
+func (m Model) presetPrompt() string {
+	label := "  Apply preset: "
+	if m.presetSave {
+		label = "  Save filters as: "
+	}
+	s := label + m.presetInput.View() + "  "
+	if m.presetErr != "" {
+		return s + ui.StyleDrop.Render(m.presetErr)
+	}
+	if m.presetSave {
+		return s + ui.StyleHelp.Render("[Enter] save  [Esc] cancel")
+	}
+	var help []string
+	for i, p := range m.presets {
+		help = append(help, fmt.Sprintf("%d %s", i+1, p.Name))
+	}
+	help = append(help, "[Enter] apply", "[Esc] cancel")
+	return s + ui.StyleHelp.Render(hints(help...))
+}
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
+	"github.com/espenotterstad/iptables-log-tui/internal/ui"
+)
+
+func TestSaveAndApplyPreset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "presets.json")
+	m := newTestModel(Options{PresetsPath: path})
+	m.addEntry(parser.LogEntry{Proto: "TCP", Prefix: "DROP", Src: "203.0.113.5", DstPort: 22})
+	m.addEntry(parser.LogEntry{Proto: "UDP", Prefix: "ACCEPT", Src: "203.0.113.6", DstPort: 53})
+
+	m = press(m, "N")
+	if m.presetEntry {
+		t.Fatal("save prompt opened with no filters set")
+	}
+	m = press(m, "d", "t", "N", "s", "s", "h", "enter")
+	if m.presetEntry || len(m.presets) != 1 || m.presets[0].Name != "ssh" {
+		t.Fatalf("after save: entry=%v presets=%+v err=%q", m.presetEntry, m.presets, m.presetErr)
+	}
+	if saved, err := ui.LoadPresets(path); err != nil || len(saved) != 1 {
+		t.Fatalf("presets file: %+v, %v", saved, err)
+	}
+
+	m = press(m, "c")
+	if len(m.filtered) != 2 {
+		t.Fatalf("after clear: %d rows", len(m.filtered))
+	}
+	m = press(m, "R", "7", "enter")
+	if !m.presetEntry || m.presetErr == "" {
+		t.Errorf("preset 7 accepted: entry=%v err=%q", m.presetEntry, m.presetErr)
+	}
+	m.presetInput.SetValue("1")
+	m = press(m, "enter")
+	if m.presetEntry || !slices.Equal(m.filters.Action, []string{"DROP"}) || !slices.Equal(m.filters.Proto, []string{"TCP"}) || len(m.filtered) != 1 {
+		t.Errorf("after apply: entry=%v filters=%+v rows=%d", m.presetEntry, m.filters, len(m.filtered))
+	}
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"strconv"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/espenotterstad/iptables-log-tui/internal/classifier"
+	"github.com/espenotterstad/iptables-log-tui/internal/keymap"
+	"github.com/espenotterstad/iptables-log-tui/internal/nettools"
+)
+
+// ProbeMsg carries the result of a ping or traceroute started from the
+// detail page.
+type ProbeMsg struct {
+	nettools.Result
+}
+
+// startProbe opens the probe box for the detail entry's source and returns
+// the command running ping or traceroute (action) against it. Like whois, it
+// only probes External sources, and only a Src that is an IP address (the
+// classifier calls anything else External); others get a status message and
+// no command.
+func (m *Model) startProbe(action keymap.Action) tea.Cmd {
+	ip := m.detailEntry.Src
+	if !nettools.ValidAddress(ip) {
+		m.setStatus("Not probing " + strconv.Quote(ip) + ": not an IP address")
+		return nil
+	}
+	if m.categorize(ip) != classifier.CatExternal {
+		m.setStatus("Ping and traceroute only run for external sources")
+		return nil
+	}
+	run, tool := nettools.Ping, "ping"
+	if action == keymap.Traceroute {
+		run, tool = nettools.Traceroute, "traceroute"
+	}
+	m.probeOpen, m.probeRunning = true, true
+	m.probe = nettools.Result{Tool: tool, IP: ip}
+	return func() tea.Msg {
+		return ProbeMsg{run(ip)}
+	}
+}
+
+// finishProbe shows msg in the probe box, unless the box has been closed or
+// has moved on to another run since msg's was started.
+func (m *Model) finishProbe(msg ProbeMsg) {
+	if m.probeOpen && m.probeRunning && msg.Tool == m.probe.Tool && msg.IP == m.probe.IP {
+		m.probe, m.probeRunning = msg.Result, false
+	}
+}
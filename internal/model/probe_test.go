@@ -0,0 +1,66 @@
+package model
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/espenotterstad/iptables-log-tui/internal/classifier"
+	"github.com/espenotterstad/iptables-log-tui/internal/nettools"
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
+)
+
+func TestProbeFromDetailPage(t *testing.T) {
+	cat := func(ip string) string {
+		if ip == "10.0.0.5" {
+			return classifier.CatPrivate
+		}
+		return classifier.CatExternal
+	}
+	m := New(nil, cat, Options{})
+	m.detailOpen = true
+	m.detailEntry = parser.LogEntry{Proto: "TCP", Src: "10.0.0.5"}
+
+	pressCmd := func(k string) tea.Cmd {
+		next, cmd := m.handleKey(keyMsg(k))
+		m = next.(Model)
+		return cmd
+	}
+	if cmd := pressCmd("I"); cmd != nil || m.probeOpen || m.status == "" {
+		t.Fatal("probed a private source")
+	}
+
+	// The classifier calls what is not an address External; it is still
+	// never handed to ping, where it could pass as an option.
+	m.detailEntry.Src = "-fi0.001"
+	if cmd := pressCmd("I"); cmd != nil || m.probeOpen || !strings.Contains(m.status, "not an IP address") {
+		t.Fatalf("probed %q: status %q", m.detailEntry.Src, m.status)
+	}
+
+	m.detailEntry.Src = "203.0.113.5"
+	if cmd := pressCmd("I"); cmd == nil || !m.probeOpen || !m.probeRunning || m.probe.Tool != "ping" {
+		t.Fatalf("ping not started: open %v running %v probe %+v", m.probeOpen, m.probeRunning, m.probe)
+	}
+	// Keys other than Esc are swallowed while the box is open.
+	if cmd := pressCmd("U"); cmd != nil || m.probe.Tool != "ping" {
+		t.Error("traceroute started over a running ping")
+	}
+
+	// A result for another run is dropped.
+	next, _ := m.Update(ProbeMsg{nettools.Result{Tool: "traceroute", IP: "203.0.113.5"}})
+	m = next.(Model)
+	if !m.probeRunning {
+		t.Error("result of another run accepted")
+	}
+	next, _ = m.Update(ProbeMsg{nettools.Result{Tool: "ping", IP: "203.0.113.5", Output: "1 received", Err: errors.New("x")}})
+	m = next.(Model)
+	if m.probeRunning || m.probe.Output != "1 received" {
+		t.Errorf("result not shown: %+v", m.probe)
+	}
+
+	m = press(m, "esc")
+	if m.probeOpen || !m.detailOpen {
+		t.Errorf("Esc: probe open %v, detail open %v; want only the box closed", m.probeOpen, m.detailOpen)
+	}
+}
@@ -0,0 +1,55 @@
+package model
+
+import (
+	"time"
+
+	"github.com/espenotterstad/iptables-log-tui/internal/clipboard"
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
+	"github.com/espenotterstad/iptables-log-tui/internal/ui"
+)
+
+// maxRejected caps how many unparsed lines the Raw tab keeps; the oldest are
+// dropped beyond it.
+const maxRejected = 500
+
+// reject records a line ParseLine could not read, for the Raw tab.
+func (m *Model) reject(line, source string, err error) {
+	m.parseErrors++
+	if len(m.rejected) == maxRejected {
+		m.rejected = append(m.rejected[:0], m.rejected[1:]...)
+	}
+	m.rejected = append(m.rejected, ui.RawLine{Time: time.Now(), Source: source, Line: line, Err: err.Error()})
+}
+
+// anonymizeRaw returns a copy of lines with every address in the line and its
+// parse error masked, for display only; copyRaw still copies the real line.
+func anonymizeRaw(lines []ui.RawLine) []ui.RawLine {
+	out := make([]ui.RawLine, len(lines))
+	for i, l := range lines {
+		l.Line, l.Err = parser.AnonymizeText(l.Line), parser.AnonymizeText(l.Err)
+		out[i] = l
+	}
+	return out
+}
+
+// selectedRaw returns the Raw tab's selected line; the tab lists the newest
+// first.
+func (m Model) selectedRaw() (ui.RawLine, bool) {
+	if m.rawCursor >= len(m.rejected) {
+		return ui.RawLine{}, false
+	}
+	return m.rejected[len(m.rejected)-1-m.rawCursor], true
+}
+
+// copyRaw copies the selected unparsed line, e.g. for a parser bug report.
+func (m *Model) copyRaw() {
+	l, ok := m.selectedRaw()
+	if !ok {
+		return
+	}
+	if err := clipboard.Copy(l.Line); err != nil {
+		m.setStatus("Copy failed: " + err.Error())
+		return
+	}
+	m.setStatus("Copied the raw line to clipboard")
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+func TestRejectedLinesKept(t *testing.T) {
+	m := newTestModel(Options{})
+	m.width, m.height = 120, 30
+
+	send := func(line string) {
+		next, _ := m.Update(NewLineMsg{Line: line, Source: "/var/log/kern.log"})
+		m = next.(Model)
+	}
+	send("Jan  2 03:04:05 host kernel: [UFW BLOCK] IN=eth0 OUT= SRC=203.0.113.5 DST=192.0.2.2 PROTO=TCP SPT=1 DPT=22")
+	send("Jan  2 03:04:06 host sshd[42]: Accepted publickey for root")
+	if len(m.rejected) != 1 || m.all.Len() != 1 {
+		t.Fatalf("rejected %d, parsed %d; want 1 and 1", len(m.rejected), m.all.Len())
+	}
+	if r := m.rejected[0]; !strings.Contains(r.Line, "sshd") || r.Err == "" || r.Source != "/var/log/kern.log" {
+		t.Errorf("rejected = %+v", r)
+	}
+
+	m = press(m, "5")
+	out := ansi.Strip(m.View())
+	if m.tab != TabRaw || !strings.Contains(out, "Accepted publickey") || !strings.Contains(out, "Error:") {
+		t.Errorf("Raw tab view:\n%s", out)
+	}
+
+	for i := range maxRejected + 10 {
+		send(fmt.Sprintf("garbage %d", i))
+	}
+	if len(m.rejected) != maxRejected || m.rejected[0].Line != "garbage 10" {
+		t.Errorf("after overflow: %d kept, oldest %q", len(m.rejected), m.rejected[0].Line)
+	}
+	if m.parseErrors != maxRejected+11 {
+		t.Errorf("parseErrors = %d, want %d", m.parseErrors, maxRejected+11)
+	}
+	m = press(m, "2")
+	if out := ansi.Strip(m.View()); !regexp.MustCompile(fmt.Sprintf(`Unparsed lines +%d\n`, maxRejected+11)).MatchString(out) {
+		t.Errorf("Stats tab has no unparsed count:\n%s", out)
+	}
+	if l, _ := m.selectedRaw(); l.Line != fmt.Sprintf("garbage %d", maxRejected+9) {
+		t.Errorf("selected %q, want the newest", l.Line)
+	}
+}
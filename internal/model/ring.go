@@ -0,0 +1,62 @@
+package model
+
+import (
+	"sort"
+
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
+)
+
+// entryRing holds the most recent entries. Once max entries are stored each
+// push overwrites the oldest one, so memory stays constant on a busy
+// firewall. A max of 0 or less means unbounded.
+type entryRing struct {
+	buf  []parser.LogEntry
+	head int // index of the oldest entry once the ring is full
+	max  int
+}
+
+func newEntryRing(max int) entryRing {
+	return entryRing{max: max}
+}
+
+// Push appends e and reports whether the oldest entry was evicted for it.
+func (r *entryRing) Push(e parser.LogEntry) bool {
+	if r.max <= 0 || len(r.buf) < r.max {
+		r.buf = append(r.buf, e)
+		return false
+	}
+	r.buf[r.head] = e
+	r.head = (r.head + 1) % r.max
+	return true
+}
+
+// Len returns the number of retained entries.
+func (r *entryRing) Len() int {
+	return len(r.buf)
+}
+
+// At returns the i-th retained entry, oldest first.
+func (r *entryRing) At(i int) parser.LogEntry {
+	return r.buf[(r.head+i)%len(r.buf)]
+}
+
+// SortByTime reorders the retained entries by Timestamp, oldest first. The
+// sort is stable so entries with equal timestamps keep arrival order.
+func (r *entryRing) SortByTime() {
+	ordered := make([]parser.LogEntry, len(r.buf))
+	for i := range ordered {
+		ordered[i] = r.At(i)
+	}
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Timestamp.Before(ordered[j].Timestamp)
+	})
+	r.buf, r.head = ordered, 0
+}
+
+// Newest returns the most recently pushed entry; ok is false when empty.
+func (r *entryRing) Newest() (e parser.LogEntry, ok bool) {
+	if len(r.buf) == 0 {
+		return e, false
+	}
+	return r.At(len(r.buf) - 1), true
+}
@@ -0,0 +1,113 @@
+package model
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
+)
+
+func TestEntryRingEvictsOldest(t *testing.T) {
+	r := newEntryRing(3)
+	for p := 1; p <= 5; p++ {
+		evicted := r.Push(parser.LogEntry{DstPort: p})
+		if want := p > 3; evicted != want {
+			t.Errorf("Push(%d) evicted = %v, want %v", p, evicted, want)
+		}
+	}
+	if r.Len() != 3 {
+		t.Fatalf("Len = %d, want 3", r.Len())
+	}
+	for i, want := range []int{3, 4, 5} {
+		if got := r.At(i).DstPort; got != want {
+			t.Errorf("At(%d) = %d, want %d", i, got, want)
+		}
+	}
+}
+
+func TestEntryRingUnbounded(t *testing.T) {
+	r := newEntryRing(0)
+	for p := 1; p <= 100; p++ {
+		if r.Push(parser.LogEntry{DstPort: p}) {
+			t.Fatal("unbounded ring evicted an entry")
+		}
+	}
+	if r.Len() != 100 || r.At(0).DstPort != 1 {
+		t.Errorf("Len = %d, At(0) = %d; want 100, 1", r.Len(), r.At(0).DstPort)
+	}
+}
+
+func TestAddEntryEvictionKeepsStats(t *testing.T) {
+	m := newTestModel(Options{MaxEntries: 200})
+	for p := 1; p <= 1000; p++ {
+		m.addEntry(parser.LogEntry{DstPort: p, Prefix: "DROP"})
+	}
+	if m.all.Len() != 200 {
+		t.Errorf("retained %d entries, want 200", m.all.Len())
+	}
+	if m.stats.Total != 1000 {
+		t.Errorf("stats.Total = %d, want 1000", m.stats.Total)
+	}
+	// filtered may lag all by one trim batch (cap/trimFraction), no more.
+	if n := len(m.filtered); n < 200 || n > 200+200/trimFraction {
+		t.Errorf("len(filtered) = %d, want 200..%d", n, 200+200/trimFraction)
+	}
+	if last := m.filtered[len(m.filtered)-1].DstPort; last != 1000 || m.cursor != len(m.filtered)-1 {
+		t.Errorf("cursor %d on port %d, want following the newest entry (1000)", m.cursor, last)
+	}
+}
+
+func TestEvictionKeepsScrolledCursor(t *testing.T) {
+	m := newTestModel(Options{MaxEntries: 200})
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	add := func(p int) {
+		m.addEntry(parser.LogEntry{Timestamp: base.Add(time.Duration(p) * time.Second), DstPort: p, Prefix: "DROP",
+			Raw: fmt.Sprintf("DROP DPT=%d", p)})
+	}
+	for p := 1; p <= 200; p++ {
+		add(p)
+	}
+	m.follow = false
+	m.cursor = 150 // port 151
+	for p := 201; p <= 1000; p++ {
+		add(p)
+		if got := m.filtered[m.cursor].DstPort; got != 151 && p < 350 {
+			t.Fatalf("after adding port %d the cursor is on port %d, want 151", p, got)
+		}
+	}
+	// Port 151 itself has been evicted: the cursor is on the oldest entry
+	// left, the one after it, rather than drifting further.
+	if got, want := m.filtered[m.cursor].DstPort, m.filtered[0].DstPort; got != want {
+		t.Errorf("cursor on port %d, want the oldest retained entry (%d)", got, want)
+	}
+}
+
+func TestEvictionKeepsDetailCursor(t *testing.T) {
+	m := newTestModel(Options{MaxEntries: 200})
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	add := func(p int) {
+		m.addEntry(parser.LogEntry{Timestamp: base.Add(time.Duration(p) * time.Second), DstPort: p, Prefix: "DROP",
+			Raw: fmt.Sprintf("DROP DPT=%d", p)})
+	}
+	for p := 1; p <= 200; p++ {
+		add(p)
+	}
+	// Open the newest entry while following, then let evictions happen.
+	m = press(m, "enter")
+	for p := 201; p <= 300; p++ {
+		add(p)
+	}
+	if m.detailEntry.DstPort != 200 || m.filtered[m.cursor].DstPort != 200 {
+		t.Fatalf("detail shows port %d, cursor on port %d; want both on 200", m.detailEntry.DstPort, m.filtered[m.cursor].DstPort)
+	}
+
+	m = press(m, "k")
+	if m.detailEntry.DstPort != 199 {
+		t.Errorf("k: detail shows port %d, want 199", m.detailEntry.DstPort)
+	}
+	m = press(m, "j", "j")
+	if m.detailEntry.DstPort != 201 {
+		t.Errorf("j j: detail shows port %d, want 201", m.detailEntry.DstPort)
+	}
+}
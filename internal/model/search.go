@@ -0,0 +1,102 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
+	"github.com/espenotterstad/iptables-log-tui/internal/ports"
+)
+
+// searchMode selects what the "/" search input filters on.
+type searchMode int
+
+const (
+	searchIP    searchMode = iota // substring of Src or Dst (Filters.IPSubstr)
+	searchText                    // substring of any displayed field (Filters.TextSearch)
+	searchRegex                   // regular expression on the raw line (Filters.Regex)
+)
+
+var searchPrompts = map[searchMode]string{
+	searchIP:    "IP filter: ",
+	searchText:  "Text search: ",
+	searchRegex: "Regex: ",
+}
+
+// handleSearchKey handles a key while the search input is open. Tab switches
+// mode, carrying the typed text over; Esc and Enter close the input.
+func (m Model) handleSearchKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "tab":
+		m.searchMode = (m.searchMode + 1) % searchMode(len(searchPrompts))
+		m.setSearch(m.searchInput.Value())
+		return m, nil
+	case "esc", "enter":
+		m.searching = false
+		m.searchInput.Blur()
+		m.setSearch(m.searchInput.Value())
+	}
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	m.setSearch(m.searchInput.Value())
+	return m, cmd
+}
+
+// setSearch stores v in the filter for the current mode, clears the filters
+// of the other modes, and re-applies filters. In regex mode an invalid
+// pattern is reported in searchErr and the last valid one stays in effect,
+// so a half-typed expression neither errors out nor empties the table.
+func (m *Model) setSearch(v string) {
+	m.searchErr = ""
+	if m.searchMode == searchRegex {
+		if v == "" {
+			m.filters.Regex = nil
+		} else if re, err := regexp.Compile(v); err != nil {
+			m.searchErr = regexErrText(err)
+		} else {
+			m.filters.Regex = re
+		}
+	} else {
+		m.filters.Regex = nil
+	}
+	m.filters.IPSubstr, m.filters.TextSearch = "", ""
+	switch m.searchMode {
+	case searchIP:
+		m.filters.IPSubstr = v
+	case searchText:
+		m.filters.TextSearch = v
+	}
+	m.applyFilters()
+}
+
+// regexErrText shortens a regexp compile error to its reason, e.g.
+// "missing closing )", dropping the repeated pattern.
+func regexErrText(err error) string {
+	var se *syntax.Error
+	if errors.As(err, &se) {
+		return string(se.Code)
+	}
+	return err.Error()
+}
+
+// searchText returns the lowercase text the free-text search matches: the
+// prefix, action, interfaces, protocol, category, addresses, ports and the
+// port service names, as shown in the table and detail page.
+func (m Model) searchText(e parser.LogEntry) string {
+	fields := []string{
+		e.Prefix, e.Action(), e.In, e.Out, e.Proto, m.categorize(e.Src), e.Src, e.Dst,
+	}
+	if e.SrcPort != 0 {
+		fields = append(fields, fmt.Sprint(e.SrcPort), ports.Lookup(e.SrcPort, e.Proto))
+	}
+	if e.DstPort != 0 {
+		fields = append(fields, fmt.Sprint(e.DstPort), ports.Lookup(e.DstPort, e.Proto))
+	}
+	// Join with a separator that cannot appear in a query, so a match never
+	// straddles two fields.
+	return strings.ToLower(strings.Join(fields, "\x00"))
+}
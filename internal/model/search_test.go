@@ -0,0 +1,267 @@
+package model
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/espenotterstad/iptables-log-tui/internal/classifier"
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
+)
+
+func TestTextSearchMatchesDisplayedFields(t *testing.T) {
+	m := newTestModel(Options{})
+	e := parser.LogEntry{Prefix: "UFW BLOCK", In: "eth0", Proto: "TCP", Src: "203.0.113.5", Dst: "192.168.1.1", DstPort: 22}
+
+	for _, q := range []string{"ufw", "DROP", "eth0", "tcp", "external", "113.5", "22", "SSH"} {
+		m.filters.TextSearch = q
+		if !m.matchesFilter(e) {
+			t.Errorf("TextSearch %q did not match", q)
+		}
+	}
+	for _, q := range []string{"udp", "https", "eth0tcp"} {
+		m.filters.TextSearch = q
+		if m.matchesFilter(e) {
+			t.Errorf("TextSearch %q matched unexpectedly", q)
+		}
+	}
+}
+
+func TestRegexSearchKeepsLastValidPattern(t *testing.T) {
+	m := newTestModel(Options{})
+	m.searchMode = searchRegex
+
+	m.setSearch(`DPT=(22|23)\b`)
+	if m.searchErr != "" || m.filters.Regex == nil {
+		t.Fatalf("valid pattern rejected: %q", m.searchErr)
+	}
+	ssh := parser.LogEntry{Raw: "... PROTO=TCP SPT=4000 DPT=22 WINDOW=1024"}
+	web := parser.LogEntry{Raw: "... PROTO=TCP SPT=4000 DPT=443 WINDOW=1024"}
+	if !m.matchesFilter(ssh) || m.matchesFilter(web) {
+		t.Error("regex filter mismatch")
+	}
+
+	m.setSearch(`DPT=(22|23`)
+	if m.searchErr == "" {
+		t.Error("invalid pattern not reported")
+	}
+	if m.filters.Regex == nil || m.filters.Regex.String() != `DPT=(22|23)\b` {
+		t.Errorf("last valid pattern not kept, got %v", m.filters.Regex)
+	}
+
+	m.setSearch("")
+	if m.filters.Regex != nil || m.searchErr != "" {
+		t.Error("empty input did not clear the regex")
+	}
+}
+
+func TestExternalOnlyFilter(t *testing.T) {
+	m := New(nil, classifier.New().Categorize, Options{})
+	m.addEntry(parser.LogEntry{Proto: "TCP", Src: "203.0.113.5"})
+	m.addEntry(parser.LogEntry{Proto: "TCP", Src: "192.168.1.10"})
+
+	m = press(m, "e")
+	if len(m.filtered) != 1 || m.filtered[0].Src != "203.0.113.5" {
+		t.Errorf("e: filtered = %v, want only the external source", m.filtered)
+	}
+	m = press(m, "e")
+	if m.filters.Category != "" || len(m.filtered) != 2 {
+		t.Errorf("second e: Category = %q, %d rows", m.filters.Category, len(m.filtered))
+	}
+}
+
+func TestDirectionFilter(t *testing.T) {
+	m := newTestModel(Options{})
+	m.addEntry(parser.LogEntry{Proto: "TCP", In: "eth0", Src: "203.0.113.5"})
+	m.addEntry(parser.LogEntry{Proto: "TCP", Out: "eth0", Src: "192.0.2.1"})
+	m.addEntry(parser.LogEntry{Proto: "TCP", In: "eth0", Out: "eth1", Src: "203.0.113.6"})
+
+	for _, want := range []struct {
+		dir string
+		src string
+	}{{"in", "203.0.113.5"}, {"out", "192.0.2.1"}, {"fwd", "203.0.113.6"}} {
+		m = press(m, "i")
+		if m.filters.Direction != want.dir || len(m.filtered) != 1 || m.filtered[0].Src != want.src {
+			t.Errorf("direction %q: filtered = %v, want only %s", m.filters.Direction, m.filtered, want.src)
+		}
+	}
+	m = press(m, "i")
+	if m.filters.Direction != "" || len(m.filtered) != 3 {
+		t.Errorf("after fwd: Direction = %q, %d rows", m.filters.Direction, len(m.filtered))
+	}
+}
+
+func TestActionAndProtoSets(t *testing.T) {
+	m := newTestModel(Options{})
+	m.addEntry(parser.LogEntry{Proto: "TCP", Prefix: "DROP", Src: "203.0.113.5"})
+	m.addEntry(parser.LogEntry{Proto: "UDP", Prefix: "ACCEPT", Src: "203.0.113.6"})
+	m.addEntry(parser.LogEntry{Proto: "ICMP", Prefix: "DROP", Src: "203.0.113.7"})
+
+	m = press(m, "d")
+	if len(m.filtered) != 2 {
+		t.Errorf("DROP: %d rows, want 2", len(m.filtered))
+	}
+	m = press(m, "a")
+	if len(m.filtered) != 3 {
+		t.Errorf("DROP or ACCEPT: %d rows, want 3", len(m.filtered))
+	}
+	m = press(m, "t", "u")
+	if len(m.filtered) != 2 {
+		t.Errorf("DROP or ACCEPT, TCP or UDP: %d rows, want 2", len(m.filtered))
+	}
+	m = press(m, "d")
+	if len(m.filtered) != 1 || m.filtered[0].Src != "203.0.113.6" {
+		t.Errorf("ACCEPT, TCP or UDP: filtered = %v", m.filtered)
+	}
+
+	// Toggling copies the set, so a preset applied earlier keeps its own.
+	preset := []string{"DROP"}
+	m.filters.Action = preset
+	m = press(m, "a")
+	if len(preset) != 1 || preset[0] != "DROP" {
+		t.Errorf("preset set changed to %v", preset)
+	}
+}
+
+func TestRejectAndCycleAction(t *testing.T) {
+	m := newTestModel(Options{})
+	m.addEntry(parser.LogEntry{Proto: "TCP", Prefix: "DROP", Src: "203.0.113.5"})
+	m.addEntry(parser.LogEntry{Proto: "TCP", Prefix: "REJECT", Src: "203.0.113.6"})
+	m.addEntry(parser.LogEntry{Proto: "TCP", Prefix: "RATE-LIMIT", Src: "203.0.113.7"})
+
+	m = press(m, "r")
+	if len(m.filtered) != 1 || m.filtered[0].Src != "203.0.113.6" {
+		t.Errorf("REJECT: filtered = %v", m.filtered)
+	}
+	m = press(m, "d")
+	if len(m.filtered) != 2 {
+		t.Errorf("REJECT or DROP: %d rows, want 2", len(m.filtered))
+	}
+
+	// A set of several starts the cycle again from the first, alphabetically.
+	for _, want := range []string{"DROP", "RATE-LIMIT", "REJECT"} {
+		m = press(m, "A")
+		if len(m.filters.Action) != 1 || m.filters.Action[0] != want || len(m.filtered) != 1 {
+			t.Errorf("cycle: Action = %v, %d rows; want %s only", m.filters.Action, len(m.filtered), want)
+		}
+	}
+	m = press(m, "A")
+	if len(m.filters.Action) != 0 || len(m.filtered) != 3 {
+		t.Errorf("after the last action: Action = %v, %d rows", m.filters.Action, len(m.filtered))
+	}
+}
+
+func TestDimAndHideAccept(t *testing.T) {
+	m := newTestModel(Options{})
+	m.addEntry(parser.LogEntry{Proto: "TCP", Prefix: "DROP", Src: "203.0.113.5"})
+	m.addEntry(parser.LogEntry{Proto: "TCP", Prefix: "ACCEPT", Src: "203.0.113.6"})
+
+	m = press(m, "H")
+	if !m.tableOptions().DimAccept || len(m.filtered) != 2 {
+		t.Errorf("dimmed: DimAccept %v, %d rows", m.tableOptions().DimAccept, len(m.filtered))
+	}
+	m = press(m, "H")
+	if m.tableOptions().DimAccept || len(m.filtered) != 1 || m.filtered[0].Action() != "DROP" {
+		t.Errorf("hidden: DimAccept %v, filtered = %v", m.tableOptions().DimAccept, m.filtered)
+	}
+	m.width, m.height, m.status = 200, 40, ""
+	if out := ansi.Strip(m.View()); !strings.Contains(out, "ACCEPT hidden") {
+		t.Errorf("footer does not say ACCEPT rows are hidden:\n%s", out)
+	}
+	m.tab = TabFilters
+	out := ansi.Strip(m.View())
+	if !strings.Contains(out, "Hidden:         ACCEPT rows") || strings.Contains(out, "all entries are shown") {
+		t.Errorf("Filters tab does not say ACCEPT rows are hidden:\n%s", out)
+	}
+	m.tab = TabLogs
+	m = press(m, "H")
+	if m.accepts != acceptsShown || len(m.filtered) != 2 {
+		t.Errorf("shown: mode %d, %d rows", m.accepts, len(m.filtered))
+	}
+}
+
+func TestDropsOnly(t *testing.T) {
+	m := newTestModel(Options{DropsOnly: true})
+	if m.tab != TabLogs || !m.follow {
+		t.Errorf("tab %d, follow %v: want the Logs tab following live", m.tab, m.follow)
+	}
+	for _, prefix := range []string{"DROP", "REJECT", "ACCEPT"} {
+		m.addEntry(parser.LogEntry{Proto: "TCP", Prefix: prefix, Src: "203.0.113.5"})
+	}
+	if len(m.filtered) != 2 || m.filtered[0].Action() == "ACCEPT" || m.filtered[1].Action() == "ACCEPT" {
+		t.Errorf("filtered = %v, want the DROP and REJECT entries", m.filtered)
+	}
+
+	m = press(m, "d")
+	if len(m.filtered) != 1 || m.filtered[0].Action() != "REJECT" {
+		t.Errorf("after d: Action = %v, filtered = %v", m.filters.Action, m.filtered)
+	}
+	m = press(m, "c")
+	if len(m.filters.Action) != 0 || len(m.filtered) != 3 {
+		t.Errorf("after c: Action = %v, %d rows", m.filters.Action, len(m.filtered))
+	}
+}
+
+func TestFilterKeepsCursorOnEntry(t *testing.T) {
+	m := newTestModel(Options{})
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	for i, prefix := range []string{"DROP", "ACCEPT", "DROP", "ACCEPT", "DROP"} {
+		m.addEntry(parser.LogEntry{Timestamp: base.Add(time.Duration(i) * time.Second), Proto: "TCP", Prefix: prefix,
+			Src: "203.0.113." + string(rune('1'+i)), Raw: prefix + string(rune('1'+i))})
+	}
+	m.follow = false
+	m.cursor = 2
+
+	m = press(m, "d")
+	if got := m.filtered[m.cursor].Src; got != "203.0.113.3" {
+		t.Errorf("after d: cursor on %s, want the selected 203.0.113.3", got)
+	}
+	m = press(m, "c")
+	if m.cursor != 2 {
+		t.Errorf("after c: cursor %d, want 2", m.cursor)
+	}
+
+	// The selected entry is filtered out: the cursor takes the row after it.
+	m.cursor = 1
+	m = press(m, "d")
+	if got := m.filtered[m.cursor].Src; got != "203.0.113.3" {
+		t.Errorf("entry gone: cursor on %s, want the next entry 203.0.113.3", got)
+	}
+	m = press(m, "c")
+	m.cursor = 3
+	m = press(m, "d")
+	if got := m.filtered[m.cursor].Src; got != "203.0.113.5" {
+		t.Errorf("entry gone: cursor on %s, want 203.0.113.5", got)
+	}
+
+	// A followed view still jumps to the newest entry.
+	m = press(m, "c")
+	m.follow = true
+	m.cursor = 0
+	m = press(m, "a")
+	if m.cursor != len(m.filtered)-1 {
+		t.Errorf("following: cursor %d of %d", m.cursor, len(m.filtered))
+	}
+}
+
+func TestFiltersTabFitsAndShowsLegend(t *testing.T) {
+	m := newTestModel(Options{})
+	m.width, m.height = 80, 24
+	m.tab = TabFilters
+
+	for _, legend := range []bool{false, true} {
+		out := ansi.Strip(m.View())
+		lines := strings.Split(out, "\n")
+		if len(lines) != m.height || !strings.Contains(lines[0], "1: Logs") {
+			t.Errorf("legend %v: frame is %d rows starting %q, want %d with the tab bar first", legend, len(lines), lines[0], m.height)
+		}
+		if got := strings.Contains(out, "CAT: source address category"); got != legend {
+			t.Errorf("legend shown = %v, want %v", got, legend)
+		}
+		m = press(m, "C")
+	}
+	if m.legendOpen {
+		t.Error("C did not close the legend again")
+	}
+}
@@ -0,0 +1,148 @@
+package model
+
+import (
+	"bytes"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
+)
+
+// sortKey selects the column the Logs tab is ordered by.
+type sortKey int
+
+const (
+	sortArrival sortKey = iota // order lines were read in (the default)
+	sortTime
+	sortSrc
+	sortDstPort
+)
+
+var sortLabels = map[sortKey]string{
+	sortArrival: "arrival",
+	sortTime:    "time",
+	sortSrc:     "src IP",
+	sortDstPort: "dst port",
+}
+
+// next returns the key after k in the cycle, wrapping back to arrival order.
+func (k sortKey) next() sortKey {
+	return (k + 1) % sortKey(len(sortLabels))
+}
+
+// sorted reports whether a non-default order is active. Auto-follow of new
+// entries only makes sense for ascending arrival order, so it is disabled
+// whenever this is true.
+func (m Model) sorted() bool {
+	return m.sortKey != sortArrival || m.sortDesc
+}
+
+// sortLess orders a before b under the current sort key and direction.
+func (m Model) sortLess(a, b parser.LogEntry) bool {
+	if m.sortDesc {
+		a, b = b, a
+	}
+	switch m.sortKey {
+	case sortTime:
+		return a.Timestamp.Before(b.Timestamp)
+	case sortSrc:
+		return compareIP(a.Src, b.Src) < 0
+	case sortDstPort:
+		return a.DstPort < b.DstPort
+	}
+	return false // arrival: keep the stable order of all
+}
+
+// sortFiltered orders m.filtered in place. The sort is stable so entries that
+// compare equal stay in arrival order.
+func (m *Model) sortFiltered() {
+	m.sortEntries(m.filtered)
+}
+
+// sortEntries orders entries in place the way the table shows them.
+func (m Model) sortEntries(entries []parser.LogEntry) {
+	if !m.sorted() {
+		return
+	}
+	if m.sortKey == sortArrival {
+		// Descending arrival: newest first.
+		for i, j := 0, len(entries)-1; i < j; i, j = i+1, j-1 {
+			entries[i], entries[j] = entries[j], entries[i]
+		}
+		return
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return m.sortLess(entries[i], entries[j])
+	})
+}
+
+// reselect returns the row of m.filtered holding sel, the entry that was
+// selected before the view was rebuilt. If sel no longer passes the filters
+// it returns the row that took its place: the first that comes after it in
+// the current order, or the last row when none does.
+func (m Model) reselect(sel parser.LogEntry) int {
+	for i, e := range m.filtered {
+		if e.Timestamp.Equal(sel.Timestamp) && e.Source == sel.Source && e.Raw == sel.Raw {
+			return i
+		}
+	}
+	after := func(e parser.LogEntry) bool { return m.sortLess(sel, e) }
+	if m.sortKey == sortArrival {
+		// Arrival order is timestamp order, give or take clock skew between
+		// files, so the timestamp places an entry that is gone.
+		after = func(e parser.LogEntry) bool {
+			if m.sortDesc {
+				return e.Timestamp.Before(sel.Timestamp)
+			}
+			return e.Timestamp.After(sel.Timestamp)
+		}
+	}
+	for i, e := range m.filtered {
+		if after(e) {
+			return i
+		}
+	}
+	return len(m.filtered) - 1
+}
+
+// insertSorted places e into m.filtered at its sorted position, after any
+// entries it compares equal to. The cursor keeps pointing at the same entry.
+func (m *Model) insertSorted(e parser.LogEntry) {
+	idx := 0 // descending arrival: newest first
+	if m.sortKey != sortArrival {
+		idx = sort.Search(len(m.filtered), func(i int) bool {
+			return m.sortLess(e, m.filtered[i])
+		})
+	}
+	m.filtered = append(m.filtered, parser.LogEntry{})
+	copy(m.filtered[idx+1:], m.filtered[idx:])
+	m.filtered[idx] = e
+	if idx <= m.cursor && len(m.filtered) > 1 {
+		m.cursor++
+	}
+}
+
+// compareIP orders addresses numerically, IPv4 before IPv6. Unparseable
+// strings sort last, lexicographically among themselves.
+func compareIP(a, b string) int {
+	ipa, ipb := net.ParseIP(a), net.ParseIP(b)
+	switch {
+	case ipa == nil && ipb == nil:
+		return strings.Compare(a, b)
+	case ipa == nil:
+		return 1
+	case ipb == nil:
+		return -1
+	}
+	a4, b4 := ipa.To4(), ipb.To4()
+	switch {
+	case a4 != nil && b4 != nil:
+		return bytes.Compare(a4, b4)
+	case a4 != nil:
+		return -1
+	case b4 != nil:
+		return 1
+	}
+	return bytes.Compare(ipa.To16(), ipb.To16())
+}
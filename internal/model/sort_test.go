@@ -0,0 +1,39 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
+)
+
+func TestCompareIP(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2.0.0.1", "10.0.0.1", -1},
+		{"10.0.0.1", "9.255.255.255", 1},
+		{"192.168.1.1", "192.168.1.1", 0},
+		{"255.255.255.255", "::1", -1},
+		{"2001:db8::2", "2001:db8::10", -1},
+		{"not-an-ip", "10.0.0.1", 1},
+	}
+	for _, tt := range tests {
+		if got := compareIP(tt.a, tt.b); got != tt.want {
+			t.Errorf("compareIP(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestInsertSortedKeepsOrder(t *testing.T) {
+	m := Model{sortKey: sortDstPort}
+	for _, p := range []int{443, 22, 8080, 22, 80} {
+		m.insertSorted(parser.LogEntry{DstPort: p})
+	}
+	want := []int{22, 22, 80, 443, 8080}
+	for i, e := range m.filtered {
+		if e.DstPort != want[i] {
+			t.Fatalf("filtered[%d].DstPort = %d, want %d", i, e.DstPort, want[i])
+		}
+	}
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// statusTTL is how long a status message stays in the footer when no key is
+// pressed to dismiss it sooner.
+const statusTTL = 4 * time.Second
+
+// statusTickMsg fires when the current status message may have expired.
+type statusTickMsg struct{}
+
+// setStatus shows s in the footer for statusTTL.
+func (m *Model) setStatus(s string) {
+	m.status = s
+	m.statusUntil = time.Now().Add(statusTTL)
+}
+
+// scheduleStatusTick returns a command that wakes the model when the status
+// message expires, or nil when there is none or a tick is already pending.
+func (m *Model) scheduleStatusTick() tea.Cmd {
+	if m.status == "" || m.statusTicking {
+		return nil
+	}
+	m.statusTicking = true
+	return tea.Tick(time.Until(m.statusUntil), func(time.Time) tea.Msg {
+		return statusTickMsg{}
+	})
+}
+
+// expireStatus clears the status message once it is due and schedules a
+// further tick if a newer message replaced it in the meantime.
+func (m *Model) expireStatus() tea.Cmd {
+	m.statusTicking = false
+	if !time.Now().Before(m.statusUntil) {
+		m.status = ""
+	}
+	return m.scheduleStatusTick()
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStatusExpires(t *testing.T) {
+	m := newTestModel(Options{})
+	m.setStatus("Copied 203.0.113.5 to clipboard")
+	if cmd := m.scheduleStatusTick(); cmd == nil || !m.statusTicking {
+		t.Fatal("no tick scheduled for a new status")
+	}
+	if cmd := m.scheduleStatusTick(); cmd != nil {
+		t.Error("second tick scheduled while one is pending")
+	}
+
+	// A tick before the deadline (a newer message arrived) keeps the message
+	// and schedules another.
+	if cmd := m.expireStatus(); cmd == nil || m.status == "" {
+		t.Errorf("early tick: status %q, rescheduled %v", m.status, cmd != nil)
+	}
+
+	m.statusUntil = time.Now().Add(-time.Second)
+	m.statusTicking = false
+	if cmd := m.expireStatus(); cmd != nil || m.status != "" {
+		t.Errorf("expired status %q still shown (rescheduled %v)", m.status, cmd != nil)
+	}
+}
@@ -0,0 +1,96 @@
+// Package nettools runs ping and traceroute against an address to see
+// whether a source is reachable, for the detail page.
+package nettools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Timeouts for each tool. Traceroute waits up to two seconds a hop, so it is
+// given long enough to reach a distant host.
+const (
+	PingTimeout  = 5 * time.Second
+	TraceTimeout = 30 * time.Second
+)
+
+// Result is the output of one run. Output holds whatever the tool printed,
+// stdout and stderr together, even when it failed or was cut short; Err says
+// why it did not finish cleanly, nil when it did.
+type Result struct {
+	Tool   string
+	IP     string
+	Output string
+	Err    error
+}
+
+// ErrNotInstalled is returned when the tool is not on the PATH,
+// ErrNeedsRoot when it could not open its raw socket, and ErrNotAddress when
+// the address given is not an IP address.
+var (
+	ErrNotInstalled = errors.New("not installed")
+	ErrNeedsRoot    = errors.New("needs root or CAP_NET_RAW")
+	ErrNotAddress   = errors.New("not an IP address")
+)
+
+// Ping sends a single echo request to ip with `ping -c1`.
+func Ping(ip string) Result {
+	return run("ping", PingTimeout, ip, "-c1", "-W2")
+}
+
+// Traceroute runs `traceroute -n` to ip, one probe a hop, at most 20 hops.
+func Traceroute(ip string) Result {
+	return run("traceroute", TraceTimeout, ip, "-n", "-q1", "-w2", "-m20")
+}
+
+// ValidAddress reports whether ip is a plain IP address the tools can be run
+// against.
+func ValidAddress(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	return err == nil && addr.Zone() == ""
+}
+
+// run runs tool with opts against ip, killing it after timeout. The address
+// comes from a log line, so it must parse as one; it is passed in canonical
+// form after "--", where it cannot be taken for an option.
+func run(tool string, timeout time.Duration, ip string, opts ...string) Result {
+	r := Result{Tool: tool, IP: ip}
+	if !ValidAddress(ip) {
+		r.Err = fmt.Errorf("%q is %w", ip, ErrNotAddress)
+		return r
+	}
+	if _, err := exec.LookPath(tool); err != nil {
+		r.Err = fmt.Errorf("%s is %w", tool, ErrNotInstalled)
+		return r
+	}
+	args := append(opts, "--", netip.MustParseAddr(ip).Unmap().String())
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, tool, args...).CombinedOutput()
+	r.Output = strings.TrimRight(string(out), "\n")
+	switch {
+	case ctx.Err() != nil:
+		r.Err = fmt.Errorf("timed out after %s", timeout)
+	case err != nil && needsRoot(r.Output):
+		r.Err = ErrNeedsRoot
+	case err != nil:
+		r.Err = err
+	}
+	return r
+}
+
+// needsRoot reports whether output is a tool's complaint about privileges.
+func needsRoot(output string) bool {
+	lower := strings.ToLower(output)
+	for _, s := range []string{"operation not permitted", "permission denied", "must be root", "are you root"} {
+		if strings.Contains(lower, s) {
+			return true
+		}
+	}
+	return false
+}
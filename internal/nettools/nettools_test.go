@@ -0,0 +1,80 @@
+package nettools
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeTool puts an executable shell script named tool on an otherwise empty
+// PATH for the rest of the test.
+func fakeTool(t *testing.T, tool, script string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, tool), []byte("#!/bin/sh\n"+script+"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir)
+}
+
+func TestPing(t *testing.T) {
+	fakeTool(t, "ping", `echo "args: $*"; echo "64 bytes from $4: icmp_seq=1 ttl=54 time=11.2 ms"`)
+	r := Ping("203.0.113.5")
+	if r.Err != nil || r.Tool != "ping" || r.IP != "203.0.113.5" {
+		t.Fatalf("Ping = %+v", r)
+	}
+	if !strings.Contains(r.Output, "args: -c1 -W2 -- 203.0.113.5\n") || !strings.Contains(r.Output, "64 bytes from 203.0.113.5") ||
+		strings.HasSuffix(r.Output, "\n") {
+		t.Errorf("Output = %q", r.Output)
+	}
+}
+
+func TestRunRefusesNonAddress(t *testing.T) {
+	fakeTool(t, "ping", `echo ran`)
+	for _, ip := range []string{"-fi0.001", "--help", "203.0.113.5;id", "fe80::1%-f", ""} {
+		r := Ping(ip)
+		if !errors.Is(r.Err, ErrNotAddress) || r.Output != "" {
+			t.Errorf("Ping(%q) = %+v, want ErrNotAddress and no run", ip, r)
+		}
+	}
+	fakeTool(t, "traceroute", `echo "$*"`)
+	if r := Traceroute("::ffff:203.0.113.5"); r.Err != nil || !strings.HasSuffix(r.Output, "-m20 -- 203.0.113.5") {
+		t.Errorf("Traceroute = %+v, want the canonical address after --", r)
+	}
+}
+
+func TestRunFailures(t *testing.T) {
+	t.Run("not installed", func(t *testing.T) {
+		t.Setenv("PATH", t.TempDir())
+		if r := Traceroute("203.0.113.5"); !errors.Is(r.Err, ErrNotInstalled) || r.Err.Error() != "traceroute is not installed" {
+			t.Errorf("Err = %v", r.Err)
+		}
+	})
+	t.Run("needs root", func(t *testing.T) {
+		fakeTool(t, "ping", `echo "ping: socket: Operation not permitted" >&2; exit 2`)
+		if r := Ping("203.0.113.5"); !errors.Is(r.Err, ErrNeedsRoot) || !strings.Contains(r.Output, "not permitted") {
+			t.Errorf("Ping = %+v", r)
+		}
+	})
+	t.Run("no reply", func(t *testing.T) {
+		fakeTool(t, "ping", `echo "1 packets transmitted, 0 received, 100% packet loss"; exit 1`)
+		if r := Ping("203.0.113.5"); r.Err == nil || !strings.Contains(r.Output, "100% packet loss") {
+			t.Errorf("Ping = %+v", r)
+		}
+	})
+	t.Run("timeout keeps partial output", func(t *testing.T) {
+		sleep, err := exec.LookPath("sleep")
+		if err != nil {
+			t.Skip("no sleep binary")
+		}
+		fakeTool(t, "traceroute", `echo " 1  192.0.2.1  0.4 ms"; exec `+sleep+` 5`)
+		r := run("traceroute", 300*time.Millisecond, "203.0.113.5")
+		if r.Err == nil || !strings.Contains(r.Err.Error(), "timed out") || !strings.Contains(r.Output, "192.0.2.1") {
+			t.Errorf("run = %+v", r)
+		}
+	})
+}
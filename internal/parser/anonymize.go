@@ -0,0 +1,47 @@
+package parser
+
+import (
+	"fmt"
+	"net/netip"
+	"regexp"
+)
+
+// AnonymizeIP masks the host part of ip for sharing screenshots and exports:
+// the last two octets of an IPv4 address ("203.0.x.x") and the lower 64 bits
+// of an IPv6 one ("2001:db8:0:1:x:x:x:x"). Anything that is not an address
+// is returned unchanged, so masking twice is harmless.
+func AnonymizeIP(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ip
+	}
+	addr = addr.Unmap()
+	if addr.Is4() {
+		b := addr.As4()
+		return fmt.Sprintf("%d.%d.x.x", b[0], b[1])
+	}
+	b := addr.As16()
+	return fmt.Sprintf("%x:%x:%x:%x:x:x:x:x",
+		uint16(b[0])<<8|uint16(b[1]), uint16(b[2])<<8|uint16(b[3]),
+		uint16(b[4])<<8|uint16(b[5]), uint16(b[6])<<8|uint16(b[7]))
+}
+
+// addrTokenRe matches runs of characters that could make up an IPv4 or IPv6
+// address; AnonymizeIP leaves the ones that are not addresses (times, MACs,
+// hex words) as they are.
+var addrTokenRe = regexp.MustCompile(`[0-9A-Fa-f:.]{7,}`)
+
+// AnonymizeText masks every IPv4 and IPv6 address in s with AnonymizeIP.
+func AnonymizeText(s string) string {
+	return addrTokenRe.ReplaceAllStringFunc(s, AnonymizeIP)
+}
+
+// Anonymized returns a copy of e with Src and Dst masked by AnonymizeIP, and
+// every address in the raw line too, including those of a packet quoted in an
+// ICMP error. e itself is not changed.
+func (e LogEntry) Anonymized() LogEntry {
+	e.Src = AnonymizeIP(e.Src)
+	e.Dst = AnonymizeIP(e.Dst)
+	e.Raw = AnonymizeText(e.Raw)
+	return e
+}
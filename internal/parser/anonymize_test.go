@@ -0,0 +1,50 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAnonymizeIP(t *testing.T) {
+	tests := []struct{ ip, want string }{
+		{"203.0.113.5", "203.0.x.x"},
+		{"10.0.0.1", "10.0.x.x"},
+		{"::ffff:192.0.2.1", "192.0.x.x"},
+		{"2001:db8:0:1::20", "2001:db8:0:1:x:x:x:x"},
+		{"fe80:0000:0000:0000:0211:22ff:fe33:4455", "fe80:0:0:0:x:x:x:x"},
+		{"203.0.x.x", "203.0.x.x"}, // already masked
+		{"", ""},
+		{"not-an-ip", "not-an-ip"},
+	}
+	for _, tt := range tests {
+		if got := AnonymizeIP(tt.ip); got != tt.want {
+			t.Errorf("AnonymizeIP(%q) = %q, want %q", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestLogEntryAnonymized(t *testing.T) {
+	line := `Mar 15 08:30:02 fw kernel: [UFW BLOCK] IN=eth0 OUT= MAC=00:11:22:33:44:55:66:77:88:99:aa:bb:08:00 SRC=198.51.100.7 DST=192.168.1.1 LEN=112 TTL=54 ID=0 PROTO=ICMP TYPE=3 CODE=3 [SRC=192.168.1.1 DST=198.51.100.7 LEN=84 TTL=64 ID=1 PROTO=UDP SPT=53 DPT=40000 LEN=64 ]`
+	e, err := ParseLine(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	a := e.Anonymized()
+	if a.Src != "198.51.x.x" || a.Dst != "192.168.x.x" {
+		t.Errorf("Src/Dst = %s/%s", a.Src, a.Dst)
+	}
+	for _, leaked := range []string{"198.51.100.7", "192.168.1.1"} {
+		if strings.Contains(a.Raw, leaked) {
+			t.Errorf("raw line still contains %s: %s", leaked, a.Raw)
+		}
+	}
+	// Everything else in the raw line, the timestamp and MAC included, is kept.
+	for _, kept := range []string{"Mar 15 08:30:02", "MAC=00:11:22:33:44:55:66:77:88:99:aa:bb:08:00", "SRC=192.168.x.x DST=198.51.x.x"} {
+		if !strings.Contains(a.Raw, kept) {
+			t.Errorf("raw line lost %q: %s", kept, a.Raw)
+		}
+	}
+	if e.Src != "198.51.100.7" || e.Raw != line {
+		t.Error("Anonymized changed the original entry")
+	}
+}
@@ -0,0 +1,146 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format recognises one log line format: it returns the entry for a line in
+// that format, or false for any other line.
+type Format func(line string) (*LogEntry, bool)
+
+// errNoMatch is returned by a matcher for a line not in its format, as
+// opposed to one in its format with a bad field.
+var errNoMatch = errors.New("no match")
+
+// matcher is a Format that can also say why a line in its format was
+// rejected, such as an unreadable timestamp.
+type matcher struct {
+	name  string
+	parse func(line string) (*LogEntry, error)
+}
+
+// formats are tried in order by ParseLine; the built-in kernel format comes
+// first. See RegisterFormat.
+var formats = []matcher{{"kernel", parseKernelLine}}
+
+// RegisterFormat adds a line format for ParseLine to try after the built-in
+// one and any registered before it. name identifies it in errors. It is not
+// safe to call while lines are being parsed; register formats at startup.
+func RegisterFormat(name string, f Format) {
+	formats = append(formats, matcher{name, func(line string) (*LogEntry, error) {
+		if e, ok := f(line); ok {
+			return e, nil
+		}
+		return nil, errNoMatch
+	}})
+}
+
+// ParseLine parses a single firewall log line, trying each format in turn
+// and returning the first match. Raw is set to line whatever the format.
+// Returns nil and an error if no format matches; when a format recognised
+// the line but could not read a field, the error says which.
+func ParseLine(line string) (*LogEntry, error) {
+	var rejected error
+	for _, f := range formats {
+		e, err := f.parse(line)
+		switch {
+		case err == nil:
+			e.Raw = line
+			return e, nil
+		case rejected == nil && !errors.Is(err, errNoMatch):
+			rejected = fmt.Errorf("%s format: %w", f.name, err)
+		}
+	}
+	if rejected != nil {
+		return nil, rejected
+	}
+	return nil, errors.New("line does not match any known log format")
+}
+
+// customGroups are the named groups a --format regex may use, and the
+// LogEntry fields they fill in.
+var customGroups = []string{"ts", "prefix", "in", "out", "src", "dst", "proto", "spt", "dpt"}
+
+// UseCustomFormat installs a user-supplied regex, from --format, as the first
+// format ParseLine tries. Its named groups map onto LogEntry fields: src and
+// dst are required; ts, prefix, in, out, proto, spt and dpt are optional.
+// Without ts, lines are stamped with the time they are parsed. The usual
+// KEY=value extras such as TTL= and LEN= are still read from the whole line.
+func UseCustomFormat(expr string) error {
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return fmt.Errorf("invalid regex: %w", err)
+	}
+	names := re.SubexpNames()
+	for _, name := range names {
+		if name != "" && !slices.Contains(customGroups, name) {
+			return fmt.Errorf("unknown group (?P<%s>); use %s", name, strings.Join(customGroups, ", "))
+		}
+	}
+	for _, need := range []string{"src", "dst"} {
+		if !slices.Contains(names, need) {
+			return fmt.Errorf("regex has no (?P<%s>…) group; src and dst are required", need)
+		}
+	}
+	formats = slices.Insert(formats, 0, matcher{"custom", func(line string) (*LogEntry, error) {
+		return parseCustomLine(re, line)
+	}})
+	return nil
+}
+
+// parseCustomLine parses line with a regex checked by UseCustomFormat.
+func parseCustomLine(re *regexp.Regexp, line string) (*LogEntry, error) {
+	m := re.FindStringSubmatch(line)
+	if m == nil {
+		return nil, errNoMatch
+	}
+	group := func(name string) string {
+		if i := re.SubexpIndex(name); i >= 0 {
+			return m[i]
+		}
+		return ""
+	}
+	port := func(name string) (int, error) {
+		v := group(name)
+		if v == "" {
+			return 0, nil
+		}
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > 65535 {
+			return 0, fmt.Errorf("%s %q is not a port number", name, v)
+		}
+		return n, nil
+	}
+
+	e := &LogEntry{
+		Timestamp: time.Now(),
+		Prefix:    strings.TrimSpace(group("prefix")),
+		In:        group("in"),
+		Out:       group("out"),
+		Src:       group("src"),
+		Dst:       group("dst"),
+		Proto:     normalizeProto(strings.ToUpper(group("proto"))),
+	}
+	if ts := group("ts"); ts != "" {
+		t, err := parseTimestamp(ts)
+		if err != nil {
+			return nil, fmt.Errorf("parse timestamp %q: %w", ts, err)
+		}
+		e.Timestamp = t
+	}
+	var err error
+	if e.SrcPort, err = port("spt"); err != nil {
+		return nil, err
+	}
+	if e.DstPort, err = port("dpt"); err != nil {
+		return nil, err
+	}
+	parseExtras(line, e)
+	return e, nil
+}
@@ -0,0 +1,120 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// withFormats restores the registered formats when the test ends.
+func withFormats(t *testing.T) {
+	t.Helper()
+	saved := append([]matcher(nil), formats...)
+	t.Cleanup(func() { formats = saved })
+}
+
+func TestRegisterFormat(t *testing.T) {
+	withFormats(t)
+	line := `pf: block in on em0: 203.0.113.5.4711 > 192.0.2.1.22: tcp`
+	if _, err := ParseLine(line); err == nil {
+		t.Fatal("pf line parsed before its format was registered")
+	}
+
+	RegisterFormat("pf", func(line string) (*LogEntry, bool) {
+		if !strings.HasPrefix(line, "pf: ") {
+			return nil, false
+		}
+		return &LogEntry{Prefix: "DROP", Src: "203.0.113.5", Dst: "192.0.2.1", Proto: "TCP", DstPort: 22}, true
+	})
+	e, err := ParseLine(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Src != "203.0.113.5" || e.DstPort != 22 || e.Raw != line {
+		t.Errorf("pf entry = %+v", e)
+	}
+
+	// The built-in format still comes first.
+	kernel := sampleLines[0].line
+	if e, err := ParseLine(kernel); err != nil || e.Src != sampleLines[0].wantSrc {
+		t.Errorf("kernel line after registering: %+v, %v", e, err)
+	}
+}
+
+func TestParseLineReportsFieldErrors(t *testing.T) {
+	_, err := ParseLine(`Foo  2 10:01:33 myhost kernel: [DROP] IN=eth0 OUT= SRC=1.2.3.4 DST=10.0.0.1 PROTO=TCP`)
+	if err == nil || !strings.Contains(err.Error(), "kernel format: parse timestamp") {
+		t.Errorf("err = %v, want the timestamp named", err)
+	}
+	_, err = ParseLine("this is not a firewall log line")
+	if err == nil || !strings.Contains(err.Error(), "does not match any known log format") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestUseCustomFormat(t *testing.T) {
+	withFormats(t)
+	expr := `^(?P<ts>\S+) fw: (?P<prefix>\w+) (?P<proto>\w+) (?P<src>\S+):(?P<spt>\d+) -> (?P<dst>\S+):(?P<dpt>\d+)`
+	if err := UseCustomFormat(expr); err != nil {
+		t.Fatal(err)
+	}
+	line := `2026-02-22T00:00:28+01:00 fw: DROP tcp 203.0.113.5:4711 -> 192.0.2.1:22 TTL=51 LEN=60`
+	e, err := ParseLine(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Src != "203.0.113.5" || e.Dst != "192.0.2.1" || e.SrcPort != 4711 || e.DstPort != 22 ||
+		e.Proto != "TCP" || e.Prefix != "DROP" || e.TTL != 51 || e.Len != 60 || e.Raw != line {
+		t.Errorf("custom entry = %+v", e)
+	}
+	if want := time.Date(2026, 2, 21, 23, 0, 28, 0, time.UTC); !e.Timestamp.Equal(want) {
+		t.Errorf("timestamp = %v", e.Timestamp)
+	}
+
+	// Lines the regex does not match still reach the built-in parser.
+	kernel := sampleLines[0].line
+	if e, err := ParseLine(kernel); err != nil || e.Src != sampleLines[0].wantSrc {
+		t.Errorf("kernel line with --format set: %+v, %v", e, err)
+	}
+
+	_, err = ParseLine(`yesterday fw: DROP tcp 203.0.113.5:4711 -> 192.0.2.1:22`)
+	if err == nil || !strings.Contains(err.Error(), "custom format: parse timestamp") {
+		t.Errorf("err = %v, want the bad timestamp named", err)
+	}
+}
+
+func TestUseCustomFormatWithoutTimestamp(t *testing.T) {
+	withFormats(t)
+	if err := UseCustomFormat(`(?P<src>[\d.]+) => (?P<dst>[\d.]+)`); err != nil {
+		t.Fatal(err)
+	}
+	before := time.Now()
+	e, err := ParseLine("blocked 10.0.0.1 => 10.0.0.2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Src != "10.0.0.1" || e.Dst != "10.0.0.2" || e.Timestamp.Before(before) {
+		t.Errorf("entry = %+v", e)
+	}
+}
+
+func TestUseCustomFormatRejects(t *testing.T) {
+	for _, tc := range []struct{ expr, want string }{
+		{`(?P<src>\S+`, "invalid regex"},
+		{`(?P<src>\S+) (?P<to>\S+)`, "unknown group (?P<to>)"},
+		{`(?P<src>\S+) (\S+)`, "no (?P<dst>…) group"},
+		{`(\S+) (?P<dst>\S+)`, "no (?P<src>…) group"},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			withFormats(t)
+			n := len(formats)
+			err := UseCustomFormat(tc.expr)
+			if err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Errorf("err = %v, want %q", err, tc.want)
+			}
+			if len(formats) != n {
+				t.Error("a rejected format was installed")
+			}
+		})
+	}
+}
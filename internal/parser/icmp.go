@@ -0,0 +1,117 @@
+package parser
+
+import "fmt"
+
+// icmpTypes names ICMP (IPv4) message types.
+// Source: https://www.iana.org/assignments/icmp-parameters
+var icmpTypes = map[int]string{
+	0:  "Echo Reply",
+	3:  "Destination Unreachable",
+	4:  "Source Quench",
+	5:  "Redirect",
+	8:  "Echo Request",
+	9:  "Router Advertisement",
+	10: "Router Solicitation",
+	11: "Time Exceeded",
+	12: "Parameter Problem",
+	13: "Timestamp",
+	14: "Timestamp Reply",
+}
+
+// icmpCodes names the codes of ICMP types that define more than one.
+var icmpCodes = map[int]map[int]string{
+	3: {
+		0:  "Net Unreachable",
+		1:  "Host Unreachable",
+		2:  "Protocol Unreachable",
+		3:  "Port Unreachable",
+		4:  "Fragmentation Needed",
+		5:  "Source Route Failed",
+		6:  "Destination Network Unknown",
+		7:  "Destination Host Unknown",
+		9:  "Network Administratively Prohibited",
+		10: "Host Administratively Prohibited",
+		13: "Communication Administratively Prohibited",
+	},
+	5: {
+		0: "Network",
+		1: "Host",
+		2: "TOS and Network",
+		3: "TOS and Host",
+	},
+	11: {
+		0: "TTL Exceeded in Transit",
+		1: "Fragment Reassembly Time Exceeded",
+	},
+}
+
+// icmpv6Types names ICMPv6 message types.
+// Source: https://www.iana.org/assignments/icmpv6-parameters
+var icmpv6Types = map[int]string{
+	1:   "Destination Unreachable",
+	2:   "Packet Too Big",
+	3:   "Time Exceeded",
+	4:   "Parameter Problem",
+	128: "Echo Request",
+	129: "Echo Reply",
+	133: "Router Solicitation",
+	134: "Router Advertisement",
+	135: "Neighbor Solicitation",
+	136: "Neighbor Advertisement",
+	137: "Redirect",
+	143: "MLDv2 Report",
+}
+
+// icmpv6Codes names the codes of ICMPv6 types that define more than one.
+var icmpv6Codes = map[int]map[int]string{
+	1: {
+		0: "No Route",
+		1: "Administratively Prohibited",
+		3: "Address Unreachable",
+		4: "Port Unreachable",
+	},
+	3: {
+		0: "Hop Limit Exceeded",
+		1: "Fragment Reassembly Time Exceeded",
+	},
+}
+
+// ICMPDescription describes an ICMP (IPv4) type and code, e.g. "Echo Request"
+// or "Destination Unreachable / Port Unreachable". Unknown values fall back
+// to their numbers.
+func ICMPDescription(t, c int) string {
+	return describeICMP(icmpTypes, icmpCodes, t, c)
+}
+
+// ICMPv6Description is ICMPDescription for ICMPv6 type and code values.
+func ICMPv6Description(t, c int) string {
+	return describeICMP(icmpv6Types, icmpv6Codes, t, c)
+}
+
+// ICMPDescription returns the description for e in the right numbering for
+// its protocol, or "" when the line carried no TYPE=/CODE=.
+func (e LogEntry) ICMPDescription() string {
+	switch {
+	case !e.HasICMP:
+		return ""
+	case e.Proto == "ICMPv6":
+		return ICMPv6Description(e.ICMPType, e.ICMPCode)
+	default:
+		return ICMPDescription(e.ICMPType, e.ICMPCode)
+	}
+}
+
+func describeICMP(types map[int]string, codes map[int]map[int]string, t, c int) string {
+	name, ok := types[t]
+	if !ok {
+		return fmt.Sprintf("Type %d / Code %d", t, c)
+	}
+	byCode, multi := codes[t]
+	if !multi {
+		return name
+	}
+	if code, ok := byCode[c]; ok {
+		return name + " / " + code
+	}
+	return fmt.Sprintf("%s / Code %d", name, c)
+}
@@ -15,14 +15,51 @@ type LogEntry struct {
 	Prefix    string // e.g. "UFW BLOCK", "DROP", custom chain prefix
 	In        string // IN interface
 	Out       string // OUT interface
+	MAC       string // raw MAC= value: dst MAC, src MAC, ethertype
 	Src       string // source IP
 	Dst       string // destination IP
-	Proto     string // TCP / UDP / ICMP
+	Proto     string // TCP / UDP / ICMP / ICMPv6
 	SrcPort   int    // SPT
 	DstPort   int    // DPT
 	TTL       int
 	Len       int
-	Raw       string // original line (for detail view)
+	TCPFlags  []string // e.g. ["SYN", "ACK"]; empty for non-TCP lines
+	// Window is the TCP WINDOW= value. A zero window is meaningful (a
+	// zero-window probe), so HasWindow tells whether it was logged at all.
+	Window    int
+	HasWindow bool
+	// ID is the IPv4 identification field, which the fragments of one
+	// packet share; 0 when not logged (IPv6 lines have none).
+	ID int
+	// DontFragment and MoreFragments are the IPv4 DF and MF header flags;
+	// FragOffset is the logged FRAG: offset (0 for unfragmented packets or
+	// the first fragment).
+	DontFragment  bool
+	MoreFragments bool
+	FragOffset    int
+	// ICMPType and ICMPCode are the TYPE= and CODE= of ICMP/ICMPv6 lines.
+	// Zero is a valid type (echo reply), so HasICMP tells whether they were
+	// logged at all.
+	ICMPType int
+	ICMPCode int
+	HasICMP  bool
+	// UID and GID identify the local user and group whose process sent the
+	// packet, logged by --log-uid on OUTPUT rules. They are nil when not
+	// logged, since 0 (root) is a real owner.
+	UID *int
+	GID *int
+	// Mark is the packet's fwmark as logged by MARK=, e.g. "0x64", kept in
+	// its logged hex form; "" when the packet was not marked.
+	Mark string
+	Raw  string // original line (for detail view)
+	// Source is the log file the line was read from; set by the caller, not
+	// by ParseLine.
+	Source string
+	// Repeats counts the earlier identical entries the Logs tab's collapse
+	// mode merged into this one, and First is the oldest of their
+	// timestamps. Both are zero on entries from ParseLine.
+	Repeats int
+	First   time.Time
 }
 
 // Action returns the action derived from the prefix (DROP, ACCEPT, REJECT, etc.)
@@ -43,6 +80,57 @@ func (e LogEntry) Action() string {
 	}
 }
 
+// Direction classifies the packet's path from IN= and OUT=: "in" when only
+// IN is set (addressed to this host), "out" when only OUT is (sent by it),
+// "fwd" when both are (routed through it), or "" when neither was logged.
+func (e LogEntry) Direction() string {
+	switch {
+	case e.In != "" && e.Out != "":
+		return "fwd"
+	case e.In != "":
+		return "in"
+	case e.Out != "":
+		return "out"
+	}
+	return ""
+}
+
+// DstMAC returns the destination hardware address from the 14-byte MAC=
+// field, or "" if the field is absent or not in that form.
+func (e LogEntry) DstMAC() string {
+	octets := strings.Split(e.MAC, ":")
+	if len(octets) != 14 {
+		return ""
+	}
+	return strings.Join(octets[:6], ":")
+}
+
+// SrcMAC returns the source hardware address from the 14-byte MAC= field,
+// or "" if the field is absent or not in that form.
+func (e LogEntry) SrcMAC() string {
+	octets := strings.Split(e.MAC, ":")
+	if len(octets) != 14 {
+		return ""
+	}
+	return strings.Join(octets[6:12], ":")
+}
+
+// FragmentLabel summarises the fragmentation flags, e.g. "DF" or
+// "MF, offset 185", or "" when none are set.
+func (e LogEntry) FragmentLabel() string {
+	var parts []string
+	if e.DontFragment {
+		parts = append(parts, "DF")
+	}
+	if e.MoreFragments {
+		parts = append(parts, "MF")
+	}
+	if e.FragOffset != 0 {
+		parts = append(parts, fmt.Sprintf("offset %d", e.FragOffset))
+	}
+	return strings.Join(parts, ", ")
+}
+
 // String returns a human-readable summary of all fields.
 func (e LogEntry) String() string {
 	var sb strings.Builder
@@ -52,6 +140,9 @@ func (e LogEntry) String() string {
 	fmt.Fprintf(&sb, "Action    : %s\n", e.Action())
 	fmt.Fprintf(&sb, "In        : %s\n", e.In)
 	fmt.Fprintf(&sb, "Out       : %s\n", e.Out)
+	if e.MAC != "" {
+		fmt.Fprintf(&sb, "MAC       : %s\n", e.MAC)
+	}
 	fmt.Fprintf(&sb, "Src       : %s\n", e.Src)
 	fmt.Fprintf(&sb, "Dst       : %s\n", e.Dst)
 	fmt.Fprintf(&sb, "Proto     : %s\n", e.Proto)
@@ -67,6 +158,30 @@ func (e LogEntry) String() string {
 	if e.Len != 0 {
 		fmt.Fprintf(&sb, "Len       : %d\n", e.Len)
 	}
+	if len(e.TCPFlags) > 0 {
+		fmt.Fprintf(&sb, "Flags     : %s\n", strings.Join(e.TCPFlags, " "))
+	}
+	if e.HasWindow {
+		fmt.Fprintf(&sb, "Window    : %d\n", e.Window)
+	}
+	if e.ID != 0 {
+		fmt.Fprintf(&sb, "ID        : %d\n", e.ID)
+	}
+	if frag := e.FragmentLabel(); frag != "" {
+		fmt.Fprintf(&sb, "Fragment  : %s\n", frag)
+	}
+	if e.HasICMP {
+		fmt.Fprintf(&sb, "ICMP      : type %d code %d\n", e.ICMPType, e.ICMPCode)
+	}
+	if e.UID != nil {
+		fmt.Fprintf(&sb, "UID       : %d\n", *e.UID)
+	}
+	if e.GID != nil {
+		fmt.Fprintf(&sb, "GID       : %d\n", *e.GID)
+	}
+	if e.Mark != "" {
+		fmt.Fprintf(&sb, "Mark      : %s\n", e.Mark)
+	}
 	fmt.Fprintf(&sb, "\nRaw:\n%s\n", e.Raw)
 	return sb.String()
 }
@@ -75,8 +190,11 @@ func (e LogEntry) String() string {
 // Groups: (1) timestamp  (2) hostname  (3) prefix  (4) IN  (5) OUT
 //
 //	(6) SRC  (7) DST  (8) PROTO  (9) SPT?  (10) DPT?
+//
+// A syslog timestamp may carry a year before the time and a zone
+// abbreviation after it; see syslogLayouts.
 var logLineRe = regexp.MustCompile(
-	`^(\d{4}-\d{2}-\d{2}T\S+|\w{3}\s+\d+\s+[\d:]+)\s+(\S+)\s+kernel:.*?\[?([^\]]*?)\]?\s+IN=(\S*)\s+OUT=(\S*)` +
+	`^(\d{4}-\d{2}-\d{2}T\S+|\w{3}\s+\d+(?:\s+\d{4})?\s+[\d:]+(?:\s+[A-Z]{3,5})?)\s+(\S+)\s+kernel:.*?\[?([^\]]*?)\]?\s+IN=(\S*)\s+OUT=(\S*)` +
 		`.*?SRC=(\S+)\s+DST=(\S+).*?PROTO=(\S+)` +
 		`(?:.*?SPT=(\d+))?(?:.*?DPT=(\d+))?`,
 )
@@ -91,14 +209,30 @@ var (
 	ttlRe      = regexp.MustCompile(`TTL=(\d+)`)
 	hoplimitRe = regexp.MustCompile(`HOPLIMIT=(\d+)`)
 	lenRe      = regexp.MustCompile(`\bLEN=(\d+)`)
+	macRe      = regexp.MustCompile(`\bMAC=([0-9A-Fa-f:]+)`)
+	windowRe   = regexp.MustCompile(`PROTO=TCP\b.*?\bWINDOW=(\d+)`)
+	uidRe      = regexp.MustCompile(`\bUID=(\d+)`)
+	gidRe      = regexp.MustCompile(`\bGID=(\d+)`)
+	markRe     = regexp.MustCompile(`\bMARK=(0[xX][0-9A-Fa-f]+)`)
 )
 
-// ParseLine parses a single iptables log line.
-// Returns nil and an error if the line does not match the expected format.
-func ParseLine(line string) (*LogEntry, error) {
+// tcpFlagRe matches the bare TCP flag words iptables logs between RES= and
+// URGP=. Flags are only looked for after PROTO=TCP so words in the prefix
+// can never be mistaken for flags.
+var tcpFlagRe = regexp.MustCompile(`\b(CWR|ECE|URG|ACK|PSH|RST|SYN|FIN)\b`)
+
+// icmpRe extracts TYPE= and CODE= following PROTO=. Error messages such as
+// destination unreachable carry a bracketed copy of the offending packet
+// afterwards; only the first pair, the outer header, is used.
+var icmpRe = regexp.MustCompile(`PROTO=\S+\s+TYPE=(\d+)\s+CODE=(\d+)`)
+
+// parseKernelLine parses the kernel log line format shared by iptables,
+// UFW, nftables and firewalld, as written by syslog or journalctl. It is the
+// first format ParseLine tries.
+func parseKernelLine(line string) (*LogEntry, error) {
 	m := logLineRe.FindStringSubmatch(line)
 	if m == nil {
-		return nil, fmt.Errorf("line does not match iptables log format")
+		return nil, errNoMatch
 	}
 
 	ts, err := parseTimestamp(m[1])
@@ -131,6 +265,14 @@ func ParseLine(line string) (*LogEntry, error) {
 	if m[10] != "" {
 		entry.DstPort, _ = strconv.Atoi(m[10])
 	}
+	parseExtras(line, entry)
+	return entry, nil
+}
+
+// parseExtras fills in the optional KEY=value fields found anywhere in the
+// line: TTL (or HOPLIMIT), LEN, MAC, TCP flags and window, the IPv4 ID and
+// fragment flags and the ICMP type and code. e.Proto must already be set.
+func parseExtras(line string, entry *LogEntry) {
 	if ttl := ttlRe.FindStringSubmatch(line); ttl != nil {
 		entry.TTL, _ = strconv.Atoi(ttl[1])
 	} else if hl := hoplimitRe.FindStringSubmatch(line); hl != nil {
@@ -139,8 +281,82 @@ func ParseLine(line string) (*LogEntry, error) {
 	if ln := lenRe.FindStringSubmatch(line); ln != nil {
 		entry.Len, _ = strconv.Atoi(ln[1])
 	}
+	if mac := macRe.FindStringSubmatch(line); mac != nil {
+		entry.MAC = strings.ToLower(mac[1])
+	}
+	if entry.Proto == "TCP" {
+		entry.TCPFlags = parseTCPFlags(line)
+		if w := windowRe.FindStringSubmatch(line); w != nil {
+			entry.Window, _ = strconv.Atoi(w[1])
+			entry.HasWindow = true
+		}
+	}
+	parseIPFlags(line, entry)
+	if im := icmpRe.FindStringSubmatch(line); im != nil && (entry.Proto == "ICMP" || entry.Proto == "ICMPv6") {
+		entry.ICMPType, _ = strconv.Atoi(im[1])
+		entry.ICMPCode, _ = strconv.Atoi(im[2])
+		entry.HasICMP = true
+	}
+	entry.UID = parseOwnerID(uidRe, line)
+	entry.GID = parseOwnerID(gidRe, line)
+	if mark := markRe.FindStringSubmatch(line); mark != nil {
+		entry.Mark = mark[1]
+	}
+}
 
-	return entry, nil
+// parseOwnerID returns the UID= or GID= value re matches in line, or nil.
+func parseOwnerID(re *regexp.Regexp, line string) *int {
+	m := re.FindStringSubmatch(line)
+	if m == nil {
+		return nil
+	}
+	id, err := strconv.Atoi(m[1])
+	if err != nil {
+		return nil
+	}
+	return &id
+}
+
+// parseTCPFlags returns the TCP flag words that appear after PROTO= and
+// before URGP= (or the end of the line), in logged order.
+func parseTCPFlags(line string) []string {
+	i := strings.Index(line, "PROTO=")
+	if i < 0 {
+		return nil
+	}
+	rest := line[i:]
+	if j := strings.Index(rest, "URGP="); j >= 0 {
+		rest = rest[:j]
+	}
+	return tcpFlagRe.FindAllString(rest, -1)
+}
+
+// parseIPFlags sets the ID, the DF/MF flags and FRAG: offset, which iptables
+// logs from ID= up to PROTO= in the IPv4 header section. Limiting the search
+// to that span keeps words from the prefix or an embedded packet out, as well
+// as the ICMP echo ID= logged after PROTO=.
+func parseIPFlags(line string, e *LogEntry) {
+	end := strings.Index(line, "PROTO=")
+	if end < 0 {
+		return
+	}
+	head := line[:end]
+	start := strings.LastIndex(head, " ID=")
+	if start < 0 {
+		return
+	}
+	for _, f := range strings.Fields(head[start:]) {
+		switch {
+		case strings.HasPrefix(f, "ID="):
+			e.ID, _ = strconv.Atoi(strings.TrimPrefix(f, "ID="))
+		case f == "DF":
+			e.DontFragment = true
+		case f == "MF":
+			e.MoreFragments = true
+		case strings.HasPrefix(f, "FRAG:"):
+			e.FragOffset, _ = strconv.Atoi(strings.TrimPrefix(f, "FRAG:"))
+		}
+	}
 }
 
 // protoNames maps IP protocol numbers (as logged by iptables) to their names.
@@ -283,34 +499,93 @@ var protoNames = map[string]string{
 	"143": "Ethernet",
 }
 
+// protoAliases maps alternative spellings (upper-cased) onto the name used
+// throughout the UI. ip6tables logs "PROTO=ICMPv6" while the numeric form 58
+// resolves to the IANA keyword "IPv6-ICMP"; both become "ICMPv6".
+var protoAliases = map[string]string{
+	"ICMPV6":    "ICMPv6",
+	"IPV6-ICMP": "ICMPv6",
+}
+
 // normalizeProto converts numeric protocol values to their canonical names.
 func normalizeProto(p string) string {
-	up := strings.ToUpper(p)
-	if name, ok := protoNames[up]; ok {
-		return name
+	name := strings.ToUpper(p)
+	if n, ok := protoNames[name]; ok {
+		name = n
 	}
-	return up
+	if alias, ok := protoAliases[strings.ToUpper(name)]; ok {
+		return alias
+	}
+	return name
+}
+
+// shortISO is the timestamp layout of journalctl -o short-iso, whose zone
+// offset has no colon (e.g. "2026-02-22T00:00:28+0100").
+const shortISO = "2006-01-02T15:04:05.999999999-0700"
+
+// syslogLayouts are tried in order on a syslog-style timestamp once runs of
+// spaces are collapsed. Some rsyslog templates add the year, a zone
+// abbreviation, or both; an abbreviation the local zone does not know is
+// taken as UTC, as time.Parse does.
+var syslogLayouts = []string{
+	"Jan 2 2006 15:04:05 MST",
+	"Jan 2 2006 15:04:05",
+	"Jan 2 15:04:05 MST",
+	"Jan 2 15:04:05",
 }
 
+// yearRolloverSkew is how far in the future a year-less syslog timestamp may
+// fall, given the current year, before it is taken to be from last year.
+// The slack absorbs clock skew between the logging host and this one.
+const yearRolloverSkew = 24 * time.Hour
+
 // parseTimestamp parses either an ISO 8601 timestamp (ufw.log style,
-// e.g. "2026-02-22T00:00:28.257338+01:00") or a syslog-style timestamp
-// (e.g. "Jan  2 15:04:05"). For syslog format the year is assumed to be
-// the current year.
+// e.g. "2026-02-22T00:00:28.257338+01:00", or journalctl's short-iso) or a
+// syslog-style timestamp (e.g. "Jan  2 15:04:05", "Jan 02 2024 15:04:05",
+// "Jan  2 15:04:05 CET"). A syslog timestamp without a year gets the current
+// one, or last year's when that would put it more than a day in the future
+// (December lines read in January).
 func parseTimestamp(s string) (time.Time, error) {
+	return parseTimestampAt(s, time.Now())
+}
+
+// parseTimestampAt is parseTimestamp with the current time given.
+func parseTimestampAt(s string, now time.Time) (time.Time, error) {
 	if len(s) > 0 && s[0] >= '0' && s[0] <= '9' {
 		t, err := time.Parse(time.RFC3339Nano, s)
 		if err != nil {
-			return time.Time{}, err
+			var isoErr error
+			if t, isoErr = time.Parse(shortISO, s); isoErr != nil {
+				return time.Time{}, err
+			}
 		}
 		return t.In(time.Local), nil
 	}
 	// Syslog format: normalise multiple spaces then parse.
 	s = strings.Join(strings.Fields(s), " ")
-	t, err := time.Parse("Jan 2 15:04:05", s)
+	var t time.Time
+	var layout string
+	var err error
+	for _, layout = range syslogLayouts {
+		if t, err = time.Parse(layout, s); err == nil {
+			break
+		}
+	}
 	if err != nil {
 		return time.Time{}, err
 	}
-	now := time.Now()
-	return time.Date(now.Year(), t.Month(), t.Day(),
-		t.Hour(), t.Minute(), t.Second(), 0, time.Local), nil
+	loc := time.Local
+	if strings.HasSuffix(layout, "MST") {
+		loc = t.Location()
+	}
+	hasYear := strings.Contains(layout, "2006")
+	year := t.Year()
+	if !hasYear {
+		year = now.Year()
+	}
+	t = time.Date(year, t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc)
+	if !hasYear && t.After(now.Add(yearRolloverSkew)) {
+		t = t.AddDate(-1, 0, 0)
+	}
+	return t.In(time.Local), nil
 }
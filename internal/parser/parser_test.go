@@ -3,6 +3,7 @@ package parser
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 var sampleLines = []struct {
@@ -59,6 +60,33 @@ var sampleLines = []struct {
 		wantDPT:    5353,
 		wantAction: "REJECT",
 	},
+	{
+		name: "docker-user chain forwarded to a container",
+		line: `Jan  5 11:00:00 dockerhost kernel: [DOCKER-USER DROP] IN=eth0 OUT=docker0 MAC=02:42:ac:11:00:02:52:54:00:12:34:56:08:00 SRC=198.51.100.7 DST=172.17.0.2 LEN=60 TOS=0x00 PREC=0x00 TTL=63 ID=4711 DF PROTO=TCP SPT=40000 DPT=8080 WINDOW=64240 RES=0x00 SYN URGP=0`,
+		wantSrc:    "198.51.100.7",
+		wantDst:    "172.17.0.2",
+		wantProto:  "TCP",
+		wantDPT:    8080,
+		wantAction: "DROP",
+	},
+	{
+		name: "journalctl short-iso",
+		line: `2026-02-22T00:00:28+0100 fw kernel: [UFW BLOCK] IN=eth0 OUT= SRC=192.0.2.50 DST=10.0.0.1 LEN=40 TTL=244 PROTO=TCP SPT=54321 DPT=3389`,
+		wantSrc:    "192.0.2.50",
+		wantDst:    "10.0.0.1",
+		wantProto:  "TCP",
+		wantDPT:    3389,
+		wantAction: "DROP",
+	},
+	{
+		name: "iptables custom prefix",
+		line: `Apr  1 09:00:00 gw kernel: [RATE-LIMIT] IN=eth0 OUT= SRC=203.0.113.77 DST=10.0.0.1 LEN=52 TTL=55 PROTO=UDP SPT=5000 DPT=161`,
+		wantSrc:    "203.0.113.77",
+		wantDst:    "10.0.0.1",
+		wantProto:  "UDP",
+		wantDPT:    161,
+		wantAction: "RATE-LIMIT",
+	},
 }
 
 func TestParseLineSampleLines(t *testing.T) {
@@ -110,3 +138,409 @@ func TestLogEntryString(t *testing.T) {
 		t.Errorf("String() missing DstPort; got:\n%s", s)
 	}
 }
+
+func TestParseLineTCPFlags(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want string
+	}{
+		{
+			name: "syn only",
+			line: `Jan  2 10:01:33 myhost kernel: [UFW BLOCK] IN=eth0 OUT= SRC=1.2.3.4 DST=10.0.0.1 LEN=60 TTL=50 PROTO=TCP SPT=12345 DPT=22 WINDOW=65535 RES=0x00 SYN URGP=0`,
+			want: "SYN",
+		},
+		{
+			name: "syn ack",
+			line: `Jan  2 10:01:34 myhost kernel: [UFW BLOCK] IN=eth0 OUT= SRC=1.2.3.4 DST=10.0.0.1 LEN=60 TTL=50 PROTO=TCP SPT=443 DPT=51000 WINDOW=28960 RES=0x00 ACK SYN URGP=0`,
+			want: "ACK SYN",
+		},
+		{
+			name: "udp has no flags",
+			line: sampleLines[1].line,
+			want: "",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e, err := ParseLine(tc.line)
+			if err != nil {
+				t.Fatalf("ParseLine returned error: %v", err)
+			}
+			if got := strings.Join(e.TCPFlags, " "); got != tc.want {
+				t.Errorf("TCPFlags: got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseLineIPv6(t *testing.T) {
+	tests := []struct {
+		name      string
+		line      string
+		wantSrc   string
+		wantDst   string
+		wantProto string
+		wantTTL   int
+	}{
+		{
+			name:      "ufw block ip6tables tcp",
+			line:      `Feb 21 10:15:42 myhost kernel: [UFW BLOCK] IN=eth0 OUT= MAC=00:11:22:33:44:55:66:77:88:99:aa:bb:86:dd SRC=2001:db8::1 DST=2001:db8:0:1::20 LEN=80 TC=0 HOPLIMIT=57 FLOWLBL=553242 PROTO=TCP SPT=50632 DPT=22 WINDOW=64800 RES=0x00 SYN URGP=0`,
+			wantSrc:   "2001:db8::1",
+			wantDst:   "2001:db8:0:1::20",
+			wantProto: "TCP",
+			wantTTL:   57,
+		},
+		{
+			name:      "ufw block ip6tables icmpv6",
+			line:      `2026-02-21T10:15:43.123456+01:00 myhost kernel: [UFW BLOCK] IN=eth0 OUT= MAC=33:33:00:00:00:01:00:11:22:33:44:55:86:dd SRC=fe80:0000:0000:0000:0211:22ff:fe33:4455 DST=ff02:0000:0000:0000:0000:0000:0000:0001 LEN=72 TC=0 HOPLIMIT=255 FLOWLBL=0 PROTO=ICMPv6 TYPE=134 CODE=0`,
+			wantSrc:   "fe80:0000:0000:0000:0211:22ff:fe33:4455",
+			wantDst:   "ff02:0000:0000:0000:0000:0000:0000:0001",
+			wantProto: "ICMPv6",
+			wantTTL:   255,
+		},
+		{
+			name:      "numeric icmpv6 protocol",
+			line:      `Feb 21 10:15:44 myhost kernel: [DROP] IN=eth0 OUT= SRC=2001:db8::7 DST=2001:db8::8 LEN=64 TC=0 HOPLIMIT=64 FLOWLBL=0 PROTO=58`,
+			wantSrc:   "2001:db8::7",
+			wantDst:   "2001:db8::8",
+			wantProto: "ICMPv6",
+			wantTTL:   64,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e, err := ParseLine(tc.line)
+			if err != nil {
+				t.Fatalf("ParseLine returned error: %v", err)
+			}
+			if e.Src != tc.wantSrc {
+				t.Errorf("Src: got %q, want %q", e.Src, tc.wantSrc)
+			}
+			if e.Dst != tc.wantDst {
+				t.Errorf("Dst: got %q, want %q", e.Dst, tc.wantDst)
+			}
+			if e.Proto != tc.wantProto {
+				t.Errorf("Proto: got %q, want %q", e.Proto, tc.wantProto)
+			}
+			if e.TTL != tc.wantTTL {
+				t.Errorf("TTL: got %d, want %d", e.TTL, tc.wantTTL)
+			}
+			if e.Action() != "DROP" {
+				t.Errorf("Action: got %q, want DROP", e.Action())
+			}
+		})
+	}
+}
+
+func TestLogEntryMAC(t *testing.T) {
+	e, err := ParseLine(sampleLines[3].line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := e.DstMAC(), "ff:ff:ff:ff:ff:ff"; got != want {
+		t.Errorf("DstMAC: got %q, want %q", got, want)
+	}
+	if got, want := e.SrcMAC(), "98:06:3c:a2:4a:63"; got != want {
+		t.Errorf("SrcMAC: got %q, want %q", got, want)
+	}
+
+	// A truncated MAC field is kept verbatim but not split.
+	e, err = ParseLine(sampleLines[0].line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.MAC != "aa:bb:cc" {
+		t.Errorf("MAC: got %q, want %q", e.MAC, "aa:bb:cc")
+	}
+	if e.SrcMAC() != "" || e.DstMAC() != "" {
+		t.Errorf("expected empty Src/Dst MAC for short field, got %q / %q", e.SrcMAC(), e.DstMAC())
+	}
+}
+
+func TestParseICMPTypeCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     string
+		wantType int
+		wantCode int
+		wantDesc string
+	}{
+		{
+			name:     "echo request",
+			line:     `Mar 15 08:30:00 fw kernel: [UFW BLOCK] IN=eth0 OUT= SRC=198.51.100.7 DST=192.168.1.1 LEN=84 TTL=54 ID=0 DF PROTO=ICMP TYPE=8 CODE=0 ID=4321 SEQ=1`,
+			wantType: 8,
+			wantCode: 0,
+			wantDesc: "Echo Request",
+		},
+		{
+			name:     "echo reply",
+			line:     `Mar 15 08:30:01 fw kernel: [UFW ALLOW] IN=eth0 OUT= SRC=8.8.8.8 DST=192.168.1.5 LEN=84 TTL=117 ID=0 PROTO=ICMP TYPE=0 CODE=0 ID=4321 SEQ=1`,
+			wantType: 0,
+			wantCode: 0,
+			wantDesc: "Echo Reply",
+		},
+		{
+			name:     "destination unreachable with embedded packet",
+			line:     `Mar 15 08:30:02 fw kernel: [DROP] IN=eth0 OUT= SRC=203.0.113.9 DST=192.168.1.5 LEN=96 TTL=60 ID=1 PROTO=ICMP TYPE=3 CODE=3 [SRC=192.168.1.5 DST=203.0.113.9 LEN=68 TTL=64 ID=2 PROTO=UDP SPT=5353 DPT=33434 LEN=48 ]`,
+			wantType: 3,
+			wantCode: 3,
+			wantDesc: "Destination Unreachable / Port Unreachable",
+		},
+		{
+			name:     "icmpv6 echo request",
+			line:     `Feb 21 10:15:44 myhost kernel: [DROP] IN=eth0 OUT= SRC=2001:db8::7 DST=2001:db8::8 LEN=64 TC=0 HOPLIMIT=64 FLOWLBL=0 PROTO=ICMPv6 TYPE=128 CODE=0 ID=1 SEQ=1`,
+			wantType: 128,
+			wantCode: 0,
+			wantDesc: "Echo Request",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e, err := ParseLine(tc.line)
+			if err != nil {
+				t.Fatalf("ParseLine returned error: %v", err)
+			}
+			if !e.HasICMP {
+				t.Fatal("HasICMP: got false, want true")
+			}
+			if e.ICMPType != tc.wantType || e.ICMPCode != tc.wantCode {
+				t.Errorf("type/code: got %d/%d, want %d/%d", e.ICMPType, e.ICMPCode, tc.wantType, tc.wantCode)
+			}
+			if got := e.ICMPDescription(); got != tc.wantDesc {
+				t.Errorf("ICMPDescription: got %q, want %q", got, tc.wantDesc)
+			}
+		})
+	}
+
+	// Lines without TYPE=/CODE= leave the fields unset.
+	e, err := ParseLine(`Mar 15 08:30:00 fw kernel: [ACCEPT] IN=lo OUT= SRC=127.0.0.1 DST=127.0.0.1 LEN=84 TTL=64 PROTO=ICMP`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.HasICMP {
+		t.Error("HasICMP: got true for a line without TYPE=")
+	}
+}
+
+func TestICMPDescriptionUnknown(t *testing.T) {
+	if got, want := ICMPDescription(42, 7), "Type 42 / Code 7"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if got, want := ICMPDescription(3, 99), "Destination Unreachable / Code 99"; got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestParseFragmentFlags(t *testing.T) {
+	e, err := ParseLine(sampleLines[3].line) // ... ID=42197 DF PROTO=UDP ...
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.DontFragment || e.MoreFragments || e.FragOffset != 0 {
+		t.Errorf("DF line: got DF=%v MF=%v FRAG=%d, want DF only", e.DontFragment, e.MoreFragments, e.FragOffset)
+	}
+	if got := e.FragmentLabel(); got != "DF" {
+		t.Errorf("FragmentLabel: got %q, want %q", got, "DF")
+	}
+
+	line := `Mar 15 08:31:00 fw kernel: [DROP] IN=eth0 OUT= SRC=203.0.113.4 DST=192.168.1.1 LEN=1500 TOS=0x00 PREC=0x00 TTL=58 ID=5120 MF FRAG:185 PROTO=UDP`
+	e, err = ParseLine(line)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.DontFragment || !e.MoreFragments || e.FragOffset != 185 {
+		t.Errorf("fragment line: got DF=%v MF=%v FRAG=%d, want MF and offset 185", e.DontFragment, e.MoreFragments, e.FragOffset)
+	}
+	if got, want := e.FragmentLabel(), "MF, offset 185"; got != want {
+		t.Errorf("FragmentLabel: got %q, want %q", got, want)
+	}
+}
+
+func TestParseIPID(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+		want int
+	}{
+		{"DF packet", sampleLines[3].line, 42197},
+		{"fragment", `Mar 15 08:31:00 fw kernel: [DROP] IN=eth0 OUT= SRC=203.0.113.4 DST=192.168.1.1 LEN=1500 TOS=0x00 PREC=0x00 TTL=58 ID=5120 MF FRAG:185 PROTO=UDP`, 5120},
+		// The echo identifier after PROTO= is not the IP header's.
+		{"ICMP echo", `Mar 15 08:30:00 fw kernel: [UFW BLOCK] IN=eth0 OUT= SRC=198.51.100.7 DST=192.168.1.1 LEN=84 TTL=54 ID=812 PROTO=ICMP TYPE=8 CODE=0 ID=4321 SEQ=1`, 812},
+		// Nor is that of the packet quoted in an ICMP error.
+		{"ICMP error", `Mar 15 08:30:02 fw kernel: [UFW BLOCK] IN=eth0 OUT= SRC=198.51.100.7 DST=192.168.1.1 LEN=112 TTL=54 ID=300 PROTO=ICMP TYPE=3 CODE=3 [SRC=192.168.1.1 DST=198.51.100.7 LEN=84 TTL=64 ID=1 PROTO=UDP SPT=53 DPT=40000 LEN=64 ]`, 300},
+		{"not logged", sampleLines[0].line, 0},
+	}
+	for _, tc := range tests {
+		e, err := ParseLine(tc.line)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if e.ID != tc.want {
+			t.Errorf("%s: ID = %d, want %d", tc.name, e.ID, tc.want)
+		}
+	}
+}
+
+func TestParseTCPWindow(t *testing.T) {
+	e, err := ParseLine(`Jan  2 10:01:34 myhost kernel: [UFW BLOCK] IN=eth0 OUT= SRC=1.2.3.4 DST=10.0.0.1 LEN=60 TTL=50 PROTO=TCP SPT=443 DPT=51000 WINDOW=28960 RES=0x00 ACK SYN URGP=0`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Window != 28960 || !e.HasWindow {
+		t.Errorf("Window = %d (present %v), want 28960", e.Window, e.HasWindow)
+	}
+
+	// A zero window is logged, not absent.
+	e, err = ParseLine(`Jan  2 10:01:35 myhost kernel: [UFW BLOCK] IN=eth0 OUT= SRC=1.2.3.4 DST=10.0.0.1 LEN=40 TTL=50 PROTO=TCP SPT=443 DPT=51000 WINDOW=0 RES=0x00 ACK URGP=0`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Window != 0 || !e.HasWindow {
+		t.Errorf("WINDOW=0: Window = %d, present %v", e.Window, e.HasWindow)
+	}
+
+	if e, _ := ParseLine(sampleLines[1].line); e.HasWindow {
+		t.Errorf("UDP line has Window %d", e.Window)
+	}
+}
+
+func TestParseOwnerUIDGID(t *testing.T) {
+	e, err := ParseLine(`Jan  2 10:01:36 myhost kernel: [OUT DROP] IN= OUT=eth0 SRC=10.0.0.5 DST=203.0.113.9 LEN=60 TTL=64 ID=4242 DF PROTO=TCP SPT=51514 DPT=443 WINDOW=64240 RES=0x00 SYN URGP=0 UID=1000 GID=1000`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.UID == nil || *e.UID != 1000 || e.GID == nil || *e.GID != 1000 {
+		t.Errorf("UID/GID = %v/%v, want 1000/1000", e.UID, e.GID)
+	}
+	if !strings.Contains(e.String(), "UID       : 1000") {
+		t.Errorf("String() does not show the UID:\n%s", e.String())
+	}
+
+	// Root is a real owner, not an absent one.
+	e, err = ParseLine(`Jan  2 10:01:37 myhost kernel: [OUT DROP] IN= OUT=eth0 SRC=10.0.0.5 DST=203.0.113.9 LEN=76 TTL=64 PROTO=UDP SPT=123 DPT=123 LEN=56 UID=0 GID=0`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.UID == nil || *e.UID != 0 || e.GID == nil || *e.GID != 0 {
+		t.Errorf("root UID/GID = %v/%v, want 0/0", e.UID, e.GID)
+	}
+
+	if e, _ := ParseLine(sampleLines[0].line); e.UID != nil || e.GID != nil {
+		t.Errorf("inbound line has UID/GID %v/%v", e.UID, e.GID)
+	}
+}
+
+func TestParseMark(t *testing.T) {
+	e, err := ParseLine(`Jan  2 10:01:38 myhost kernel: [FWD DROP] IN=eth1 OUT=wg0 SRC=192.168.1.20 DST=10.8.0.1 LEN=84 TTL=63 ID=0 DF PROTO=ICMP TYPE=8 CODE=0 ID=7 SEQ=1 MARK=0x64`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Mark != "0x64" {
+		t.Errorf("Mark = %q, want 0x64", e.Mark)
+	}
+
+	// The hex is kept as logged, not normalised.
+	e, _ = ParseLine(`Jan  2 10:01:39 myhost kernel: [FWD DROP] IN=eth1 OUT=wg0 SRC=192.168.1.20 DST=10.8.0.1 LEN=60 TTL=63 PROTO=TCP SPT=40000 DPT=80 MARK=0xCAFE00`)
+	if e == nil || e.Mark != "0xCAFE00" {
+		t.Errorf("Mark = %+v, want 0xCAFE00", e)
+	}
+
+	if e, _ := ParseLine(sampleLines[0].line); e.Mark != "" {
+		t.Errorf("unmarked line has Mark %q", e.Mark)
+	}
+}
+
+func TestParseTimestampJournalShortISO(t *testing.T) {
+	line := `2026-02-22T00:00:28+0100 myhost kernel: [UFW BLOCK] IN=eth0 OUT= SRC=1.2.3.4 DST=10.0.0.1 LEN=60 TTL=50 PROTO=TCP SPT=12345 DPT=22`
+	e, err := ParseLine(line)
+	if err != nil {
+		t.Fatalf("ParseLine: %v", err)
+	}
+	if got, want := e.Timestamp.UTC().Format(time.RFC3339), "2026-02-21T23:00:28Z"; got != want {
+		t.Errorf("Timestamp = %s, want %s", got, want)
+	}
+	if _, err := parseTimestamp("2026-02-22T00:00:28+01"); err == nil {
+		t.Error("malformed offset accepted")
+	}
+}
+
+func TestParseTimestampSyslogVariants(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 30, 0, 0, time.Local)
+	local := func(y int, mo time.Month, d, h, mi, s int) time.Time {
+		return time.Date(y, mo, d, h, mi, s, 0, time.Local)
+	}
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"Jan  2 15:04:05", local(2025, 1, 2, 15, 4, 5)}, // a year ahead would be the future
+		{"Jan  1 00:10:00", local(2026, 1, 1, 0, 10, 0)},
+		{"Dec 31 23:59:59", local(2025, 12, 31, 23, 59, 59)},
+		{"Jan 02 2024 15:04:05", local(2024, 1, 2, 15, 4, 5)},
+		{"Dec 31 2025 23:00:00 UTC", time.Date(2025, 12, 31, 23, 0, 0, 0, time.UTC)},
+		{"Dec 31 23:00:00 UTC", time.Date(2025, 12, 31, 23, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseTimestampAt(tt.in, now)
+		if err != nil {
+			t.Errorf("parseTimestampAt(%q): %v", tt.in, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseTimestampAt(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+	if _, err := parseTimestampAt("Foo 2 15:04:05", now); err == nil {
+		t.Error("bad month accepted")
+	}
+}
+
+func TestParseLineSyslogYearAndZone(t *testing.T) {
+	for _, prefix := range []string{"Jan 02 2024 15:04:05", "Jan  2 15:04:05 UTC", "Jan 02 2024 15:04:05 UTC"} {
+		line := prefix + ` FW kernel: [DROP] IN=eth0 OUT= SRC=1.2.3.4 DST=10.0.0.1 LEN=60 TTL=50 PROTO=TCP SPT=12345 DPT=22`
+		e, err := ParseLine(line)
+		if err != nil {
+			t.Errorf("%q: %v", prefix, err)
+			continue
+		}
+		if e.Hostname != "FW" || e.Timestamp.UTC().Month() != time.January {
+			t.Errorf("%q: hostname %q, timestamp %v", prefix, e.Hostname, e.Timestamp)
+		}
+	}
+}
+
+func TestParseTimestampYearRollover(t *testing.T) {
+	newYear := time.Date(2026, 1, 1, 0, 5, 0, 0, time.Local)
+	dec31, err := parseTimestampAt("Dec 31 23:59:58", newYear)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dec31.Year() != 2025 {
+		t.Errorf("Dec 31 line read on Jan 1: year %d, want 2025", dec31.Year())
+	}
+	jan1, _ := parseTimestampAt("Jan  1 00:04:59", newYear)
+	if !dec31.Before(jan1) {
+		t.Errorf("Dec 31 (%v) sorts after Jan 1 (%v)", dec31, jan1)
+	}
+	// A line slightly ahead of our clock is skew, not last year.
+	if ahead, _ := parseTimestampAt("Jan  1 12:00:00", newYear); ahead.Year() != 2026 {
+		t.Errorf("line 12h ahead: year %d, want 2026", ahead.Year())
+	}
+}
+
+func TestLogEntryDirection(t *testing.T) {
+	tests := []struct {
+		in, out, want string
+	}{
+		{"eth0", "", "in"},
+		{"", "eth0", "out"},
+		{"eth0", "eth1", "fwd"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		if got := (LogEntry{In: tt.in, Out: tt.out}).Direction(); got != tt.want {
+			t.Errorf("Direction(IN=%q OUT=%q) = %q, want %q", tt.in, tt.out, got, tt.want)
+		}
+	}
+}
@@ -1,9 +1,12 @@
 package ports
 
 import (
+	"bufio"
 	_ "embed"
 	"encoding/csv"
 	"fmt"
+	"os"
+	"strconv"
 	"strings"
 )
 
@@ -33,6 +36,58 @@ func init() {
 	}
 }
 
+// LoadExtra merges an /etc/services-format file over the embedded IANA data.
+// Each line is "name port/proto [aliases...]" with "#" starting a comment;
+// entries from the file replace any existing name for the same port and
+// protocol. It must be called before the first Lookup from another
+// goroutine. Malformed lines are skipped and the first one is reported in
+// the returned error once the rest of the file has been merged.
+func LoadExtra(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var firstErr error
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		line, _, _ := strings.Cut(sc.Text(), "#")
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		key, ok := servicesKey(fields)
+		if !ok {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("%s:%d: expected \"name port/proto\", got %q", path, n, strings.TrimSpace(line))
+			}
+			continue
+		}
+		serviceMap[key] = fields[0]
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	return firstErr
+}
+
+// servicesKey validates the "port/proto" field of an /etc/services line and
+// returns it as a serviceMap key.
+func servicesKey(fields []string) (string, bool) {
+	if len(fields) < 2 {
+		return "", false
+	}
+	port, proto, ok := strings.Cut(fields[1], "/")
+	if !ok || proto == "" {
+		return "", false
+	}
+	if n, err := strconv.Atoi(port); err != nil || n < 1 || n > 65535 {
+		return "", false
+	}
+	return port + "/" + strings.ToUpper(proto), true
+}
+
 // Lookup returns the IANA service name for the given port and transport protocol
 // (e.g. "TCP", "UDP"), or "" if unknown.
 func Lookup(port int, proto string) string {
@@ -0,0 +1,41 @@
+package ports
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadExtraOverridesIANA(t *testing.T) {
+	if got := Lookup(22, "TCP"); got != "ssh" {
+		t.Fatalf("embedded Lookup(22, TCP) = %q, want ssh", got)
+	}
+	path := filepath.Join(t.TempDir(), "services")
+	data := `# local overrides
+jumpbox   22/tcp            # bastion
+myapp     48123/udp  app2
+broken-line
+`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		serviceMap["22/TCP"] = "ssh"
+		delete(serviceMap, "48123/UDP")
+	})
+
+	err := LoadExtra(path)
+	if err == nil || !strings.Contains(err.Error(), ":4:") {
+		t.Errorf("LoadExtra error = %v, want one naming line 4", err)
+	}
+	if got := Lookup(22, "TCP"); got != "jumpbox" {
+		t.Errorf("Lookup(22, TCP) = %q, want jumpbox", got)
+	}
+	if got := Lookup(48123, "udp"); got != "myapp" {
+		t.Errorf("Lookup(48123, UDP) = %q, want myapp", got)
+	}
+	if got := Lookup(22, "UDP"); got != "ssh" {
+		t.Errorf("Lookup(22, UDP) = %q, want ssh (untouched)", got)
+	}
+}
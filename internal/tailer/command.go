@@ -0,0 +1,61 @@
+package tailer
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// StartCommand runs name with args (e.g. journalctl -k -f) and emits every
+// line it writes to stdout. If the command cannot be started, or exits with
+// an error, the error and the last line of its stderr are sent on Errors; a
+// clean exit just ends the stream. Stop kills the command.
+func (t *Tailer) StartCommand(name string, args ...string) {
+	go t.runCommand(exec.Command(name, args...))
+}
+
+func (t *Tailer) runCommand(cmd *exec.Cmd) {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.StdoutPipe()
+	if err == nil {
+		err = cmd.Start()
+	}
+	if err != nil {
+		t.sendErr(err)
+		return
+	}
+
+	exited := make(chan struct{})
+	defer close(exited)
+	go func() {
+		select {
+		case <-t.done:
+			_ = cmd.Process.Kill()
+		case <-exited:
+		}
+	}()
+
+	ok := t.drain(bufio.NewReader(out))
+	err = cmd.Wait()
+	if !ok || err == nil {
+		return
+	}
+	select {
+	case <-t.done:
+		return // killed by Stop
+	default:
+	}
+	if msg := lastLine(stderr.String()); msg != "" {
+		err = fmt.Errorf("%w: %s", err, msg)
+	}
+	t.sendErr(fmt.Errorf("%s: %w", cmd.Args[0], err))
+}
+
+// lastLine returns the last non-empty line of s, trimmed.
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
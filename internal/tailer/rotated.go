@@ -0,0 +1,98 @@
+package tailer
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// rotatedFiles returns the rotated siblings of path — "<path>.N" and
+// "<path>.N.gz" as produced by logrotate — ordered oldest first (highest N
+// first), so reading them in order followed by path itself is chronological.
+func rotatedFiles(path string) []string {
+	matches, _ := filepath.Glob(path + ".*")
+	type rotated struct {
+		name string
+		n    int
+	}
+	var files []rotated
+	for _, m := range matches {
+		suffix := strings.TrimSuffix(strings.TrimPrefix(m, path+"."), ".gz")
+		n, err := strconv.Atoi(suffix)
+		if err != nil || n < 0 {
+			continue
+		}
+		files = append(files, rotated{m, n})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].n > files[j].n })
+	names := make([]string, len(files))
+	for i, f := range files {
+		names[i] = f.name
+	}
+	return names
+}
+
+// gzipMagic is the first two bytes of every gzip stream.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// isGzip reports whether path names gzip data: by a ".gz" suffix, or by the
+// magic bytes for archives that were renamed.
+func isGzip(path string) bool {
+	if strings.HasSuffix(path, ".gz") {
+		return true
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	head := make([]byte, len(gzipMagic))
+	_, err = io.ReadFull(f, head)
+	return err == nil && bytes.Equal(head, gzipMagic)
+}
+
+// readRotated sends every line of a rotated file or archive, decompressing
+// it if isGzip says so. It returns an error naming the file if it cannot be
+// opened or the stream turns out to be corrupt (the lines before the damage
+// have been sent by then), or errStopped if the tailer was stopped.
+func (t *Tailer) readRotated(name string) error {
+	compressed := isGzip(name)
+	f, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if compressed {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+		defer gz.Close()
+		r = namedReader{gz, name}
+	}
+	return t.send(bufio.NewReader(r))
+}
+
+// namedReader prefixes read errors other than io.EOF with the file name, so
+// a corrupt archive is reported as "iptables.log.2.gz: unexpected EOF".
+type namedReader struct {
+	r    io.Reader
+	name string
+}
+
+func (n namedReader) Read(p []byte) (int, error) {
+	k, err := n.r.Read(p)
+	if err != nil && err != io.EOF {
+		err = fmt.Errorf("%s: %w", n.name, err)
+	}
+	return k, err
+}
@@ -1,107 +1,180 @@
 // Package tailer provides a file-tailing goroutine that emits new lines as
-// they are appended to the watched file.  It uses a poll-based approach
-// (checking file size on a timer) that works on every OS without requiring
-// kernel-specific APIs.
+// they are appended to the watched file.  It is woken by inotify events where
+// available and otherwise falls back to polling on a timer; either way it
+// checks file size and identity after each wake-up to detect rotation.
 package tailer
 
 import (
 	"bufio"
+	"errors"
+	"fmt"
 	"io"
 	"os"
+	"sync/atomic"
 	"time"
 )
 
-const pollInterval = 250 * time.Millisecond
+// DefaultPollInterval is how often a file is checked when inotify is not
+// available; MinPollInterval is the shortest interval NewWithInterval accepts.
+const (
+	DefaultPollInterval = 250 * time.Millisecond
+	MinPollInterval     = 50 * time.Millisecond
+)
 
 // Tailer watches a file and sends new lines over Lines.
 type Tailer struct {
 	Lines  chan string
 	Errors chan error
-	done   chan struct{}
+	// Waiting receives the watched path once if it does not exist yet; the
+	// tailer then retries until it appears instead of failing.
+	Waiting chan string
+	// Skipped receives an error, naming the file, for each rotated sibling
+	// read with history that could not be opened or turned out corrupt; the
+	// tailer goes on to the next file rather than failing.
+	Skipped chan error
+	done    chan struct{}
+
+	// pollInterval paces the polling fallback; see watch.go.
+	pollInterval time.Duration
+
+	// reopens counts how many times the file was re-opened after rotation.
+	reopens atomic.Int64
 }
 
 // New creates a new Tailer but does not start it.
 func New() *Tailer {
 	return &Tailer{
-		Lines:  make(chan string, 256),
-		Errors: make(chan error, 8),
-		done:   make(chan struct{}),
+		Lines:        make(chan string, 256),
+		Errors:       make(chan error, 8),
+		Waiting:      make(chan string, 1),
+		Skipped:      make(chan error, 8),
+		done:         make(chan struct{}),
+		pollInterval: DefaultPollInterval,
+	}
+}
+
+// NewWithInterval is like New but polls every d when inotify is not
+// available. It rejects intervals shorter than MinPollInterval.
+func NewWithInterval(d time.Duration) (*Tailer, error) {
+	switch {
+	case d <= 0:
+		return nil, fmt.Errorf("poll interval must be positive, got %v", d)
+	case d < MinPollInterval:
+		return nil, fmt.Errorf("poll interval %v is below the minimum of %v", d, MinPollInterval)
 	}
+	t := New()
+	t.pollInterval = d
+	return t, nil
 }
 
 // Start begins watching path.  When history is true the entire file is read
-// from the beginning; otherwise only lines appended after Start is called are
-// emitted.  Call Stop to shut down.
+// from the beginning, preceded by any rotated siblings ("<path>.1",
+// "<path>.2.gz", …) oldest first; otherwise only lines appended after Start
+// is called are emitted.  A gzip-compressed path (by its ".gz" suffix or
+// magic bytes) is an archive: it is read once from the beginning, whatever
+// history says, and never tailed.  Call Stop to shut down.
 func (t *Tailer) Start(path string, history bool) {
 	go t.run(path, history)
 }
 
+// Reopens returns how many times the watched file has been re-opened because
+// it was rotated or truncated. Safe to call from any goroutine.
+func (t *Tailer) Reopens() int {
+	return int(t.reopens.Load())
+}
+
+// StartReader begins emitting lines read from r (e.g. os.Stdin) until it
+// reaches EOF. There is no file to re-open, so rotation handling does not
+// apply. Call Stop to shut down early.
+func (t *Tailer) StartReader(r io.Reader) {
+	go t.drain(bufio.NewReader(r))
+}
+
 // Stop signals the tailer goroutine to exit.
 func (t *Tailer) Stop() {
 	close(t.done)
 }
 
 func (t *Tailer) run(path string, history bool) {
+	if isGzip(path) {
+		if err := t.readRotated(path); err != nil && err != errStopped {
+			t.sendErr(err)
+		}
+		return
+	}
+	if history {
+		for _, name := range rotatedFiles(path) {
+			err := t.readRotated(name)
+			if err == errStopped {
+				return
+			}
+			if err != nil {
+				select {
+				case t.Skipped <- err:
+				default:
+				}
+			}
+		}
+	}
+
 	f, offset, err := openFile(path, history)
+	if errors.Is(err, os.ErrNotExist) {
+		f, offset, err = t.awaitFile(path, history)
+	}
 	if err != nil {
 		t.sendErr(err)
 		return
 	}
-	defer f.Close()
+	if f == nil {
+		return // stopped while waiting
+	}
+	defer func() { f.Close() }()
 
 	reader := bufio.NewReader(f)
+	w := t.newWaiter(path)
+	defer w.close()
 
 	for {
-		// Drain all currently available complete lines.
-		for {
-			line, err := reader.ReadString('\n')
-			if len(line) > 0 {
-				// Strip trailing newline characters.
-				l := len(line)
-				for l > 0 && (line[l-1] == '\n' || line[l-1] == '\r') {
-					l--
-				}
-				if l > 0 {
-					select {
-					case t.Lines <- line[:l]:
-					case <-t.done:
-						return
-					}
-				}
-			}
-			if err == io.EOF {
-				break
-			}
-			if err != nil {
-				t.sendErr(err)
-				return
-			}
+		if !t.drain(reader) {
+			return
 		}
 
 		// Update our known offset.
 		pos, _ := f.Seek(0, io.SeekCurrent)
 		offset = pos
 
-		// Wait before polling again.
-		select {
-		case <-t.done:
+		// Wait for the file to change (or the next poll tick).
+		if !w.wait() {
 			return
-		case <-time.After(pollInterval):
 		}
 
-		// Check for log rotation: if the file is now smaller than our last
-		// known position, it has been truncated or replaced.
+		// Check for log rotation. The path may now name a different file
+		// (rename-and-create rotation), or the same file may have been
+		// truncated in place (copytruncate) so it is smaller than our offset.
 		fi, err := os.Stat(path)
-		if err == nil && fi.Size() < offset {
-			// Re-open from the beginning.
-			f.Close()
-			f, offset, err = openFile(path, true)
-			if err != nil {
-				t.sendErr(err)
-				return
+		if err == nil {
+			replaced := false
+			if cur, statErr := f.Stat(); statErr == nil && !os.SameFile(fi, cur) {
+				replaced = true
+				// Pick up anything written to the old file before the switch.
+				_, _ = f.Seek(offset, io.SeekStart)
+				reader.Reset(f)
+				if !t.drain(reader) {
+					return
+				}
+			}
+			if replaced || fi.Size() < offset {
+				// Re-open from the beginning.
+				f.Close()
+				f, offset, err = openFile(path, true)
+				if err != nil {
+					t.sendErr(err)
+					return
+				}
+				reader.Reset(f)
+				t.reopens.Add(1)
+				continue
 			}
-			reader.Reset(f)
-			continue
 		}
 
 		// Reposition reader in case new data appeared after EOF.
@@ -110,6 +183,66 @@ func (t *Tailer) run(path string, history bool) {
 	}
 }
 
+// awaitFile announces on Waiting that path is missing, then retries opening
+// it every poll interval until it exists, like tail -F. It returns a nil file
+// and error if Stop is called first; errors other than "not exist" end the
+// wait.
+func (t *Tailer) awaitFile(path string, history bool) (*os.File, int64, error) {
+	select {
+	case t.Waiting <- path:
+	default:
+	}
+	w := pollWaiter{done: t.done, interval: t.pollInterval}
+	for w.wait() {
+		f, offset, err := openFile(path, history)
+		if !errors.Is(err, os.ErrNotExist) {
+			return f, offset, err
+		}
+	}
+	return nil, 0, nil
+}
+
+// drain sends every complete line currently available from reader. It returns
+// false if the tailer should exit (Stop was called or a read error occurred).
+func (t *Tailer) drain(reader *bufio.Reader) bool {
+	err := t.send(reader)
+	if err != nil && err != errStopped {
+		t.sendErr(err)
+	}
+	return err == nil
+}
+
+// errStopped is returned by send when Stop was called.
+var errStopped = errors.New("tailer stopped")
+
+// send sends every line of reader until EOF, when it returns nil. It
+// returns errStopped if the tailer is stopped, or the read error.
+func (t *Tailer) send(reader *bufio.Reader) error {
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			// Strip trailing newline characters.
+			l := len(line)
+			for l > 0 && (line[l-1] == '\n' || line[l-1] == '\r') {
+				l--
+			}
+			if l > 0 {
+				select {
+				case t.Lines <- line[:l]:
+				case <-t.done:
+					return errStopped
+				}
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
 func (t *Tailer) sendErr(err error) {
 	select {
 	case t.Errors <- err:
@@ -0,0 +1,247 @@
+package tailer
+
+import (
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// expectLines reads len(want) lines from t.Lines and compares them in order.
+func expectLines(t *testing.T, tl *Tailer, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		select {
+		case got := <-tl.Lines:
+			if got != w {
+				t.Fatalf("got line %q, want %q", got, w)
+			}
+		case err := <-tl.Errors:
+			t.Fatalf("tailer error: %v", err)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timed out waiting for line %q", w)
+		}
+	}
+}
+
+func TestTailerFollowsRenameRotation(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "iptables.log")
+	if err := os.WriteFile(path, []byte("old 1\nold 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tl := New()
+	tl.Start(path, true)
+	defer tl.Stop()
+	expectLines(t, tl, "old 1", "old 2")
+
+	// Rotate: move the file aside and create a fresh one at the original
+	// path that is larger than the old one, so a size check alone would
+	// not notice the switch.
+	if err := os.Rename(path, path+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("new 1 is a longer line\nnew 2 is a longer line\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	expectLines(t, tl, "new 1 is a longer line", "new 2 is a longer line")
+
+	if got := tl.Reopens(); got != 1 {
+		t.Errorf("Reopens: got %d, want 1", got)
+	}
+}
+
+func TestTailerHistoryReadsRotatedFilesOldestFirst(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "iptables.log")
+
+	gzf, err := os.Create(path + ".2.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	gw := gzip.NewWriter(gzf)
+	gw.Write([]byte("oldest\n"))
+	gw.Close()
+	gzf.Close()
+	if err := os.WriteFile(path+".1", []byte("older\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("current\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	// Not a numbered rotation; must be ignored.
+	if err := os.WriteFile(path+".bak", []byte("ignored\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tl := New()
+	tl.Start(path, true)
+	defer tl.Stop()
+	expectLines(t, tl, "oldest", "older", "current")
+}
+
+func TestNewWithIntervalValidates(t *testing.T) {
+	for _, d := range []time.Duration{0, -time.Second, MinPollInterval - time.Millisecond} {
+		if _, err := NewWithInterval(d); err == nil {
+			t.Errorf("NewWithInterval(%v): expected error", d)
+		}
+	}
+	tl, err := NewWithInterval(time.Second)
+	if err != nil {
+		t.Fatalf("NewWithInterval(1s): %v", err)
+	}
+	if tl.pollInterval != time.Second {
+		t.Errorf("pollInterval = %v, want 1s", tl.pollInterval)
+	}
+}
+
+func TestTailerWaitsForMissingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "iptables.log")
+
+	tl, err := NewWithInterval(MinPollInterval)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tl.Start(path, true)
+	defer tl.Stop()
+
+	select {
+	case got := <-tl.Waiting:
+		if got != path {
+			t.Errorf("Waiting = %q, want %q", got, path)
+		}
+	case err := <-tl.Errors:
+		t.Fatalf("missing file reported as error: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for the Waiting notice")
+	}
+
+	if err := os.WriteFile(path, []byte("first\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	expectLines(t, tl, "first")
+}
+
+// writeGzip writes content to path as a gzip stream.
+func writeGzip(t *testing.T, path, content string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gw := gzip.NewWriter(f)
+	gw.Write([]byte(content))
+	gw.Close()
+	f.Close()
+}
+
+func TestTailerReadsGzipArchive(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"iptables.log.2.gz", "archived"} { // suffix, then magic bytes
+		path := filepath.Join(dir, name)
+		writeGzip(t, path, "one\ntwo\n")
+
+		tl := New()
+		tl.Start(path, false) // history is implied
+		expectLines(t, tl, "one", "two")
+		tl.Stop()
+	}
+}
+
+func TestTailerReportsCorruptGzip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "iptables.log.1.gz")
+	writeGzip(t, path, "one\ntwo\nthree\n")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, data[:len(data)-6], 0o644); err != nil { // cut into the trailer
+		t.Fatal(err)
+	}
+
+	tl := New()
+	tl.Start(path, false)
+	defer tl.Stop()
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case <-tl.Lines:
+		case err := <-tl.Errors:
+			if !strings.Contains(err.Error(), path) {
+				t.Errorf("error %q does not name the file", err)
+			}
+			return
+		case <-deadline:
+			t.Fatal("corrupt archive read without an error")
+		}
+	}
+}
+
+func TestTailerSkipsCorruptRotatedFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "iptables.log")
+	if err := os.WriteFile(path+".3.gz", []byte("not gzip at all\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writeGzip(t, path+".2.gz", "oldest\n")
+	data, err := os.ReadFile(path + ".2.gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+".2.gz", data[:len(data)-6], 0o644); err != nil { // cut into the trailer
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path+".1", []byte("older\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("current\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tl := New()
+	tl.Start(path, true)
+	defer tl.Stop()
+	// The truncated archive's line is sent before the damage is found.
+	expectLines(t, tl, "oldest", "older", "current")
+	for _, name := range []string{path + ".3.gz", path + ".2.gz"} {
+		select {
+		case err := <-tl.Skipped:
+			if !strings.Contains(err.Error(), name) {
+				t.Errorf("skipped %q, want %s", err, name)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no notice for %s", name)
+		}
+	}
+
+	// The live file is still followed.
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("live\n")
+	f.Close()
+	expectLines(t, tl, "live")
+}
+
+func TestTailerCommand(t *testing.T) {
+	tl := New()
+	tl.StartCommand("sh", "-c", `printf 'one\ntwo\n'`)
+	defer tl.Stop()
+	expectLines(t, tl, "one", "two")
+
+	failing := New()
+	failing.StartCommand("sh", "-c", `echo 'permission denied' >&2; exit 1`)
+	defer failing.Stop()
+	select {
+	case err := <-failing.Errors:
+		if !strings.Contains(err.Error(), "permission denied") {
+			t.Errorf("error %q does not carry the command's stderr", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("failed command reported no error")
+	}
+}
@@ -0,0 +1,98 @@
+package tailer
+
+import (
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// safetyPoll bounds how long the event-driven waiter sleeps without any
+// event, so a missed or coalesced inotify event can only delay lines, never
+// stall the tailer.
+const safetyPoll = 5 * time.Second
+
+// waiter blocks until the watched file may have changed. It returns false
+// when the tailer has been stopped.
+type waiter interface {
+	wait() bool
+	close()
+}
+
+// newWaiter returns an inotify-backed waiter for path when a watch can be
+// established, and a fixed-interval poller otherwise. The parent directory is
+// watched rather than the file so that rename-and-create rotation, which
+// replaces the file, still produces events.
+func (t *Tailer) newWaiter(path string) waiter {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return pollWaiter{done: t.done, interval: t.pollInterval}
+	}
+	names := map[string]bool{filepath.Clean(path): true}
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		names[resolved] = true
+	}
+	for name := range names {
+		if err := w.Add(filepath.Dir(name)); err != nil {
+			w.Close()
+			return pollWaiter{done: t.done, interval: t.pollInterval}
+		}
+	}
+	return &eventWaiter{w: w, names: names, done: t.done, pollInterval: t.pollInterval}
+}
+
+// pollWaiter wakes every interval.
+type pollWaiter struct {
+	done     chan struct{}
+	interval time.Duration
+}
+
+func (p pollWaiter) wait() bool {
+	select {
+	case <-p.done:
+		return false
+	case <-time.After(p.interval):
+		return true
+	}
+}
+
+func (pollWaiter) close() {}
+
+// eventWaiter wakes on filesystem events that concern the watched file.
+type eventWaiter struct {
+	w     *fsnotify.Watcher
+	names map[string]bool
+	done  chan struct{}
+	// pollInterval is the cadence to degrade to if the watcher goes away.
+	pollInterval time.Duration
+}
+
+func (e *eventWaiter) wait() bool {
+	timeout := time.NewTimer(safetyPoll)
+	defer timeout.Stop()
+	for {
+		select {
+		case <-e.done:
+			return false
+		case <-timeout.C:
+			return true
+		case ev, ok := <-e.w.Events:
+			if !ok {
+				// Watcher gone; degrade to polling cadence.
+				return pollWaiter{done: e.done, interval: e.pollInterval}.wait()
+			}
+			if e.names[filepath.Clean(ev.Name)] {
+				return true
+			}
+		case _, ok := <-e.w.Errors:
+			// An error (e.g. queue overflow) may mean we missed an event;
+			// wake up and let the caller re-check the file.
+			if !ok {
+				return pollWaiter{done: e.done, interval: e.pollInterval}.wait()
+			}
+			return true
+		}
+	}
+}
+
+func (e *eventWaiter) close() { e.w.Close() }
@@ -0,0 +1,62 @@
+package ui
+
+import (
+	"fmt"
+	"net/netip"
+	"strings"
+
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
+)
+
+// BlockCommands returns ready-to-paste iptables and nftables commands that
+// drop traffic from e's source address. When the entry has a destination
+// port the rule is narrowed to that protocol and port. IPv6 sources use
+// ip6tables and "ip6 saddr".
+//
+// The source comes from a log line, which anyone able to send a packet can
+// shape, so it is only used once it parses as a plain IP address, and then in
+// its canonical form; anything else returns an error and no commands.
+func BlockCommands(e parser.LogEntry) (ipt, nft string, err error) {
+	addr, err := netip.ParseAddr(e.Src)
+	if err != nil || addr.Zone() != "" {
+		return "", "", fmt.Errorf("source %q is not an IP address", e.Src)
+	}
+	addr = addr.Unmap()
+	src := addr.String()
+	iptBin, family := "iptables", "ip"
+	if addr.Is6() {
+		iptBin, family = "ip6tables", "ip6"
+	}
+
+	iptArgs := []string{iptBin, "-A", "INPUT", "-s", src}
+	nftArgs := []string{"nft", "add", "rule", "inet", "filter", "input", family, "saddr", src}
+	if proto := strings.ToLower(e.Proto); e.DstPort != 0 && (proto == "tcp" || proto == "udp") {
+		port := fmt.Sprintf("%d", e.DstPort)
+		iptArgs = append(iptArgs, "-p", proto, "--dport", port)
+		nftArgs = append(nftArgs, proto, "dport", port)
+	}
+	iptArgs = append(iptArgs, "-j", "DROP")
+	nftArgs = append(nftArgs, "drop")
+	return strings.Join(iptArgs, " "), strings.Join(nftArgs, " "), nil
+}
+
+// RenderBlockSuggestion renders a small bordered box showing the block
+// commands for e. Nothing is executed; the commands are only displayed so
+// they can be copied. When e's source is not an IP address the box says so
+// instead.
+func RenderBlockSuggestion(e parser.LogEntry) string {
+	ipt, nft, err := BlockCommands(e)
+	if err != nil {
+		return StyleOverlayBorder.Render(StyleLabel.Render("No block command") + "\n\n" +
+			StyleDrop.Render(err.Error()) + "\n\n" +
+			StyleHelp.Render("[b/Esc] close"))
+	}
+	var sb strings.Builder
+	sb.WriteString(StyleLabel.Render("Block "+e.Src) + "\n\n")
+	sb.WriteString(StyleStatLabel.Render("iptables:") + "\n")
+	sb.WriteString("  " + ipt + "\n\n")
+	sb.WriteString(StyleStatLabel.Render("nftables:") + "\n")
+	sb.WriteString("  " + nft + "\n\n")
+	sb.WriteString(StyleHelp.Render("Not executed — copy and run as root. [b/Esc] close"))
+	return StyleOverlayBorder.Render(sb.String())
+}
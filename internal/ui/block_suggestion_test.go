@@ -0,0 +1,70 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
+)
+
+func TestBlockCommands(t *testing.T) {
+	tests := []struct {
+		name     string
+		e        parser.LogEntry
+		ipt, nft string
+	}{
+		{
+			"tcp with port",
+			parser.LogEntry{Src: "203.0.113.5", Proto: "TCP", DstPort: 22},
+			"iptables -A INPUT -s 203.0.113.5 -p tcp --dport 22 -j DROP",
+			"nft add rule inet filter input ip saddr 203.0.113.5 tcp dport 22 drop",
+		},
+		{
+			"icmp without port",
+			parser.LogEntry{Src: "203.0.113.5", Proto: "ICMP"},
+			"iptables -A INPUT -s 203.0.113.5 -j DROP",
+			"nft add rule inet filter input ip saddr 203.0.113.5 drop",
+		},
+		{
+			"ipv6 udp",
+			parser.LogEntry{Src: "2001:db8::1", Proto: "UDP", DstPort: 53},
+			"ip6tables -A INPUT -s 2001:db8::1 -p udp --dport 53 -j DROP",
+			"nft add rule inet filter input ip6 saddr 2001:db8::1 udp dport 53 drop",
+		},
+		{
+			"canonical form",
+			parser.LogEntry{Src: "2001:DB8:0:0::1", Proto: "ICMPv6"},
+			"ip6tables -A INPUT -s 2001:db8::1 -j DROP",
+			"nft add rule inet filter input ip6 saddr 2001:db8::1 drop",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ipt, nft, err := BlockCommands(tt.e)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ipt != tt.ipt {
+				t.Errorf("iptables = %q, want %q", ipt, tt.ipt)
+			}
+			if nft != tt.nft {
+				t.Errorf("nft = %q, want %q", nft, tt.nft)
+			}
+		})
+	}
+}
+
+func TestBlockCommandsRejectsNonAddress(t *testing.T) {
+	for _, src := range []string{"1.2.3.4;rm", "1.2.3.4 -j ACCEPT", "$(reboot)", "fe80::1%eth0;rm", ""} {
+		e := parser.LogEntry{Src: src, Proto: "TCP", DstPort: 22}
+		if ipt, nft, err := BlockCommands(e); err == nil || ipt != "" || nft != "" {
+			t.Errorf("Src %q: got %q / %q, err %v; want no commands", src, ipt, nft, err)
+		}
+		out := ansi.Strip(RenderBlockSuggestion(e))
+		if strings.Contains(out, "DROP") || !strings.Contains(out, "is not an IP address") {
+			t.Errorf("Src %q: suggestion box:\n%s", src, out)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/espenotterstad/iptables-log-tui/internal/blocklist"
+)
+
+// RenderBlockListOverlay renders the full-screen block list review page.
+// ips is the accumulated list in insertion order; cursor is the selected row.
+func RenderBlockListOverlay(ips []string, cursor, width, height int) string {
+	var sb strings.Builder
+	indent := strings.Repeat(" ", gutterWidth)
+
+	title := StyleLabel.Render(fmt.Sprintf("Block List (%d)", len(ips)))
+	sb.WriteString(indent + title + "\n")
+	sb.WriteString(StyleDivider.Render(strings.Repeat("─", width)) + "\n")
+
+	// Reserve two lines for the header and three for the export key legend.
+	rowsAvail := height - 5
+	if rowsAvail < 1 {
+		rowsAvail = 1
+	}
+
+	if len(ips) == 0 {
+		sb.WriteString(indent + StyleMuted.Render("Empty — press [+] on a log entry to add its source IP.") + "\n")
+	} else {
+		start := scrollStart(len(ips), cursor, rowsAvail)
+		end := start + rowsAvail
+		if end > len(ips) {
+			end = len(ips)
+		}
+		for i := start; i < end; i++ {
+			if i == cursor {
+				sb.WriteString(StyleSelected.Render(arrowRune+" "+ips[i]) + "\n")
+			} else {
+				sb.WriteString(indent + ips[i] + "\n")
+			}
+		}
+	}
+
+	sb.WriteByte('\n')
+	var formats []string
+	for _, f := range blocklist.Formats {
+		formats = append(formats, fmt.Sprintf("[%s]%s", f.Name[:1], f.Name))
+	}
+	sb.WriteString(indent + StyleLabel.Render("Export:") + " " + strings.Join(formats, "  ") + "\n")
+
+	// Pad to full height for the same reason as RenderDetailPage.
+	out := sb.String()
+	written := strings.Count(out, "\n")
+	for written < height {
+		out += "\n"
+		written++
+	}
+	return out
+}
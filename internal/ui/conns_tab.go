@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Conn is one row of the Connections tab: every event sharing the same
+// source, destination, destination port and protocol.
+type Conn struct {
+	Src      string
+	Dst      string
+	DstPort  int
+	Proto    string
+	Count    int
+	LastSeen time.Time
+}
+
+// Connections-tab column widths (content + trailing gap).
+const (
+	colCount = 9  // "1234567" (7) + 2 gap
+	colSeen  = 11 // "15:04:05"
+)
+
+// RenderConnsTab renders the aggregated connection list. conns must already
+// be in display order (busiest first); cursor is the selected row.
+func RenderConnsTab(conns []Conn, cursor, width, height int) string {
+	var sb strings.Builder
+
+	start, end := VisibleRows(len(conns), cursor, height)
+	addrW := colSrc
+	for _, c := range conns[start:end] {
+		if strings.Contains(c.Src, ":") || strings.Contains(c.Dst, ":") {
+			addrW = colAddr6
+			break
+		}
+	}
+
+	gutter := strings.Repeat(" ", gutterWidth)
+	header := lipgloss.NewStyle().Bold(true).Foreground(ColorHeader)
+	sb.WriteString(gutter + header.Render(
+		padCell("COUNT", colCount)+
+			padCell("LAST SEEN", colSeen)+
+			padCell("PROTO", colProto)+
+			padCell("SRC", addrW)+
+			padCell("DST", addrW)+
+			padCell("DPT", colDPT),
+	) + "\n")
+	sb.WriteString(StyleDivider.Render(strings.Repeat("─", width)) + "\n")
+
+	if len(conns) == 0 {
+		sb.WriteString(gutter + StyleMuted.Render("No connections yet.") + "\n")
+	}
+	for i := start; i < end; i++ {
+		c := conns[i]
+		row := padCell(fmt.Sprintf("%d", c.Count), colCount) +
+			padCell(c.LastSeen.Format(time.TimeOnly), colSeen) +
+			padCell(c.Proto, colProto) +
+			padCell(c.Src, addrW) +
+			padCell(c.Dst, addrW) +
+			padCell(portLabel(c.DstPort, c.Proto, false), colDPT)
+		if i == cursor {
+			prefix := lipgloss.NewStyle().Foreground(ColorStats).Render(arrowRune) +
+				strings.Repeat(" ", gutterWidth-lipgloss.Width(arrowRune))
+			sb.WriteString(prefix + StyleSelected.Render(row) + "\n")
+		} else {
+			sb.WriteString(gutter + row + "\n")
+		}
+	}
+
+	// Pad to full height for the same reason as RenderDetailPage.
+	out := sb.String()
+	for written := strings.Count(out, "\n"); written < height; written++ {
+		out += "\n"
+	}
+	return out
+}
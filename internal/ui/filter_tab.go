@@ -2,23 +2,179 @@ package ui
 
 import (
 	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+
+	"github.com/espenotterstad/iptables-log-tui/internal/keymap"
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
 )
 
 // Filters holds the current active filter state.
 type Filters struct {
-	Action    string // "DROP", "ACCEPT", "" (any)
-	Proto     string // "TCP", "UDP", "" (any)
+	// Action and Proto are sets: the entry's action ("DROP", "ACCEPT",
+	// "REJECT") or protocol ("TCP", "UDP") must be one of them. An empty
+	// set matches any. Change them with Toggle.
+	Action []string
+	Proto  []string
+	// Category is a classifier category the source IP must fall in, e.g.
+	// "External"; "" (any).
+	Category string
+	// Direction is a LogEntry.Direction the entry must have: "in", "out"
+	// or "fwd"; "" (any).
+	Direction string
 	IPSubstr  string // substring match against Src or Dst
+	// Src and Dst, when set, must equal the entry's addresses exactly. They
+	// are set together when drilling down from the Conns tab.
+	Src string
+	Dst string
+	// TextSearch is a case-insensitive substring matched against the
+	// entry's displayed fields (prefix, action, interfaces, protocol,
+	// category, addresses, ports and service names).
+	TextSearch string
+	// Regex, when non-nil, must match somewhere in the raw log line.
+	Regex *regexp.Regexp
+	// DstPortMin/DstPortMax restrict the destination port to an inclusive
+	// range; a single port has Min == Max. Both 0 means any port.
+	DstPortMin int
+	DstPortMax int
+	// SinceMinutes limits the view to entries from the last N minutes, 0 (any).
+	// The window slides: it is re-evaluated periodically, not only on new lines.
+	SinceMinutes int
 }
 
 // Active returns true if any filter is set.
 func (f Filters) Active() bool {
-	return f.Action != "" || f.Proto != "" || f.IPSubstr != ""
+	return len(f.Action) > 0 || len(f.Proto) > 0 || f.Category != "" || f.Direction != "" || f.IPSubstr != "" || f.Src != "" || f.Dst != "" || f.TextSearch != "" || f.Regex != nil ||
+		f.DstPortMax != 0 ||
+		f.SinceMinutes != 0
+}
+
+// Anonymized returns a copy of f with the addresses in its Src, Dst, IP
+// substring and text search masked by parser.AnonymizeIP, for display. f
+// itself, which the view still filters on, is not changed.
+func (f Filters) Anonymized() Filters {
+	f.Src, f.Dst = parser.AnonymizeIP(f.Src), parser.AnonymizeIP(f.Dst)
+	f.IPSubstr = parser.AnonymizeText(f.IPSubstr)
+	f.TextSearch = parser.AnonymizeText(f.TextSearch)
+	return f
+}
+
+// Toggle returns set with v removed if it is present, or added at the end if
+// not. set itself is left alone, so a Filters copied from a preset can be
+// changed without changing the preset.
+func Toggle(set []string, v string) []string {
+	if i := slices.Index(set, v); i >= 0 {
+		return slices.Delete(slices.Clone(set), i, i+1)
+	}
+	return append(slices.Clone(set), v)
 }
 
-// RenderFilterTab renders the Filters tab view.
-func RenderFilterTab(f Filters) string {
+// setLabel formats an Action or Proto set, e.g. "DROP or REJECT".
+func setLabel(set []string) string {
+	return strings.Join(set, " or ")
+}
+
+// ParsePortRange parses "N" (exact port) or "N-M" (inclusive range) into
+// lo and hi. An empty string or "0" yields 0, 0, meaning no filter.
+func ParsePortRange(s string) (lo, hi int, err error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "0" {
+		return 0, 0, nil
+	}
+	first, last, isRange := strings.Cut(s, "-")
+	if lo, err = parsePort(first); err != nil {
+		return 0, 0, err
+	}
+	hi = lo
+	if isRange {
+		if hi, err = parsePort(last); err != nil {
+			return 0, 0, err
+		}
+		if lo > hi {
+			return 0, 0, fmt.Errorf("range %q is backwards (start > end)", s)
+		}
+	}
+	return lo, hi, nil
+}
+
+func parsePort(s string) (int, error) {
+	n, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || n < 1 || n > 65535 {
+		return 0, fmt.Errorf("%q is not a port number (1-65535)", strings.TrimSpace(s))
+	}
+	return n, nil
+}
+
+// PortRangeLabel formats a port filter as "22" or "1024-65535", or "" when
+// no port filter is set.
+func PortRangeLabel(lo, hi int) string {
+	switch {
+	case hi == 0:
+		return ""
+	case lo == hi:
+		return strconv.Itoa(lo)
+	default:
+		return fmt.Sprintf("%d-%d", lo, hi)
+	}
+}
+
+// WindowLabel formats a time-window length in minutes, e.g. "last 15m" or
+// "last 1h". Returns "" for 0.
+func WindowLabel(minutes int) string {
+	switch {
+	case minutes == 0:
+		return ""
+	case minutes%60 == 0:
+		return fmt.Sprintf("last %dh", minutes/60)
+	default:
+		return fmt.Sprintf("last %dm", minutes)
+	}
+}
+
+// FilterSummary lists the filters set in f on one line, e.g.
+// "DROP · TCP · port 22", or "(none)" when no filter is set.
+func FilterSummary(f Filters) string {
+	var parts []string
+	add := func(prefix, v string) {
+		if v != "" {
+			parts = append(parts, prefix+v)
+		}
+	}
+	add("", setLabel(f.Action))
+	add("", setLabel(f.Proto))
+	add("", f.Category)
+	add("", directionLabel(f.Direction))
+	add("ip ~ ", f.IPSubstr)
+	add("src ", f.Src)
+	add("dst ", f.Dst)
+	add("text ~ ", f.TextSearch)
+	if f.Regex != nil {
+		add("regex ", f.Regex.String())
+	}
+	add("port ", PortRangeLabel(f.DstPortMin, f.DstPortMax))
+	add("", WindowLabel(f.SinceMinutes))
+	if len(parts) == 0 {
+		return "(none)"
+	}
+	return strings.Join(parts, " · ")
+}
+
+// SourceInfo describes the log source actually being read, for display.
+type SourceInfo struct {
+	Path     string // absolute path with symlinks resolved
+	Elevated bool   // running as root
+	Sudo     bool   // root privileges were obtained via sudo
+	Reopens  int    // times the file was re-opened after rotation
+}
+
+// RenderFilterTab renders the Filters tab view, naming the keys bound in km,
+// cut and padded to exactly height lines of at most width cells. presets are
+// listed by the number that applies them. acceptsHidden reports that the
+// dim-accept key has left ACCEPT rows out of the view, which hides entries
+// just as a filter does.
+func RenderFilterTab(f Filters, presets []Preset, src SourceInfo, km keymap.Keymap, acceptsHidden bool, width, height int) string {
 	var sb strings.Builder
 
 	sb.WriteString("\n" + StyleLabel.Render("Active Filters") + "\n")
@@ -34,33 +190,98 @@ func RenderFilterTab(f Filters) string {
 		sb.WriteString(label + value + "\n")
 	}
 
-	filterRow("Action", f.Action)
-	filterRow("Protocol", f.Proto)
+	filterRow("Action", setLabel(f.Action))
+	filterRow("Protocol", setLabel(f.Proto))
+	filterRow("Src category", f.Category)
+	filterRow("Direction", directionLabel(f.Direction))
 	filterRow("IP substring", f.IPSubstr)
+	filterRow("Src IP", f.Src)
+	filterRow("Dst IP", f.Dst)
+	filterRow("Text search", f.TextSearch)
+	regex := ""
+	if f.Regex != nil {
+		regex = f.Regex.String()
+	}
+	filterRow("Regex", regex)
+	filterRow("Dst port", PortRangeLabel(f.DstPortMin, f.DstPortMax))
+	filterRow("Time window", WindowLabel(f.SinceMinutes))
+	hidden := ""
+	if acceptsHidden {
+		hidden = "ACCEPT rows"
+	}
+	filterRow("Hidden", hidden)
 
 	sb.WriteString("\n")
-	if f.Active() {
-		sb.WriteString(StyleHelp.Render("  Press [c] to clear all filters") + "\n")
-	} else {
+	if clear := km.Short(keymap.ClearFilters); f.Active() && clear != "" {
+		sb.WriteString(StyleHelp.Render("  Press ["+clear+"] to clear all filters") + "\n")
+	} else if f.Active() {
+		sb.WriteString(StyleHelp.Render("  Press [Esc] in the Logs tab to clear all filters") + "\n")
+	} else if !acceptsHidden {
 		sb.WriteString(StyleMuted.Render("  No active filters — all entries are shown.") + "\n")
 	}
+	if dim := km.Short(keymap.DimAccept); acceptsHidden && dim != "" {
+		sb.WriteString(StyleHelp.Render("  Press ["+dim+"] in the Logs tab to show ACCEPT rows") + "\n")
+	}
+	if legend := km.Short(keymap.Legend); legend != "" {
+		sb.WriteString(StyleHelp.Render("  Press ["+legend+"] for what the table's colors mean") + "\n")
+	}
+
+	sb.WriteString("\n" + StyleLabel.Render("Presets") + "\n")
+	sb.WriteString(StyleDivider.Render(strings.Repeat("─", 40)) + "\n\n")
+	if len(presets) == 0 {
+		hint := "  No saved presets."
+		if save := km.Short(keymap.SavePreset); save != "" {
+			hint = "  No saved presets — press [" + save + "] to save the current filters."
+		}
+		sb.WriteString(StyleMuted.Render(hint) + "\n")
+	}
+	for i, p := range presets {
+		sb.WriteString(fmt.Sprintf("  %s  %-16s%s\n",
+			StyleFilter.Render(fmt.Sprintf("%2d", i+1)), p.Name,
+			StyleMuted.Render(FilterSummary(p.Filters))))
+	}
 
 	sb.WriteString("\n" + StyleLabel.Render("Quick-Filter Keys (active in Logs tab)") + "\n")
 	sb.WriteString(StyleDivider.Render(strings.Repeat("─", 40)) + "\n\n")
-	keys := [][2]string{
-		{"d", "Toggle DROP-only"},
-		{"a", "Toggle ACCEPT-only"},
-		{"t", "Toggle TCP-only"},
-		{"u", "Toggle UDP-only"},
-		{"/", "Search by IP substring"},
-		{"Esc", "Clear filter / close search"},
+	keys := []helpKey{
+		act("Add / remove DROP in the action filter", keymap.FilterDrop),
+		act("Add / remove ACCEPT in the action filter", keymap.FilterAccept),
+		act("Add / remove REJECT in the action filter", keymap.FilterReject),
+		act("Cycle through every action seen so far, then any", keymap.CycleAction),
+		act("Add / remove TCP in the protocol filter", keymap.FilterTCP),
+		act("Add / remove UDP in the protocol filter", keymap.FilterUDP),
+		act("Toggle external sources only", keymap.FilterExtern),
+		act("Cycle direction (in, out, fwd, any)", keymap.FilterDir),
+		act("Search by IP substring ([Tab] in the prompt: any field, regex)", keymap.Search),
+		act("Filter by destination port or range", keymap.PortFilter),
+		act("Cycle time window (5m, 15m, 1h, off)", keymap.TimeWindow),
+		act("Save the current filters as a named preset", keymap.SavePreset),
+		act("Apply a saved preset by number or name", keymap.Presets),
+		fixed("Esc", "Clear filter / close search"),
 	}
 	for _, k := range keys {
+		label := k.label(km)
+		if label == "" {
+			continue
+		}
 		sb.WriteString(fmt.Sprintf("  %s  %s\n",
-			StyleFilter.Render(fmt.Sprintf("[%-4s]", k[0])),
-			StyleStatLabel.Render(k[1]),
+			StyleFilter.Render(fmt.Sprintf("[%-4s]", label)),
+			StyleStatLabel.Render(k.desc),
 		))
 	}
 
-	return sb.String()
+	sb.WriteString("\n" + StyleLabel.Render("Source") + "\n")
+	sb.WriteString(StyleDivider.Render(strings.Repeat("─", 40)) + "\n\n")
+	privs := "user"
+	switch {
+	case src.Elevated && src.Sudo:
+		privs = "root (via sudo)"
+	case src.Elevated:
+		privs = "root"
+	}
+	sb.WriteString(fmt.Sprintf("  %-16s%s\n", "File:", src.Path))
+	sb.WriteString(fmt.Sprintf("  %-16s%s\n", "Privileges:", privs))
+	sb.WriteString(fmt.Sprintf("  %-16s%d\n", "Reopened:", src.Reopens))
+
+	return fitPage(sb.String(), width, height)
 }
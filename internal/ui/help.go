@@ -0,0 +1,202 @@
+package ui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/espenotterstad/iptables-log-tui/internal/keymap"
+)
+
+// helpSection is one context's group of key bindings on the help overlay.
+type helpSection struct {
+	title string
+	keys  []helpKey
+}
+
+// helpKey is one line of a section. Its keys are those bound to actions,
+// shown "a / b" when there are several, or fixed for keys that cannot be
+// remapped.
+type helpKey struct {
+	actions []keymap.Action
+	fixed   string
+	desc    string
+}
+
+// act and fixed build helpKeys.
+func act(desc string, actions ...keymap.Action) helpKey {
+	return helpKey{actions: actions, desc: desc}
+}
+
+func fixed(key, desc string) helpKey { return helpKey{fixed: key, desc: desc} }
+
+// label returns the keys of k under km, or "" if none of its actions is
+// bound.
+func (k helpKey) label(km keymap.Keymap) string {
+	if k.fixed != "" {
+		return k.fixed
+	}
+	var parts []string
+	for _, a := range k.actions {
+		if l := km.Label(a); l != "" {
+			parts = append(parts, l)
+		}
+	}
+	return strings.Join(parts, " / ")
+}
+
+// helpLeft and helpRight are the two columns of the help overlay. Keep them
+// in step with the README's key binding tables.
+var (
+	helpLeft = []helpSection{
+		{"Logs", []helpKey{
+			act("move cursor", keymap.Up, keymap.Down),
+			act("page up / down", keymap.PageUp, keymap.PageDown),
+			act("first / last row", keymap.Top, keymap.Bottom),
+			act("follow newest (LIVE)", keymap.Follow),
+			act("go to row number", keymap.Goto),
+			act("count: digits, then a move", keymap.Count),
+			act("scroll sideways", keymap.ScrollLeft, keymap.ScrollRight),
+			act("open detail", keymap.Detail),
+			fixed("Esc", "clear filters"),
+			act("add / remove DROP, ACCEPT", keymap.FilterDrop, keymap.FilterAccept),
+			act("add / remove REJECT", keymap.FilterReject),
+			act("cycle through seen actions", keymap.CycleAction),
+			act("add / remove TCP, UDP", keymap.FilterTCP, keymap.FilterUDP),
+			act("external sources only", keymap.FilterExtern),
+			act("direction: in, out, fwd, any", keymap.FilterDir),
+			act("search (Tab: IP, text, regex)", keymap.Search),
+			act("dst port or range", keymap.PortFilter),
+			act("time window", keymap.TimeWindow),
+			act("clear all filters", keymap.ClearFilters),
+			act("save / apply filter preset", keymap.SavePreset, keymap.Presets),
+			act("sort column / direction", keymap.Sort, keymap.SortReverse),
+			act("LEN/TTL columns", keymap.LenTTL),
+			act("source port column", keymap.SrcPort),
+			act("port numbers with names", keymap.PortNumbers),
+			act("wide mode (140+ columns)", keymap.Wide),
+			act("minute / hour separators", keymap.GroupTime),
+			act("collapse repeated entries", keymap.Collapse),
+			act("ACCEPT rows: dim, hide, show", keymap.DimAccept),
+			act("DST reverse-DNS names", keymap.DstDNS),
+			act("pause / resume", keymap.Pause),
+			act("add to / open block list", keymap.BlockAdd, keymap.BlockList),
+			act("copy source IP", keymap.CopyIP),
+			act("export JSON / CSV", keymap.ExportJSON, keymap.ExportCSV),
+			fixed("mouse", "click selects, wheel moves"),
+		}},
+	}
+	helpRight = []helpSection{
+		{"Global", []helpKey{
+			fixed("1-5", "switch tab"),
+			act("next tab", keymap.NextTab),
+			act("light / dark theme", keymap.Theme),
+			act("mask IP addresses", keymap.Anonymize),
+			act("this help", keymap.Help),
+			act("quit", keymap.Quit),
+		}},
+		{"Detail", []helpKey{
+			fixed("Esc/Enter", "back to the list"),
+			act("next / previous entry", keymap.Down, keymap.Up),
+			act("add to block list", keymap.BlockAdd),
+			act("copy source IP", keymap.CopyIP),
+			act("block command", keymap.BlockCommand),
+			act("ping / traceroute source", keymap.Ping, keymap.Traceroute),
+		}},
+		{"Block list", []helpKey{
+			act("select", keymap.Up, keymap.Down),
+			fixed("x", "remove"),
+			fixed("p/i/n/c", "export"),
+		}},
+		{"Filters", []helpKey{
+			act("clear all filters", keymap.ClearFilters),
+			act("save / apply filter preset", keymap.SavePreset, keymap.Presets),
+			act("color legend", keymap.Legend),
+		}},
+		{"Conns", []helpKey{
+			act("select", keymap.Up, keymap.Down),
+			act("show in Logs", keymap.Detail),
+		}},
+		{"Raw", []helpKey{
+			act("select", keymap.Up, keymap.Down),
+			act("copy the raw line", keymap.CopyIP),
+		}},
+	}
+)
+
+// renderHelpColumn renders sections one below the other, with the keys as
+// bound in km. Unbound actions are left out.
+func renderHelpColumn(sections []helpSection, km keymap.Keymap) string {
+	width := 0
+	for _, s := range sections {
+		for _, k := range s.keys {
+			width = max(width, lipgloss.Width(k.label(km)))
+		}
+	}
+	var sb strings.Builder
+	for i, s := range sections {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(StyleLabel.Render(s.title) + "\n")
+		for _, k := range s.keys {
+			label := k.label(km)
+			if label == "" {
+				continue
+			}
+			pad := strings.Repeat(" ", width-lipgloss.Width(label))
+			sb.WriteString("  " + StyleFilter.Render(label+pad) + " " + k.desc + "\n")
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// helpBody returns the lines of both help columns: side by side when they
+// fit in width next to the gutter, otherwise one below the other.
+func helpBody(width int, km keymap.Keymap) []string {
+	left, right := renderHelpColumn(helpLeft, km), renderHelpColumn(helpRight, km)
+	body := lipgloss.JoinHorizontal(lipgloss.Top, left, "    ", right)
+	if gutterWidth+lipgloss.Width(body) > width {
+		body = left + "\n\n" + right
+	}
+	return strings.Split(body, "\n")
+}
+
+// helpRows is how many body lines the help overlay shows at height, below
+// its title and divider.
+func helpRows(height int) int {
+	return max(height-2, 1)
+}
+
+// HelpMaxScroll returns the largest useful offset for RenderHelpOverlay at
+// width and height: 0 when every binding fits.
+func HelpMaxScroll(width, height int, km keymap.Keymap) int {
+	return max(len(helpBody(width, km))-helpRows(height), 0)
+}
+
+// RenderHelpOverlay renders the full-screen key binding reference opened
+// with ?, listing the keys bound in km. When the bindings do not fit in
+// height lines the body scrolls: offset is the first body line shown, and
+// the title says which way there is more.
+func RenderHelpOverlay(width, height, offset int, km keymap.Keymap) string {
+	var sb strings.Builder
+	indent := strings.Repeat(" ", gutterWidth)
+	body := helpBody(width, km)
+	rows := helpRows(height)
+	offset = min(max(offset, 0), max(len(body)-rows, 0))
+
+	title := StyleLabel.Render("Key Bindings")
+	switch above, below := offset > 0, offset+rows < len(body); {
+	case above && below:
+		title += StyleMuted.Render("  ↑↓ more")
+	case above:
+		title += StyleMuted.Render("  ↑ more")
+	case below:
+		title += StyleMuted.Render("  ↓ more")
+	}
+	sb.WriteString(indent + title + "\n")
+	sb.WriteString(StyleDivider.Render(strings.Repeat("─", width)) + "\n")
+	for _, line := range body[offset:min(offset+rows, len(body))] {
+		sb.WriteString(indent + line + "\n")
+	}
+	return fitPage(sb.String(), width, height)
+}
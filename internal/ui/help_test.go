@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/espenotterstad/iptables-log-tui/internal/keymap"
+)
+
+func TestRenderHelpOverlayHeight(t *testing.T) {
+	for _, h := range []int{10, 60} {
+		out := RenderHelpOverlay(80, h, 0, keymap.Default())
+		if got := strings.Count(out, "\n"); got != h {
+			t.Errorf("height %d: rendered %d lines", h, got)
+		}
+	}
+	if out := RenderHelpOverlay(80, 60, 0, keymap.Default()); !strings.Contains(out, "Detail") || !strings.Contains(out, "Filters") {
+		t.Error("help overlay is missing a section")
+	}
+	// Too narrow for two columns: stacked, so no description is cut short.
+	if out := RenderHelpOverlay(80, 200, 0, keymap.Default()); strings.Contains(out, "…") || !strings.Contains(out, "ping / traceroute source") {
+		t.Errorf("help overlay does not fit 80 columns:\n%s", out)
+	}
+}
+
+func TestRenderHelpOverlayScrolls(t *testing.T) {
+	km := keymap.Default()
+	if HelpMaxScroll(120, 60, km) != 0 {
+		t.Error("help overlay scrolls although it fits")
+	}
+	maxScroll := HelpMaxScroll(80, 20, km)
+	if maxScroll == 0 {
+		t.Fatal("help overlay fits in 20 lines; test needs a smaller height")
+	}
+	if out := RenderHelpOverlay(80, 20, 0, km); strings.Contains(out, "copy the raw line") || !strings.Contains(out, "↓ more") {
+		t.Error("unscrolled overlay should hide the last bindings and say there is more below")
+	}
+	out := RenderHelpOverlay(80, 20, maxScroll, km)
+	if !strings.Contains(out, "copy the raw line") || !strings.Contains(out, "↑ more") {
+		t.Error("fully scrolled overlay should show the last bindings and say there is more above")
+	}
+	if out != RenderHelpOverlay(80, 20, maxScroll+5, km) {
+		t.Error("offset past the end is not clamped")
+	}
+}
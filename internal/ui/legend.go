@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/espenotterstad/iptables-log-tui/internal/classifier"
+	"github.com/espenotterstad/iptables-log-tui/internal/keymap"
+)
+
+// RenderLegend renders what the colors of the log table mean, in place of
+// the Filters tab's sections, cut and padded to exactly height lines of at
+// most width cells. Every swatch is drawn with the style the table itself
+// uses, so the legend follows the active theme.
+func RenderLegend(km keymap.Keymap, width, height int) string {
+	var sb strings.Builder
+	sb.WriteString("\n" + StyleLabel.Render("Colors") + "\n")
+	sb.WriteString(StyleDivider.Render(strings.Repeat("─", 40)) + "\n\n")
+
+	swatch := func(st lipgloss.Style, sample, meaning string) {
+		sb.WriteString(fmt.Sprintf("  %s  %s\n",
+			st.Render(fmt.Sprintf("%-14s", sample)),
+			StyleStatLabel.Render(meaning),
+		))
+	}
+	swatch(RowStyle("DROP", "TCP", false), "DROP / REJECT", "Row: packet dropped or rejected")
+	swatch(RowStyle("ACCEPT", "TCP", false), "ACCEPT", "Row: packet accepted")
+	swatch(RowStyle("LOG", "ICMP", false), "ICMP", "Row: ICMP with any other action")
+	swatch(RowStyle("LOG", "TCP", false), "other", "Row: any other action")
+	swatch(RowStyle("DROP", "TCP", true), "selected", "Row under the cursor")
+	swatch(StyleDrop.Bold(true), "!", "Gutter: source above --alert-rate")
+	swatch(StyleDrop.Bold(true), "*", "Gutter: source in a --blocklist network")
+	swatch(StyleDrop.Bold(true), "~", "Gutter: source over --alert-rate in an earlier session")
+	swatch(lipgloss.NewStyle().Reverse(true), "203.0.113.5", "SRC/DST: search match")
+	for _, cat := range []string{classifier.CatInternal, classifier.CatPrivate, classifier.CatMulticast, classifier.CatExternal} {
+		swatch(catStyle(cat), cat, "CAT: source address category")
+	}
+	if legend := km.Short(keymap.Legend); legend != "" {
+		sb.WriteString("\n" + StyleHelp.Render("  Press ["+legend+"] to go back to the filters") + "\n")
+	}
+	return fitPage(sb.String(), width, height)
+}
@@ -0,0 +1,21 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/espenotterstad/iptables-log-tui/internal/keymap"
+)
+
+func TestRenderLegend(t *testing.T) {
+	out := ansi.Strip(RenderLegend(keymap.Default(), 80, 40))
+	for _, want := range []string{"DROP / REJECT", "ACCEPT", "ICMP", "selected", "Internal", "Private", "Multicast", "External", "Press [C]"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("legend missing %q", want)
+		}
+	}
+	if rows := strings.Count(out, "\n"); rows != 40 {
+		t.Errorf("legend is %d rows, want 40", rows)
+	}
+}
@@ -2,10 +2,12 @@ package ui
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/espenotterstad/iptables-log-tui/internal/classifier"
 	"github.com/espenotterstad/iptables-log-tui/internal/parser"
 	"github.com/espenotterstad/iptables-log-tui/internal/ports"
@@ -18,15 +20,28 @@ import (
 // cells so all columns line up.
 const (
 	colTime   = 11 // "15:04:05" (8) + 3 gap
+	colRep    = 9  // "x99999" (6) + 3 gap
+	colDir    = 8  // "⇄ fwd"    (5) + 3 gap
 	colIn     = 9  // "eth0"     (6) + 3 gap
 	colAction = 9  // "ACCEPT"   (6) + 3 gap
 	colProto  = 7  // "ICMP"     (4) + 3 gap
 	colCat    = 12 // "Multicast" (9) + 3 gap
 	colSrc    = 18 // IPv4 max   (15) + 3 gap
 	colDst    = 18 // same
+	colSPT    = 16 // same as colDPT
 	colDPT    = 16 // "ms-wbt-server" (13) + 3 gap
+	colCC     = 5  // ISO country code (2) + 3 gap
+	colLen    = 8  // "65535" (5) + 3 gap; colLen+colTTL == colDPT
+	colTTL    = 8  // "255"   (3) + 5 gap
+
+	// colAddr6 replaces colSrc/colDst for frames that show an IPv6 address.
+	colAddr6 = 42 // fully expanded IPv6 (39) + 3 gap
 )
 
+// WideMinWidth is the terminal width from which TableOptions.Wide takes
+// effect; narrower terminals keep the compact column set.
+const WideMinWidth = 140
+
 // arrowRune is the cursor indicator shown on the selected row.
 // Its display width is measured at runtime with lipgloss.Width because many
 // terminals render it as 2 cells (ambiguous-width Unicode character).
@@ -36,58 +51,196 @@ const arrowRune = "▶"
 // every row (header and data alike).
 var gutterWidth = lipgloss.Width(arrowRune) + 1
 
+// ColumnMode selects what the last column region of the log table shows.
+type ColumnMode int
+
+const (
+	// ColumnsPort shows the destination port (the default).
+	ColumnsPort ColumnMode = iota
+	// ColumnsLenTTL shows packet length and TTL instead, for MTU and
+	// fragmentation work. It occupies the same width as ColumnsPort.
+	ColumnsLenTTL
+)
+
+// TableOptions controls how RenderLogsTab draws rows.
+type TableOptions struct {
+	// Columns selects the DPT or LEN/TTL column region.
+	Columns ColumnMode
+	// Categorize maps a source IP to its CAT column value.
+	Categorize func(string) string
+	// Match is the search substring highlighted in SRC and DST, "" for none.
+	Match string
+	// MatchRe, when set, is highlighted in SRC and DST instead of Match.
+	MatchRe *regexp.Regexp
+	// Noisy reports whether a source IP has crossed the alert threshold;
+	// such rows get a "!" in the gutter. Nil marks nothing.
+	Noisy func(string) bool
+	// Blocklisted reports whether a source IP is in a --blocklist network;
+	// such rows get a "*" in the gutter, which wins over "!". Nil marks
+	// nothing.
+	Blocklisted func(string) bool
+	// KnownOffender reports whether a source IP crossed the alert threshold
+	// in an earlier session; such rows get a "~" in the gutter unless "*" or
+	// "!" applies. Nil marks nothing.
+	KnownOffender func(string) bool
+	// DstName maps a destination IP to its reverse-DNS name, "" while unknown.
+	// When non-nil the DST column shows the name in place of the address.
+	DstName func(string) string
+	// Country maps a source IP to its ISO country code. When nil (no GeoIP
+	// database loaded) the CC column is not shown at all.
+	Country func(string) string
+	// Offset is the number of cells the table is scrolled to the right. The
+	// gutter stays in place; values past MaxHScroll are clamped.
+	Offset int
+	// SrcPort adds an SPT column before the DPT or LEN/TTL region.
+	SrcPort bool
+	// PortNumbers shows known ports as "22 (ssh)" rather than just "ssh".
+	PortNumbers bool
+	// DimAccept draws ACCEPT rows, other than the selected one, entirely in
+	// the muted color so the rest stand out.
+	DimAccept bool
+	// Collapsed adds a REP column after TIME with each row's repeat count,
+	// for rows that stand for several merged entries.
+	Collapsed bool
+	// Group, when non-zero, inserts a separator row wherever the timestamp
+	// crosses into a new bucket of this length (a minute or an hour).
+	Group time.Duration
+	// Anonymize masks SRC and DST with parser.AnonymizeIP; DstName is not
+	// used, since a name gives the address away.
+	Anonymize bool
+	// Wide, on terminals at least WideMinWidth cells wide, adds an OUT
+	// column after IN, SPT, and LEN and TTL after DPT, and shows ports as
+	// number and service name. Narrower terminals ignore it.
+	Wide bool
+}
+
+// forWidth returns opts as they apply to a table width cells wide: Wide is
+// cleared below WideMinWidth, and otherwise turns on the options it implies.
+func (opts TableOptions) forWidth(width int) TableOptions {
+	if width < WideMinWidth {
+		opts.Wide = false
+	}
+	if opts.Wide {
+		opts.SrcPort, opts.PortNumbers = true, true
+	}
+	return opts
+}
+
 // RenderLogsTab renders the scrollable log table.
-func RenderLogsTab(entries []parser.LogEntry, cursor, width, height int, categorize func(string) string) string {
+func RenderLogsTab(entries []parser.LogEntry, cursor, width, height int, opts TableOptions) string {
 	var sb strings.Builder
-
-	// ── Column header ───────────────────────────────────────────────────────
-	gutter := strings.Repeat(" ", gutterWidth)
-	sb.WriteString(gutter + renderHeader())
-	sb.WriteByte('\n')
-	sb.WriteString(StyleDivider.Render(strings.Repeat("─", width)))
-	sb.WriteByte('\n')
+	opts = opts.forWidth(width)
 
 	// ── Scrolling window ────────────────────────────────────────────────────
+	rows := TableRows(entries, cursor, height, opts.Group)
+	start, end := entryRange(rows)
 	rowsAvail := height - 4
 	if rowsAvail < 1 {
 		rowsAvail = 1
 	}
+	addrW := addrWidth(entries[start:end])
+	sptW := sptWidth(entries[start:end], opts)
+	dptW := dptWidth(entries[start:end], opts)
 
-	start := scrollStart(len(entries), cursor, rowsAvail)
-	end := start + rowsAvail
-	if end > len(entries) {
-		end = len(entries)
+	// ── Horizontal scrolling ────────────────────────────────────────────────
+	// Rows are rendered at full width and then cut to the viewport, so the
+	// header and every data row scroll by exactly the same amount.
+	viewW := max(width-gutterWidth, 1)
+	offset := min(max(opts.Offset, 0), max(tableWidth(addrW, sptW, dptW, opts)-viewW, 0))
+	clip := func(row string) string {
+		return ansi.Cut(row, offset, offset+viewW)
 	}
 
-	for i := start; i < end; i++ {
+	// ── Column header ───────────────────────────────────────────────────────
+	gutter := strings.Repeat(" ", gutterWidth)
+	sb.WriteString(gutter + clip(renderHeader(addrW, sptW, dptW, opts)))
+	sb.WriteByte('\n')
+	sb.WriteString(StyleDivider.Render(strings.Repeat("─", width)))
+	sb.WriteByte('\n')
+
+	for k, i := range rows {
+		if i < 0 {
+			next := entries[rows[k+1]].Timestamp.Truncate(opts.Group)
+			sb.WriteString(gutter + separatorRow(next, opts.Group, viewW) + "\n")
+			continue
+		}
 		selected := i == cursor
+		mark := ""
+		switch {
+		case opts.Blocklisted != nil && opts.Blocklisted(entries[i].Src):
+			mark = "*"
+		case opts.Noisy != nil && opts.Noisy(entries[i].Src):
+			mark = "!"
+		case opts.KnownOffender != nil && opts.KnownOffender(entries[i].Src):
+			mark = "~"
+		}
 		var prefix string
 		if selected {
 			rendered := lipgloss.NewStyle().Foreground(ColorStats).Render(arrowRune)
 			trailing := strings.Repeat(" ", gutterWidth-lipgloss.Width(arrowRune))
+			if mark != "" {
+				trailing = StyleDrop.Bold(true).Render(mark) + trailing[1:]
+			}
 			prefix = rendered + trailing
+		} else if mark != "" {
+			prefix = StyleDrop.Bold(true).Render(mark) + strings.Repeat(" ", gutterWidth-1)
 		} else {
 			prefix = strings.Repeat(" ", gutterWidth)
 		}
-		sb.WriteString(prefix + renderDataRow(entries[i], selected, categorize))
+		sb.WriteString(prefix + clip(renderDataRow(entries[i], selected, addrW, sptW, dptW, opts)))
 		sb.WriteByte('\n')
 	}
 
 	// Pad remaining rows so height stays constant.
-	for i := end - start; i < rowsAvail; i++ {
+	for i := len(rows); i < rowsAvail; i++ {
 		sb.WriteByte('\n')
 	}
 
 	return sb.String()
 }
 
+// DetailInfo carries the asynchronous enrichment shown on the detail page.
+type DetailInfo struct {
+	// Whois is non-nil when a completed lookup is available; WhoisLoading is
+	// true while a lookup is in flight.
+	Whois        *whois.Result
+	WhoisLoading bool
+	// DstWhois and DstWhoisLoading are the same for the destination IP, for
+	// outbound traffic where it is the interesting party.
+	DstWhois        *whois.Result
+	DstWhoisLoading bool
+	// PTR is the reverse-DNS name of the source IP, "" if none is known;
+	// PTRLoading is true while the lookup is in flight.
+	PTR        string
+	PTRLoading bool
+	// ShowSource adds a File row naming e.Source, for multi-file sessions.
+	ShowSource bool
+	// Country and City come from the GeoIP database; both are "" when none
+	// is loaded or the address is not in it.
+	Country string
+	City    string
+	// Blocklist is the --blocklist network containing the source IP, ""
+	// when it is in none.
+	Blocklist string
+	// User is the login name of e.UID, "" when it is not logged or has no
+	// account on this host.
+	User string
+	// Anonymize masks the addresses, in the fields and the raw line, and
+	// leaves out what would give them away: the PTR name and whois subnet.
+	Anonymize bool
+}
+
 // RenderDetailPage renders a full-screen view of a single log entry.
 // It reads only the entry value passed in — it has no access to the live
 // filtered slice, so incoming log lines cannot affect what is displayed.
-// whoisInfo is non-nil when a completed lookup is available; loading is true
-// while a lookup is in-flight. Both are ignored for non-External source IPs.
-func RenderDetailPage(e parser.LogEntry, width, height int, whoisInfo *whois.Result, loading bool) string {
+// Enrichment in info is only populated for External source IPs.
+func RenderDetailPage(e parser.LogEntry, width, height int, info DetailInfo) string {
 	var sb strings.Builder
+	if info.Anonymize {
+		e = e.Anonymized()
+		info.PTR, info.PTRLoading = "", false
+		info.Blocklist = AnonymizePrefix(info.Blocklist)
+	}
 
 	// ── Header ──────────────────────────────────────────────────────────────
 	title := StyleLabel.Render("Entry Detail")
@@ -104,28 +257,79 @@ func RenderDetailPage(e parser.LogEntry, width, height int, whoisInfo *whois.Res
 	}
 
 	action := e.Action()
-	field("Timestamp", e.Timestamp.Format("2006-01-02 15:04:05"))
+	if info.ShowSource {
+		field("File", e.Source)
+	}
+	field("Timestamp", e.Timestamp.Format("2006-01-02 15:04:05")+" "+StyleMuted.Render("("+HumanAge(e.Timestamp)+")"))
+	if e.Repeats > 0 {
+		field("Repeated", fmt.Sprintf("%s from %s to %s (%s)", repeatLabel(e),
+			e.First.Format(time.TimeOnly), e.Timestamp.Format(time.TimeOnly),
+			e.Timestamp.Sub(e.First).Round(time.Second)))
+	}
 	field("Hostname", e.Hostname)
 	field("Prefix", e.Prefix)
 	field("Action", actionStyle(action).Bold(true).Render(action))
 	field("In", e.In)
 	field("Out", e.Out)
+	if dir := directionLabel(e.Direction()); dir != "" {
+		field("Direction", dir)
+	}
+	if mac := e.SrcMAC(); mac != "" {
+		field("Src MAC", mac)
+	}
+	if mac := e.DstMAC(); mac != "" {
+		field("Dst MAC", mac)
+	}
 	field("Src", e.Src)
+	if info.Blocklist != "" {
+		field("Blocklist", StyleDrop.Bold(true).Render("matched "+info.Blocklist))
+	}
+	switch {
+	case info.PTR != "":
+		field("PTR", info.PTR)
+	case info.PTRLoading:
+		field("PTR", StyleMuted.Render("resolving…"))
+	}
+	if info.Country != "" {
+		field("Country", info.Country)
+	}
+	if info.City != "" {
+		field("City", info.City)
+	}
 	field("Dst", e.Dst)
 	field("Proto", protoStyle(e.Proto).Render(e.Proto))
-	if e.SrcPort != 0 {
-		label := fmt.Sprintf("%d", e.SrcPort)
-		if name := ports.Lookup(e.SrcPort, e.Proto); name != "" {
-			label = fmt.Sprintf("%d (%s)", e.SrcPort, name)
+	port := func(k string, p int) {
+		if p == 0 {
+			return
+		}
+		label := fmt.Sprintf("%d", p)
+		if name := ports.Lookup(p, e.Proto); name != "" {
+			label = fmt.Sprintf("%d (%s)", p, name)
 		}
-		field("SrcPort", label)
+		field(k, label)
 	}
-	if e.DstPort != 0 {
-		label := fmt.Sprintf("%d", e.DstPort)
-		if name := ports.Lookup(e.DstPort, e.Proto); name != "" {
-			label = fmt.Sprintf("%d (%s)", e.DstPort, name)
+	// Each protocol shows the header fields that matter for it: TCP its
+	// flags and window first, ICMP its type and code instead of ports,
+	// which it never has.
+	switch e.Proto {
+	case "TCP":
+		if len(e.TCPFlags) > 0 {
+			field("Flags", StyleFilter.Bold(true).Render(strings.Join(e.TCPFlags, " ")))
 		}
-		field("DstPort", label)
+		if e.HasWindow {
+			field("Window", fmt.Sprintf("%d", e.Window))
+		}
+		port("SrcPort", e.SrcPort)
+		port("DstPort", e.DstPort)
+	case "ICMP", "ICMPv6":
+		if e.HasICMP {
+			field("ICMP", e.ICMPDescription())
+			field("Type", fmt.Sprintf("%d", e.ICMPType))
+			field("Code", fmt.Sprintf("%d", e.ICMPCode))
+		}
+	default:
+		port("SrcPort", e.SrcPort)
+		port("DstPort", e.DstPort)
 	}
 	if e.TTL != 0 {
 		field("TTL", fmt.Sprintf("%d", e.TTL))
@@ -133,6 +337,25 @@ func RenderDetailPage(e parser.LogEntry, width, height int, whoisInfo *whois.Res
 	if e.Len != 0 {
 		field("Len", fmt.Sprintf("%d", e.Len))
 	}
+	if e.ID != 0 {
+		field("ID", fmt.Sprintf("%d", e.ID))
+	}
+	if frag := e.FragmentLabel(); frag != "" {
+		field("Fragment", frag)
+	}
+	if e.UID != nil {
+		uid := fmt.Sprintf("%d", *e.UID)
+		if info.User != "" {
+			uid += " (" + info.User + ")"
+		}
+		field("UID", uid)
+	}
+	if e.GID != nil {
+		field("GID", fmt.Sprintf("%d", *e.GID))
+	}
+	if e.Mark != "" {
+		field("Mark", e.Mark)
+	}
 
 	// ── Raw line ────────────────────────────────────────────────────────────
 	sb.WriteByte('\n')
@@ -153,106 +376,332 @@ func RenderDetailPage(e parser.LogEntry, width, height int, whoisInfo *whois.Res
 		raw = raw[len(chunk):]
 	}
 
-	// ── Whois section (External IPs only) ──────────────────────────────────
-	if loading || whoisInfo != nil {
+	// ── Whois sections (External IPs only) ─────────────────────────────────
+	whoisBlock := func(title string, whoisInfo *whois.Result, loading bool) {
+		if !loading && whoisInfo == nil {
+			return
+		}
 		sb.WriteByte('\n')
-		sb.WriteString(strings.Repeat(" ", gutterWidth) + StyleLabel.Render("WHOIS (src)") + "\n")
+		sb.WriteString(strings.Repeat(" ", gutterWidth) + StyleLabel.Render(title) + "\n")
 		if loading {
 			sb.WriteString(strings.Repeat(" ", gutterWidth+2) + StyleMuted.Render("Looking up…") + "\n")
-		} else if whoisInfo != nil {
-			wfield := func(k, v string) {
-				if v == "" {
-					return
-				}
-				sb.WriteString(
-					strings.Repeat(" ", gutterWidth) +
-						StyleLabel.Render(fmt.Sprintf("%-11s", k+":")) +
-						" " + v + "\n",
-				)
+			return
+		}
+		wfield := func(k, v string) {
+			if v == "" {
+				return
 			}
+			sb.WriteString(
+				strings.Repeat(" ", gutterWidth) +
+					StyleLabel.Render(fmt.Sprintf("%-11s", k+":")) +
+					" " + v + "\n",
+			)
+		}
+		if !info.Anonymize {
 			wfield("Subnet", whoisInfo.Subnet)
-			wfield("NetName", whoisInfo.NetName)
-			wfield("ASN", whoisInfo.ASN)
-			wfield("Org", whoisInfo.Org)
 		}
+		wfield("NetName", whoisInfo.NetName)
+		wfield("ASN", whoisInfo.ASN)
+		wfield("Org", whoisInfo.Org)
+	}
+	whoisBlock("WHOIS (src)", info.Whois, info.WhoisLoading)
+	whoisBlock("WHOIS (dst)", info.DstWhois, info.DstWhoisLoading)
+
+	return fitPage(sb.String(), width, height)
+}
+
+// fitPage makes page, newline-terminated lines, exactly height rows of at
+// most width cells, so that every frame covers the same area as the table.
+// Bubble Tea v1.x uses a cell-by-cell diff renderer: any cell not written in
+// the current frame keeps whatever was rendered in the previous frame.
+// Without the padding, rows of the live log table that were visible before
+// the detail page opened bleed through below the detail content and keep
+// updating as the tailer appends entries. Without the clipping, a page that
+// no longer fits after the terminal shrinks (or a line the terminal wraps)
+// pushes the footer off screen.
+func fitPage(page string, width, height int) string {
+	lines := strings.SplitAfter(page, "\n")
+	if last := len(lines) - 1; lines[last] == "" {
+		lines = lines[:last]
+	}
+	var sb strings.Builder
+	for i := range max(height, 0) {
+		if i < len(lines) {
+			sb.WriteString(ansi.Truncate(strings.TrimSuffix(lines[i], "\n"), width, "…"))
+		}
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+// addrWidth returns the Src/Dst column width for a frame: colSrc normally, or
+// colAddr6 when any of the visible entries carries an IPv6 address, so that
+// IPv6 rows are not truncated while IPv4-only views stay compact.
+func addrWidth(visible []parser.LogEntry) int {
+	for _, e := range visible {
+		if strings.Contains(e.Src, ":") || strings.Contains(e.Dst, ":") {
+			return colAddr6
+		}
+	}
+	return colSrc
+}
+
+// dptWidth returns the DPT column width for a frame: colDPT, widened when a
+// visible service name would not fit, so scrolling right shows it in full.
+// The LEN/TTL region always keeps colDPT.
+func dptWidth(visible []parser.LogEntry, opts TableOptions) int {
+	w := colDPT
+	if opts.Columns == ColumnsLenTTL && !opts.Wide {
+		return w
+	}
+	for _, e := range visible {
+		w = max(w, ansi.StringWidth(portLabel(e.DstPort, e.Proto, opts.PortNumbers))+3)
+	}
+	return w
+}
+
+// sptWidth returns the SPT column width for a frame: 0 when the column is
+// off, else colSPT widened like dptWidth.
+func sptWidth(visible []parser.LogEntry, opts TableOptions) int {
+	if !opts.SrcPort {
+		return 0
 	}
+	w := colSPT
+	for _, e := range visible {
+		w = max(w, ansi.StringWidth(portLabel(e.SrcPort, e.Proto, opts.PortNumbers))+3)
+	}
+	return w
+}
 
-	// Pad to the full available height with blank lines.
-	// Bubble Tea v1.x uses a cell-by-cell diff renderer: any cell not written
-	// in the current frame keeps whatever was rendered in the previous frame.
-	// Without this, rows of the live log table that were visible before the
-	// detail page opened bleed through below the detail content and keep
-	// updating as the tailer appends entries.
-	out := sb.String()
-	written := strings.Count(out, "\n")
-	for written < height {
-		out += "\n"
-		written++
+// tableWidth returns the width in cells of a row without its gutter.
+func tableWidth(addrW, sptW, dptW int, opts TableOptions) int {
+	w := colTime + colDir + colIn + colAction + colProto + colCat + 2*addrW + sptW + dptW
+	if opts.Country != nil {
+		w += colCC
 	}
-	return out
+	if opts.Collapsed {
+		w += colRep
+	}
+	if opts.Wide {
+		w += colIn + colLen + colTTL
+	}
+	return w
+}
+
+// MaxHScroll returns the largest useful Offset for the frame RenderLogsTab
+// would draw with the same arguments: 0 when the table already fits.
+func MaxHScroll(entries []parser.LogEntry, cursor, width, height int, opts TableOptions) int {
+	opts = opts.forWidth(width)
+	start, end := entryRange(TableRows(entries, cursor, height, opts.Group))
+	visible := entries[start:end]
+	w := tableWidth(addrWidth(visible), sptWidth(visible, opts), dptWidth(visible, opts), opts)
+	return max(w-max(width-gutterWidth, 1), 0)
 }
 
 // renderHeader produces a styled column-header row (no gutter prefix).
-func renderHeader() string {
+// addrW is the width of the SRC and DST columns for this frame, sptW and dptW
+// those of the SPT (0 when hidden) and DPT columns.
+func renderHeader(addrW, sptW, dptW int, opts TableOptions) string {
 	style := lipgloss.NewStyle().Bold(true).Foreground(ColorHeader)
+	cc := ""
+	if opts.Country != nil {
+		cc = padCell("CC", colCC)
+	}
+	rep := ""
+	if opts.Collapsed {
+		rep = padCell("REP", colRep)
+	}
+	dst := "DST"
+	if opts.DstName != nil {
+		dst = "DST (DNS)"
+	}
+	out := ""
+	if opts.Wide {
+		out = padCell("OUT", colIn)
+	}
 	return style.Render(
 		padCell("TIME", colTime) +
+			rep +
+			padCell("DIR", colDir) +
 			padCell("IN", colIn) +
+			out +
 			padCell("ACTION", colAction) +
 			padCell("PROTO", colProto) +
 			padCell("CAT", colCat) +
-			padCell("SRC", colSrc) +
-			padCell("DST", colDst) +
-			padCell("DPT", colDPT),
+			cc +
+			padCell("SRC", addrW) +
+			padCell(dst, addrW) +
+			sptCell("SPT", sptW) +
+			tailCells("DPT", "LEN", "TTL", dptW, opts),
 	)
 }
 
-// portLabel returns the IANA service name for the port if known, else the port
+// portLabel returns the IANA service name for the port if known — after the
+// number, as in "22 (ssh)", when withNumber is set — else the port
 // number as a string. Returns "" for port 0 (not present in the log entry).
-func portLabel(port int, proto string) string {
+func portLabel(port int, proto string, withNumber bool) string {
 	if port == 0 {
 		return ""
 	}
 	if name := ports.Lookup(port, proto); name != "" {
+		if withNumber {
+			return fmt.Sprintf("%d (%s)", port, name)
+		}
 		return name
 	}
 	return fmt.Sprintf("%d", port)
 }
 
+// sptCell renders the SPT cell, or nothing when the column is hidden.
+func sptCell(spt string, sptW int) string {
+	if sptW == 0 {
+		return ""
+	}
+	return padCell(spt, sptW)
+}
+
+// tailCells renders the last column region: dpt in a dptW-wide cell, or
+// length and ttl side by side (always colDPT cells), depending on
+// opts.Columns; in wide mode, all three.
+func tailCells(dpt, length, ttl string, dptW int, opts TableOptions) string {
+	switch {
+	case opts.Wide:
+		return padCell(dpt, dptW) + padCell(length, colLen) + padCell(ttl, colTTL)
+	case opts.Columns == ColumnsLenTTL:
+		return padCell(length, colLen) + padCell(ttl, colTTL)
+	}
+	return padCell(dpt, dptW)
+}
+
+// HumanAge returns how long ago t was in a compact form: "42s ago", "3m ago",
+// "5h ago" or "2d ago". A t in the future (the logging host's clock is
+// ahead) or less than a second old is "just now".
+func HumanAge(t time.Time) string {
+	return humanAge(time.Since(t))
+}
+
+func humanAge(d time.Duration) string {
+	switch {
+	case d < time.Second:
+		return "just now"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds ago", int(d/time.Second))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(d/time.Hour))
+	}
+	return fmt.Sprintf("%dd ago", int(d/(24*time.Hour)))
+}
+
+// directionLabel returns a LogEntry.Direction with an arrow: "→ in",
+// "← out" or "⇄ fwd", and "" for an unknown direction.
+func directionLabel(dir string) string {
+	switch dir {
+	case "in":
+		return "→ in"
+	case "out":
+		return "← out"
+	case "fwd":
+		return "⇄ fwd"
+	}
+	return ""
+}
+
+// repeatLabel returns "x42" for a row that merged 42 entries, or "" for a
+// single entry.
+func repeatLabel(e parser.LogEntry) string {
+	if e.Repeats == 0 {
+		return ""
+	}
+	return fmt.Sprintf("x%d", e.Repeats+1)
+}
+
+// intLabel formats n, or "" for 0 (field not present in the log line).
+func intLabel(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d", n)
+}
+
 // renderDataRow renders a single log entry as a table row (no gutter prefix).
-func renderDataRow(e parser.LogEntry, selected bool, categorize func(string) string) string {
+func renderDataRow(e parser.LogEntry, selected bool, addrW, sptW, dptW int, opts TableOptions) string {
 	action := e.Action()
 	timeStr := e.Timestamp.Format(time.TimeOnly)
-	dpt := portLabel(e.DstPort, e.Proto)
-	cat := categorize(e.Src)
+	tail := sptCell(portLabel(e.SrcPort, e.Proto, opts.PortNumbers), sptW) + tailCells(portLabel(e.DstPort, e.Proto, opts.PortNumbers), intLabel(e.Len), intLabel(e.TTL), dptW, opts)
+	cat := opts.Categorize(e.Src)
+	src, dst := e.Src, e.Dst
+	switch {
+	case opts.Anonymize:
+		src, dst = parser.AnonymizeIP(src), parser.AnonymizeIP(dst)
+	case opts.DstName != nil:
+		if name := opts.DstName(e.Dst); name != "" {
+			dst = name
+		}
+	}
+	cc := ""
+	if opts.Country != nil {
+		cc = padCell(opts.Country(e.Src), colCC)
+	}
+	rep := ""
+	if opts.Collapsed {
+		rep = padCell(repeatLabel(e), colRep)
+	}
+	out := ""
+	if opts.Wide {
+		out = padCell(e.Out, colIn)
+	}
 
 	if selected {
 		return StyleSelected.Render(
-			padCell(timeStr, colTime) +
-				padCell(e.In, colIn) +
-				padCell(action, colAction) +
-				padCell(e.Proto, colProto) +
-				padCell(cat, colCat) +
-				padCell(e.Src, colSrc) +
-				padCell(e.Dst, colDst) +
-				padCell(dpt, colDPT),
-		)
+			padCell(timeStr, colTime)+
+				rep+
+				padCell(directionLabel(e.Direction()), colDir)+
+				padCell(e.In, colIn)+
+				out+
+				padCell(action, colAction)+
+				padCell(e.Proto, colProto)+
+				padCell(cat, colCat)+
+				cc) +
+			highlightCell(src, addrW, opts.matchSpans(src), StyleSelected) +
+			highlightCell(dst, addrW, opts.matchSpans(dst), StyleSelected) +
+			StyleSelected.Render(tail)
+	}
+
+	if opts.DimAccept && action == "ACCEPT" {
+		return StyleMuted.Render(
+			padCell(timeStr, colTime)+
+				rep+
+				padCell(directionLabel(e.Direction()), colDir)+
+				padCell(e.In, colIn)+
+				out+
+				padCell(action, colAction)+
+				padCell(e.Proto, colProto)+
+				padCell(cat, colCat)+
+				cc) +
+			highlightCell(src, addrW, opts.matchSpans(src), StyleMuted) +
+			highlightCell(dst, addrW, opts.matchSpans(dst), StyleMuted) +
+			StyleMuted.Render(tail)
 	}
 
 	timeSt := lipgloss.NewStyle().Foreground(ColorMuted)
 	actionSt := actionStyle(action)
 	protoSt := protoStyle(e.Proto)
 	addrSt := lipgloss.NewStyle()
-	portSt := lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+	portSt := lipgloss.NewStyle().Foreground(ColorText)
 
 	return timeSt.Render(padCell(timeStr, colTime)) +
-		StyleMuted.Render(padCell(e.In, colIn)) +
+		StyleStatValue.Render(rep) +
+		StyleMuted.Render(padCell(directionLabel(e.Direction()), colDir)) +
+		StyleMuted.Render(padCell(e.In, colIn)+out) +
 		actionSt.Render(padCell(action, colAction)) +
 		protoSt.Render(padCell(e.Proto, colProto)) +
 		catStyle(cat).Render(padCell(cat, colCat)) +
-		addrSt.Render(padCell(e.Src, colSrc)) +
-		addrSt.Render(padCell(e.Dst, colDst)) +
-		portSt.Render(padCell(dpt, colDPT))
+		StyleStatLabel.Render(cc) +
+		highlightCell(src, addrW, opts.matchSpans(src), addrSt) +
+		highlightCell(dst, addrW, opts.matchSpans(dst), addrSt) +
+		portSt.Render(tail)
 }
 
 // catStyle returns the foreground style for an IP category string.
@@ -260,6 +709,8 @@ func catStyle(cat string) lipgloss.Style {
 	switch cat {
 	case classifier.CatInternal:
 		return lipgloss.NewStyle().Foreground(ColorStats)
+	case classifier.CatPrivate:
+		return lipgloss.NewStyle().Foreground(ColorText)
 	case classifier.CatMulticast:
 		return lipgloss.NewStyle().Foreground(ColorICMP)
 	default:
@@ -281,18 +732,158 @@ func actionStyle(action string) lipgloss.Style {
 
 // protoStyle returns the foreground style for a protocol string.
 func protoStyle(proto string) lipgloss.Style {
-	if proto == "ICMP" {
+	if IsICMP(proto) {
 		return StyleICMP
 	}
-	return lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+	return lipgloss.NewStyle().Foreground(ColorText)
 }
 
 // padCell left-aligns s within exactly w terminal cells, truncating if needed.
+// Widths are measured in cells, so multi-byte and double-width text lines up.
 func padCell(s string, w int) string {
-	if len(s) > w-1 {
-		s = s[:w-4] + "…"
+	s = fitCell(s, w)
+	return s + strings.Repeat(" ", max(w-ansi.StringWidth(s), 0))
+}
+
+// fitCell returns s cut on a character boundary to at most w-1 cells, so at
+// least one cell of spacing follows it, with "…" marking the cut.
+func fitCell(s string, w int) string {
+	if ansi.StringWidth(s) <= w-1 {
+		return s
+	}
+	return ansi.Truncate(s, max(w-1, 0), "…")
+}
+
+// matchSpans returns the byte ranges of s to highlight: every match of
+// MatchRe when set, otherwise every case-insensitive occurrence of Match.
+func (o TableOptions) matchSpans(s string) [][]int {
+	if o.MatchRe != nil {
+		return o.MatchRe.FindAllStringIndex(s, -1)
 	}
-	return fmt.Sprintf("%-*s", w, s)
+	if o.Match == "" {
+		return nil
+	}
+	var spans [][]int
+	lower, sub := strings.ToLower(s), strings.ToLower(o.Match)
+	for pos := 0; ; {
+		i := strings.Index(lower[pos:], sub)
+		if i < 0 {
+			return spans
+		}
+		spans = append(spans, []int{pos + i, pos + i + len(sub)})
+		pos += i + len(sub)
+	}
+}
+
+// highlightCell renders s padded to w cells like padCell, with the byte
+// ranges in spans (ordered, non-overlapping) shown in reverse video. A span
+// that runs into the truncation point is highlighted only up to the
+// ellipsis, so the cell width is unchanged.
+func highlightCell(s string, w int, spans [][]int, base lipgloss.Style) string {
+	cell := padCell(s, w)
+	if len(spans) == 0 {
+		return base.Render(cell)
+	}
+	visible := len(s)
+	if fit := fitCell(s, w); fit != s {
+		visible = len(fit) - len("…")
+	}
+	hl := base.Reverse(true)
+	var sb strings.Builder
+	pos := 0
+	for _, sp := range spans {
+		start, end := sp[0], min(sp[1], visible)
+		if start >= visible {
+			break
+		}
+		if end <= start {
+			continue // empty regex match
+		}
+		sb.WriteString(base.Render(cell[pos:start]))
+		sb.WriteString(hl.Render(cell[start:end]))
+		pos = end
+	}
+	if pos < len(cell) {
+		sb.WriteString(base.Render(cell[pos:]))
+	}
+	return sb.String()
+}
+
+// VisibleRows returns the half-open range of entry indices RenderLogsTab
+// shows for the given total, cursor and height.
+func VisibleRows(total, cursor, height int) (start, end int) {
+	rowsAvail := height - 4
+	if rowsAvail < 1 {
+		rowsAvail = 1
+	}
+	start = scrollStart(total, cursor, rowsAvail)
+	return start, min(start+rowsAvail, total)
+}
+
+// TableRows lays out the body of the log table: for each screen row from the
+// top, the index of the entry shown there, or -1 for a separator. Without
+// grouping it is simply the VisibleRows range. With it, a separator precedes
+// every entry whose timestamp falls in a different group-length bucket from
+// the entry before it (except the top row), and the window slides further
+// down until the cursor fits below the separators.
+func TableRows(entries []parser.LogEntry, cursor, height int, group time.Duration) []int {
+	rowsAvail := max(height-4, 1)
+	start, _ := VisibleRows(len(entries), cursor, height)
+	for {
+		rows := layoutRows(entries, start, rowsAvail, group)
+		if start >= cursor || len(rows) == 0 || rows[len(rows)-1] >= cursor {
+			return rows
+		}
+		start++
+	}
+}
+
+// layoutRows fills up to n screen rows with entries from start on.
+func layoutRows(entries []parser.LogEntry, start, n int, group time.Duration) []int {
+	rows := make([]int, 0, n)
+	for i := start; i < len(entries) && len(rows) < n; i++ {
+		if group > 0 && i > start && newBucket(entries[i-1].Timestamp, entries[i].Timestamp, group) {
+			if len(rows)+2 > n {
+				break // no room for the entry after the separator
+			}
+			rows = append(rows, -1)
+		}
+		rows = append(rows, i)
+	}
+	return rows
+}
+
+// newBucket reports whether b falls in a different group-length bucket from
+// a. Entries without a timestamp never start one.
+func newBucket(a, b time.Time, group time.Duration) bool {
+	if a.IsZero() || b.IsZero() {
+		return false
+	}
+	return !a.Truncate(group).Equal(b.Truncate(group))
+}
+
+// entryRange returns the half-open range of entry indices in rows.
+func entryRange(rows []int) (start, end int) {
+	for _, i := range rows {
+		if i >= 0 {
+			if end == 0 {
+				start = i
+			}
+			end = i + 1
+		}
+	}
+	return start, end
+}
+
+// separatorRow renders the rule above the first entry of bucket t, labelled
+// with its start, e.g. "── 2024-01-15 10:42 ────".
+func separatorRow(t time.Time, group time.Duration, width int) string {
+	label := t.Format("2006-01-02 15:04")
+	if group >= time.Hour {
+		label = t.Format("2006-01-02 15:00")
+	}
+	label = "── " + label + " "
+	return StyleMuted.Render(label + strings.Repeat("─", max(width-ansi.StringWidth(label), 0)))
 }
 
 // scrollStart returns the first visible row index.
@@ -309,6 +900,3 @@ func scrollStart(total, cursor, rowsAvail int) int {
 	}
 	return start
 }
-
-// StyleMuted re-exports for use within this package.
-var StyleMuted = lipgloss.NewStyle().Foreground(ColorMuted)
@@ -0,0 +1,506 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
+	"github.com/espenotterstad/iptables-log-tui/internal/whois"
+	"github.com/muesli/termenv"
+)
+
+func TestPadCell(t *testing.T) {
+	tests := []struct {
+		name, s string
+		w       int
+		want    string
+	}{
+		{"ascii", "TCP", 6, "TCP   "},
+		{"ascii cut", "203.0.113.250", 8, "203.0.… "},
+		{"multi-byte", "bücher.de", 12, "bücher.de   "},
+		{"multi-byte cut", "müller-straße.de", 8, "müller… "},
+		{"arrow", "⇄ fwd", 8, "⇄ fwd   "},
+		{"wide", "例え", 6, "例え  "},
+		{"wide cut", "例え例え例え", 8, "例え例… "},
+		{"wide cut, padded", "例え例え例え", 9, "例え例…  "},
+		{"wide cut, exact fit", "例え例え例え", 10, "例え例え… "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := padCell(tt.s, tt.w)
+			if got != tt.want {
+				t.Errorf("padCell(%q, %d) = %q, want %q", tt.s, tt.w, got, tt.want)
+			}
+			if w := lipgloss.Width(got); w != tt.w {
+				t.Errorf("width = %d, want %d", w, tt.w)
+			}
+		})
+	}
+}
+
+func TestMatchSpans(t *testing.T) {
+	got := TableOptions{Match: "10"}.matchSpans("10.10.0.1")
+	if len(got) != 2 || got[0][0] != 0 || got[1][0] != 3 {
+		t.Errorf("substring spans = %v, want [[0 2] [3 5]]", got)
+	}
+	re := regexp.MustCompile(`\.0\.`)
+	got = TableOptions{Match: "ignored", MatchRe: re}.matchSpans("10.0.0.1")
+	if len(got) != 1 || got[0][0] != 2 || got[0][1] != 5 {
+		t.Errorf("regex spans = %v, want [[2 5]]", got)
+	}
+}
+
+func TestHighlightCellKeepsWidth(t *testing.T) {
+	tests := []struct {
+		name, s, match string
+		w              int
+	}{
+		{"no match", "10.0.0.1", "192", colSrc},
+		{"single", "192.168.1.10", "168", colSrc},
+		{"repeated", "10.10.10.10", "10", colSrc},
+		{"case-insensitive", "2001:DB8::1", "db8", colAddr6},
+		{"across truncation", "2001:0db8:85a3:0000:0000:8a2e:0370:7334", "0370", colSrc},
+		{"after truncation", "2001:0db8:85a3:0000:0000:8a2e:0370:7334", "7334", colSrc},
+		{"multi-byte", "bücher.例え.jp", "例え", colSrc},
+		{"wide across truncation", "例え例え例え例え例え.jp", "例え", 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := highlightCell(tt.s, tt.w, TableOptions{Match: tt.match}.matchSpans(tt.s), lipgloss.NewStyle())
+			if w := lipgloss.Width(got); w != tt.w {
+				t.Errorf("width = %d, want %d", w, tt.w)
+			}
+			if plain := ansi.Strip(got); plain != padCell(tt.s, tt.w) {
+				t.Errorf("text = %q, want %q", plain, padCell(tt.s, tt.w))
+			}
+		})
+	}
+}
+
+func TestRenderLogsTabHorizontalScroll(t *testing.T) {
+	entries := []parser.LogEntry{{
+		Proto: "TCP", Src: "2001:db8::1", Dst: "2001:db8::2", DstPort: 22,
+	}}
+	opts := TableOptions{Categorize: func(string) string { return "External" }}
+	const width, height = 80, 10
+
+	limit := MaxHScroll(entries, 0, width, height, opts)
+	if limit <= 0 {
+		t.Fatalf("MaxHScroll = %d, want > 0 for an IPv6 row on 80 columns", limit)
+	}
+	opts.Offset = 1 << 20 // clamped to limit
+	lines := strings.Split(ansi.Strip(RenderLogsTab(entries, 0, width, height, opts)), "\n")
+	header, row := lines[0], lines[2]
+	if ansi.StringWidth(header) > width || ansi.StringWidth(row) > width {
+		t.Errorf("scrolled lines wider than %d: %q / %q", width, header, row)
+	}
+	// Scrolled fully right, DPT is the last column of both header and row.
+	if !strings.Contains(header, "DPT") || !strings.Contains(row, "ssh") {
+		t.Errorf("header %q / row %q do not show the DPT column", header, row)
+	}
+	if strings.Contains(header, "TIME") {
+		t.Errorf("header %q did not scroll", header)
+	}
+	col := func(line, s string) int { return ansi.StringWidth(line[:strings.Index(line, s)]) }
+	if col(header, "DPT") != col(row, "ssh") {
+		t.Errorf("header and row out of step:\n%q\n%q", header, row)
+	}
+}
+
+func TestRenderLogsTabSrcPort(t *testing.T) {
+	entries := []parser.LogEntry{{
+		Proto: "TCP", Src: "192.0.2.1", Dst: "192.0.2.2", SrcPort: 51234, DstPort: 443,
+	}}
+	opts := TableOptions{Categorize: func(string) string { return "External" }}
+	const width, height = 200, 10
+
+	lines := strings.Split(ansi.Strip(RenderLogsTab(entries, 0, width, height, opts)), "\n")
+	if strings.Contains(lines[0], "SPT") || strings.Contains(lines[2], "51234") {
+		t.Errorf("SPT shown while off:\n%q\n%q", lines[0], lines[2])
+	}
+
+	opts.SrcPort = true
+	lines = strings.Split(ansi.Strip(RenderLogsTab(entries, 0, width, height, opts)), "\n")
+	header, row := lines[0], lines[2]
+	if !strings.Contains(header, "SPT") || !strings.Contains(row, "51234") {
+		t.Fatalf("SPT missing while on:\n%q\n%q", header, row)
+	}
+	col := func(line, s string) int { return ansi.StringWidth(line[:strings.Index(line, s)]) }
+	if col(header, "SPT") != col(row, "51234") || col(header, "DPT") != col(row, "https") {
+		t.Errorf("header and row out of step:\n%q\n%q", header, row)
+	}
+	if col(header, "SPT") > col(header, "DPT") {
+		t.Errorf("SPT should come before DPT: %q", header)
+	}
+}
+
+func TestRenderLogsTabWide(t *testing.T) {
+	entries := []parser.LogEntry{{
+		Proto: "TCP", In: "eth0", Out: "ppp0", Src: "192.0.2.1", Dst: "192.0.2.2",
+		SrcPort: 51234, DstPort: 443, Len: 60, TTL: 63,
+	}}
+	opts := TableOptions{Categorize: func(string) string { return "External" }, Wide: true}
+
+	lines := strings.Split(ansi.Strip(RenderLogsTab(entries, 0, WideMinWidth-1, 10, opts)), "\n")
+	for _, col := range []string{"OUT", "SPT", "LEN", "TTL"} {
+		if strings.Contains(lines[0], col) {
+			t.Errorf("narrow terminal shows %s: %q", col, lines[0])
+		}
+	}
+
+	lines = strings.Split(ansi.Strip(RenderLogsTab(entries, 0, 200, 10, opts)), "\n")
+	header, row := lines[0], lines[2]
+	col := func(line, s string) int {
+		i := strings.Index(line, s)
+		if i < 0 {
+			t.Fatalf("%q not in %q", s, line)
+		}
+		return ansi.StringWidth(line[:i])
+	}
+	for _, c := range []struct{ head, cell string }{
+		{"IN", "eth0"}, {"OUT", "ppp0"}, {"SPT", "51234"}, {"DPT", "443 (https)"}, {"LEN", "60"}, {"TTL", "63"},
+	} {
+		if col(header, c.head) != col(row, c.cell) {
+			t.Errorf("%s and %q out of step:\n%q\n%q", c.head, c.cell, header, row)
+		}
+	}
+	if MaxHScroll(entries, 0, 200, 10, opts) != 0 {
+		t.Error("wide table does not fit 200 columns")
+	}
+}
+
+func TestRenderLogsTabBlocklistMarker(t *testing.T) {
+	entries := []parser.LogEntry{
+		{Proto: "TCP", Src: "203.0.113.5", Dst: "192.0.2.2"},
+		{Proto: "TCP", Src: "198.51.100.9", Dst: "192.0.2.2"},
+		{Proto: "TCP", Src: "192.0.2.77", Dst: "192.0.2.2"},
+		{Proto: "TCP", Src: "192.0.2.78", Dst: "192.0.2.2"},
+	}
+	opts := TableOptions{
+		Categorize:    func(string) string { return "External" },
+		Noisy:         func(ip string) bool { return ip == "203.0.113.5" || ip == "198.51.100.9" },
+		Blocklisted:   func(ip string) bool { return ip == "203.0.113.5" },
+		KnownOffender: func(string) bool { return true },
+	}
+	lines := strings.Split(ansi.Strip(RenderLogsTab(entries, 2, 120, 10, opts)), "\n")
+	for i, want := range []string{"*", "!", "▶~", "~"} {
+		if got := lines[2+i]; !strings.HasPrefix(got, want) {
+			t.Errorf("row %d = %q, want gutter %q", i, got, want)
+		}
+	}
+
+	out := ansi.Strip(RenderDetailPage(entries[0], 80, 40, DetailInfo{Blocklist: "203.0.113.0/24"}))
+	if !strings.Contains(out, "Blocklist:  matched 203.0.113.0/24") {
+		t.Errorf("detail page missing blocklist row:\n%s", out)
+	}
+	if out := ansi.Strip(RenderDetailPage(entries[1], 80, 40, DetailInfo{})); strings.Contains(out, "Blocklist") {
+		t.Error("blocklist row shown without a match")
+	}
+}
+
+func TestRenderCollapsedRow(t *testing.T) {
+	t0 := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	e := parser.LogEntry{Timestamp: t0.Add(8 * time.Second), First: t0, Repeats: 41, Proto: "TCP", Src: "203.0.113.5", Dst: "192.0.2.2"}
+	opts := TableOptions{Categorize: func(string) string { return "External" }, Collapsed: true}
+	lines := strings.Split(ansi.Strip(RenderLogsTab([]parser.LogEntry{e}, 0, 160, 10, opts)), "\n")
+	if !strings.Contains(lines[0], "REP") || !strings.Contains(lines[2], "x42") {
+		t.Errorf("REP column missing:\n%q\n%q", lines[0], lines[2])
+	}
+
+	out := ansi.Strip(RenderDetailPage(e, 80, 40, DetailInfo{}))
+	if !strings.Contains(out, "Repeated:   x42 from 03:04:05 to 03:04:13 (8s)") {
+		t.Errorf("detail page missing the repeat span:\n%s", out)
+	}
+}
+
+func TestRenderLogsTabDirection(t *testing.T) {
+	entries := []parser.LogEntry{
+		{Proto: "TCP", In: "eth0", Src: "203.0.113.5", Dst: "192.0.2.2"},
+		{Proto: "TCP", Out: "eth0", Src: "192.0.2.2", Dst: "203.0.113.5"},
+		{Proto: "TCP", In: "eth0", Out: "eth1", Src: "203.0.113.5", Dst: "10.0.0.2"},
+	}
+	opts := TableOptions{Categorize: func(string) string { return "External" }}
+	lines := strings.Split(ansi.Strip(RenderLogsTab(entries, 0, 160, 10, opts)), "\n")
+	if !strings.Contains(lines[0], "DIR") {
+		t.Errorf("header %q has no DIR column", lines[0])
+	}
+	for i, want := range []string{"→ in", "← out", "⇄ fwd"} {
+		if !strings.Contains(lines[2+i], want) {
+			t.Errorf("row %d = %q, want %q", i, lines[2+i], want)
+		}
+	}
+}
+
+func TestHumanAge(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{-5 * time.Minute, "just now"},
+		{500 * time.Millisecond, "just now"},
+		{42 * time.Second, "42s ago"},
+		{3*time.Minute + 59*time.Second, "3m ago"},
+		{5 * time.Hour, "5h ago"},
+		{50 * time.Hour, "2d ago"},
+	}
+	for _, tt := range tests {
+		if got := humanAge(tt.d); got != tt.want {
+			t.Errorf("humanAge(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
+
+func TestRenderDetailPageByProtocol(t *testing.T) {
+	tcp := parser.LogEntry{Proto: "TCP", Src: "203.0.113.5", SrcPort: 40000, DstPort: 22, TCPFlags: []string{"SYN"}, Window: 64240, HasWindow: true}
+	out := ansi.Strip(RenderDetailPage(tcp, 80, 40, DetailInfo{}))
+	for _, want := range []string{"Flags:", "SYN", "Window:", "64240", "DstPort:"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("TCP detail missing %q", want)
+		}
+	}
+
+	tcp.Window = 0 // a zero-window probe still shows its window
+	if out := ansi.Strip(RenderDetailPage(tcp, 80, 40, DetailInfo{})); !strings.Contains(out, "Window:     0\n") {
+		t.Error("zero window not shown")
+	}
+	tcp.HasWindow = false
+	if out := ansi.Strip(RenderDetailPage(tcp, 80, 40, DetailInfo{})); strings.Contains(out, "Window:") {
+		t.Error("absent window shown")
+	}
+
+	icmp := parser.LogEntry{Proto: "ICMP", Src: "203.0.113.5", ICMPType: 8, HasICMP: true}
+	out = ansi.Strip(RenderDetailPage(icmp, 80, 40, DetailInfo{}))
+	for _, want := range []string{"Type:", "Code:", "ICMP:"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("ICMP detail missing %q", want)
+		}
+	}
+	for _, unwanted := range []string{"SrcPort:", "DstPort:", "Flags:", "Window:"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("ICMP detail shows %q", unwanted)
+		}
+	}
+}
+
+func TestTableRowsSeparators(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	var entries []parser.LogEntry
+	for _, sec := range []int{0, 30, 61, 62, 125, 3700} {
+		entries = append(entries, parser.LogEntry{Timestamp: base.Add(time.Duration(sec) * time.Second)})
+	}
+
+	if got := TableRows(entries, 0, 20, 0); !slices.Equal(got, []int{0, 1, 2, 3, 4, 5}) {
+		t.Errorf("no grouping: %v", got)
+	}
+	if got := TableRows(entries, 0, 20, time.Minute); !slices.Equal(got, []int{0, 1, -1, 2, 3, -1, 4, -1, 5}) {
+		t.Errorf("by minute: %v", got)
+	}
+	if got := TableRows(entries, 0, 20, time.Hour); !slices.Equal(got, []int{0, 1, 2, 3, 4, -1, 5}) {
+		t.Errorf("by hour: %v", got)
+	}
+
+	// Four body rows: the window slides so the cursor stays on screen, and
+	// no separator is left dangling at the bottom.
+	if got := TableRows(entries, 5, 8, time.Minute); !slices.Equal(got, []int{4, -1, 5}) {
+		t.Errorf("cursor on the last entry: %v", got)
+	}
+	if got := TableRows(entries, 2, 8, time.Minute); !slices.Equal(got, []int{2, 3, -1, 4}) {
+		t.Errorf("cursor mid-way: %v", got)
+	}
+
+	out := ansi.Strip(RenderLogsTab(entries, 0, 100, 20, TableOptions{Group: time.Minute, Categorize: func(string) string { return "External" }}))
+	if !strings.Contains(out, "── 2024-01-15 10:01 ") || !strings.Contains(out, "── 2024-01-15 11:01 ") {
+		t.Errorf("separators missing:\n%s", out)
+	}
+}
+
+func TestPortLabel(t *testing.T) {
+	tests := []struct {
+		port       int
+		withNumber bool
+		want       string
+	}{
+		{22, false, "ssh"},
+		{22, true, "22 (ssh)"},
+		{54321, false, "54321"},
+		{54321, true, "54321"},
+		{0, true, ""},
+	}
+	for _, tt := range tests {
+		if got := portLabel(tt.port, "TCP", tt.withNumber); got != tt.want {
+			t.Errorf("portLabel(%d, %v) = %q, want %q", tt.port, tt.withNumber, got, tt.want)
+		}
+	}
+}
+
+func TestRenderDimmedAcceptRow(t *testing.T) {
+	prev := lipgloss.ColorProfile()
+	t.Cleanup(func() { lipgloss.SetColorProfile(prev) })
+	lipgloss.SetColorProfile(termenv.TrueColor)
+
+	accept := parser.LogEntry{Prefix: "ACCEPT", Proto: "TCP", Src: "203.0.113.5", Dst: "192.0.2.2", DstPort: 443}
+	drop := accept
+	drop.Prefix = "DROP"
+	opts := TableOptions{Categorize: func(string) string { return "External" }}
+	dim := opts
+	dim.DimAccept = true
+
+	row := func(e parser.LogEntry, selected bool, o TableOptions) string {
+		return renderDataRow(e, selected, 16, 0, 8, o)
+	}
+	plain, dimmed := row(accept, false, opts), row(accept, false, dim)
+	if plain == dimmed {
+		t.Error("DimAccept did not change the ACCEPT row")
+	}
+	if ansi.Strip(plain) != ansi.Strip(dimmed) {
+		t.Errorf("dimmed text differs:\n%q\n%q", ansi.Strip(plain), ansi.Strip(dimmed))
+	}
+	if row(drop, false, opts) != row(drop, false, dim) {
+		t.Error("DimAccept changed a DROP row")
+	}
+	if row(accept, true, opts) != row(accept, true, dim) {
+		t.Error("DimAccept changed the selected row")
+	}
+}
+
+func TestRowFieldsKeepColumnWidths(t *testing.T) {
+	entries := []parser.LogEntry{
+		{Prefix: "RATE-LIMIT-EXCEEDED", In: "wireguard-tunnel0", Proto: "TCP", Src: "2001:0db8:85a3:0000:0000:8a2e:0370:7334", Dst: "2001:db8::1", DstPort: 3389},
+		{Prefix: "DROP", In: "eth0", Proto: "UDP", Src: "203.0.113.250", Dst: "192.0.2.2", DstPort: 53},
+	}
+	names := map[string]string{
+		"2001:db8::1": "a-very-long-hostname.bücher.例え.example.com",
+		"192.0.2.2":   "例え例え例え例え例え例え例え例え例え例え.jp",
+	}
+	opts := TableOptions{
+		Categorize: func(string) string { return "External" },
+		DstName:    func(ip string) string { return names[ip] },
+	}
+	addrW, dptW := addrWidth(entries), dptWidth(entries, opts)
+	widths := []int{colTime, colDir, colIn, colAction, colProto, colCat, addrW, addrW, dptW}
+
+	check := func(name, row string) {
+		t.Helper()
+		plain := ansi.Strip(row)
+		if w := lipgloss.Width(row); w != tableWidth(addrW, 0, dptW, opts) {
+			t.Errorf("%s: width = %d, want %d", name, w, tableWidth(addrW, 0, dptW, opts))
+		}
+		pos := 0
+		for i, w := range widths {
+			field := ansi.Cut(plain, pos, pos+w)
+			if got := lipgloss.Width(field); got != w || !strings.HasSuffix(field, " ") {
+				t.Errorf("%s: field %d = %q, width %d, want %d ending in a space", name, i, field, got, w)
+			}
+			pos += w
+		}
+	}
+	check("header", renderHeader(addrW, 0, dptW, opts))
+	for i, e := range entries {
+		check(fmt.Sprintf("row %d", i), renderDataRow(e, false, addrW, 0, dptW, opts))
+		check(fmt.Sprintf("selected row %d", i), renderDataRow(e, true, addrW, 0, dptW, opts))
+	}
+}
+
+func TestRenderDetailPageOwnerAndMark(t *testing.T) {
+	uid, gid := 0, 33
+	e := parser.LogEntry{Proto: "UDP", Src: "10.0.0.5", Dst: "203.0.113.9", UID: &uid, GID: &gid, Mark: "0x64"}
+	out := ansi.Strip(RenderDetailPage(e, 80, 40, DetailInfo{User: "root"}))
+	for _, want := range []string{"UID:        0 (root)\n", "GID:        33\n", "Mark:       0x64\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("detail missing %q", want)
+		}
+	}
+
+	e.UID, e.GID, e.Mark = nil, nil, ""
+	if out := ansi.Strip(RenderDetailPage(e, 80, 40, DetailInfo{})); strings.Contains(out, "UID:") || strings.Contains(out, "GID:") || strings.Contains(out, "Mark:") {
+		t.Error("owner or mark shown for a packet without them")
+	}
+}
+
+func TestRenderDetailPageIPID(t *testing.T) {
+	e := parser.LogEntry{Proto: "UDP", Src: "203.0.113.4", Dst: "192.168.1.1", ID: 5120, MoreFragments: true, FragOffset: 185}
+	out := ansi.Strip(RenderDetailPage(e, 80, 40, DetailInfo{}))
+	id, frag := strings.Index(out, "ID:         5120\n"), strings.Index(out, "Fragment:   MF, offset 185\n")
+	if id < 0 || frag < id {
+		t.Errorf("detail missing the ID before the fragment row:\n%s", out)
+	}
+	e.ID = 0
+	if out := ansi.Strip(RenderDetailPage(e, 80, 40, DetailInfo{})); strings.Contains(out, "ID:") {
+		t.Error("ID shown for a packet without one")
+	}
+}
+
+func TestRenderAnonymized(t *testing.T) {
+	e := parser.LogEntry{Proto: "TCP", Prefix: "DROP", Src: "203.0.113.5", Dst: "192.168.1.1", DstPort: 22,
+		Raw: "kernel: [DROP] SRC=203.0.113.5 DST=192.168.1.1 PROTO=TCP DPT=22"}
+	opts := TableOptions{
+		Categorize: func(string) string { return "External" },
+		DstName:    func(string) string { return "gw.example.net" },
+		Anonymize:  true,
+	}
+	out := ansi.Strip(RenderLogsTab([]parser.LogEntry{e}, 0, 140, 10, opts))
+	if !strings.Contains(out, "203.0.x.x") || !strings.Contains(out, "192.168.x.x") {
+		t.Errorf("table does not mask the addresses:\n%s", out)
+	}
+	if strings.Contains(out, "203.0.113.5") || strings.Contains(out, "gw.example.net") {
+		t.Errorf("table gives an address away:\n%s", out)
+	}
+
+	info := DetailInfo{PTR: "scanner.example.net", Blocklist: "203.0.113.0/24", Whois: &whois.Result{Subnet: "203.0.113.0 - 203.0.113.255", Org: "Example"}, Anonymize: true}
+	out = ansi.Strip(RenderDetailPage(e, 100, 40, info))
+	for _, leaked := range []string{"203.0.113", "192.168.1.1", "scanner.example.net", "Subnet:"} {
+		if strings.Contains(out, leaked) {
+			t.Errorf("detail page shows %q", leaked)
+		}
+	}
+	for _, want := range []string{"SRC=203.0.x.x DST=192.168.x.x", "matched 203.0.x.x/24", "Example"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("detail page missing %q", want)
+		}
+	}
+}
+
+func TestRenderDetailPageDstWhois(t *testing.T) {
+	e := parser.LogEntry{Proto: "TCP", Src: "192.168.1.10", Dst: "198.51.100.7", DstPort: 443, Raw: "OUT=eth0"}
+	info := DetailInfo{DstWhois: &whois.Result{NetName: "EXAMPLE-CDN", Org: "Example CDN"}}
+	out := ansi.Strip(RenderDetailPage(e, 80, 40, info))
+	if strings.Contains(out, "WHOIS (src)") || !strings.Contains(out, "WHOIS (dst)") || !strings.Contains(out, "NetName:    EXAMPLE-CDN") {
+		t.Errorf("destination whois block:\n%s", out)
+	}
+
+	info.Whois, info.DstWhois, info.DstWhoisLoading = &whois.Result{Org: "Example ISP"}, nil, true
+	out = ansi.Strip(RenderDetailPage(e, 80, 40, info))
+	src, dst := strings.Index(out, "WHOIS (src)"), strings.Index(out, "WHOIS (dst)")
+	if src < 0 || dst < src || !strings.Contains(out[dst:], "Looking up…") {
+		t.Errorf("want the source block, then the destination one loading:\n%s", out)
+	}
+}
+
+func TestRenderDetailPageFitsEachSize(t *testing.T) {
+	e := parser.LogEntry{
+		Timestamp: time.Now(), Prefix: "DROP", In: "eth0", Proto: "TCP", Src: "203.0.113.5", Dst: "192.168.1.1",
+		SrcPort: 40000, DstPort: 22, Raw: strings.Repeat("IN=eth0 SRC=203.0.113.5 ", 12),
+	}
+	info := DetailInfo{Whois: &whois.Result{NetName: "EXAMPLE-NET", Org: strings.Repeat("Example Organisation ", 6)}}
+	for _, size := range []struct{ w, h int }{{120, 60}, {50, 12}} {
+		out := RenderDetailPage(e, size.w, size.h, info)
+		lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+		if len(lines) != size.h {
+			t.Errorf("%dx%d: %d rows, want %d", size.w, size.h, len(lines), size.h)
+		}
+		for i, l := range lines {
+			if w := ansi.StringWidth(l); w > size.w {
+				t.Errorf("%dx%d: row %d is %d cells wide", size.w, size.h, i, w)
+			}
+		}
+		if !strings.Contains(lines[0], "Entry Detail") {
+			t.Errorf("%dx%d: header cut off, first row %q", size.w, size.h, ansi.Strip(lines[0]))
+		}
+	}
+}
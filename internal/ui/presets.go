@@ -0,0 +1,164 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Preset is a named set of filters saved with SavePreset.
+type Preset struct {
+	Name    string
+	Filters Filters
+}
+
+// savedFilters is the on-disk form of Filters; the regex is kept as its
+// source text.
+type savedFilters struct {
+	Action       []string `json:"action,omitempty"`
+	Proto        []string `json:"proto,omitempty"`
+	Category     string   `json:"category,omitempty"`
+	Direction    string   `json:"direction,omitempty"`
+	IPSubstr     string   `json:"ip,omitempty"`
+	Src          string   `json:"src,omitempty"`
+	Dst          string   `json:"dst,omitempty"`
+	TextSearch   string   `json:"text,omitempty"`
+	Regex        string   `json:"regex,omitempty"`
+	DstPortMin   int      `json:"dst_port_min,omitempty"`
+	DstPortMax   int      `json:"dst_port_max,omitempty"`
+	SinceMinutes int      `json:"since_minutes,omitempty"`
+}
+
+type savedPreset struct {
+	Name    string       `json:"name"`
+	Filters savedFilters `json:"filters"`
+}
+
+// DefaultPresetsPath returns $XDG_CONFIG_HOME/iptables-log-tui/presets.json
+// (or the platform equivalent reported by os.UserConfigDir).
+func DefaultPresetsPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "iptables-log-tui", "presets.json"), nil
+}
+
+// LoadPresets reads the presets file at path, in the order they were first
+// saved. A missing file yields no presets and no error.
+func LoadPresets(path string) ([]Preset, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var saved []savedPreset
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("presets %s: %w", path, err)
+	}
+	presets := make([]Preset, 0, len(saved))
+	for _, s := range saved {
+		f := Filters{
+			Action:       s.Filters.Action,
+			Proto:        s.Filters.Proto,
+			Category:     s.Filters.Category,
+			Direction:    s.Filters.Direction,
+			IPSubstr:     s.Filters.IPSubstr,
+			Src:          s.Filters.Src,
+			Dst:          s.Filters.Dst,
+			TextSearch:   s.Filters.TextSearch,
+			DstPortMin:   s.Filters.DstPortMin,
+			DstPortMax:   s.Filters.DstPortMax,
+			SinceMinutes: s.Filters.SinceMinutes,
+		}
+		if s.Filters.Regex != "" {
+			if f.Regex, err = regexp.Compile(s.Filters.Regex); err != nil {
+				return nil, fmt.Errorf("presets %s: %q: %w", path, s.Name, err)
+			}
+		}
+		presets = append(presets, Preset{Name: s.Name, Filters: f})
+	}
+	return presets, nil
+}
+
+// SavePreset stores f under name in the presets file at path, replacing a
+// preset of the same name (ignoring case) in place or appending a new one,
+// and returns the updated list. The write goes through a temporary file and
+// a rename, as for the whois cache.
+func SavePreset(path, name string, f Filters) ([]Preset, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, errors.New("preset name is empty")
+	}
+	presets, err := LoadPresets(path)
+	if err != nil {
+		return nil, err
+	}
+	if i := FindPreset(presets, name); i >= 0 {
+		presets[i] = Preset{Name: name, Filters: f}
+	} else {
+		presets = append(presets, Preset{Name: name, Filters: f})
+	}
+
+	saved := make([]savedPreset, len(presets))
+	for i, p := range presets {
+		s := savedFilters{
+			Action:       p.Filters.Action,
+			Proto:        p.Filters.Proto,
+			Category:     p.Filters.Category,
+			Direction:    p.Filters.Direction,
+			IPSubstr:     p.Filters.IPSubstr,
+			Src:          p.Filters.Src,
+			Dst:          p.Filters.Dst,
+			TextSearch:   p.Filters.TextSearch,
+			DstPortMin:   p.Filters.DstPortMin,
+			DstPortMax:   p.Filters.DstPortMax,
+			SinceMinutes: p.Filters.SinceMinutes,
+		}
+		if p.Filters.Regex != nil {
+			s.Regex = p.Filters.Regex.String()
+		}
+		saved[i] = savedPreset{Name: p.Name, Filters: s}
+	}
+	data, err := json.MarshalIndent(saved, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".presets-*.json")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return nil, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return nil, err
+	}
+	return presets, nil
+}
+
+// FindPreset returns the index of the preset named name, ignoring case, or
+// -1 if there is none.
+func FindPreset(presets []Preset, name string) int {
+	for i, p := range presets {
+		if strings.EqualFold(p.Name, name) {
+			return i
+		}
+	}
+	return -1
+}
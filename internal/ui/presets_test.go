@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestSavePresetRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "presets.json")
+
+	if presets, err := LoadPresets(path); err != nil || presets != nil {
+		t.Fatalf("missing file: %v, %v", presets, err)
+	}
+	ssh := Filters{Action: []string{"DROP"}, Proto: []string{"TCP"}, Category: "External", DstPortMin: 22, DstPortMax: 22}
+	if _, err := SavePreset(path, "ssh drops", ssh); err != nil {
+		t.Fatal(err)
+	}
+	dns := Filters{Proto: []string{"UDP"}, Regex: regexp.MustCompile(`DPT=53\b`), SinceMinutes: 15}
+	if _, err := SavePreset(path, "dns", dns); err != nil {
+		t.Fatal(err)
+	}
+	// Same name, other case: replaced in place, keeping its number.
+	ssh.Direction = "in"
+	if _, err := SavePreset(path, "SSH Drops", ssh); err != nil {
+		t.Fatal(err)
+	}
+
+	presets, err := LoadPresets(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(presets) != 2 || presets[0].Name != "SSH Drops" || presets[1].Name != "dns" {
+		t.Fatalf("presets = %+v", presets)
+	}
+	if got := presets[0].Filters; !reflect.DeepEqual(got, ssh) {
+		t.Errorf("ssh filters = %+v, want %+v", got, ssh)
+	}
+	got := presets[1].Filters
+	if got.Regex == nil || got.Regex.String() != `DPT=53\b` || !slices.Equal(got.Proto, []string{"UDP"}) || got.SinceMinutes != 15 {
+		t.Errorf("dns filters = %+v", got)
+	}
+	if i := FindPreset(presets, "DNS"); i != 1 {
+		t.Errorf("FindPreset(DNS) = %d, want 1", i)
+	}
+}
+
+func TestPresetErrors(t *testing.T) {
+	dir := t.TempDir()
+	if _, err := SavePreset(filepath.Join(dir, "presets.json"), "  ", Filters{Proto: []string{"TCP"}}); err == nil {
+		t.Error("empty name saved")
+	}
+	bad := filepath.Join(dir, "bad.json")
+	if err := os.WriteFile(bad, []byte(`[{"name": "x", "filters": {"regex": "("}}]`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadPresets(bad); err == nil {
+		t.Error("invalid regex loaded")
+	}
+}
+
+func TestFilterSummary(t *testing.T) {
+	if got := FilterSummary(Filters{}); got != "(none)" {
+		t.Errorf("empty = %q", got)
+	}
+	f := Filters{Action: []string{"DROP", "REJECT"}, Proto: []string{"TCP"}, DstPortMin: 22, DstPortMax: 22, SinceMinutes: 60}
+	if got, want := FilterSummary(f), "DROP or REJECT · TCP · port 22 · last 1h"; got != want {
+		t.Errorf("FilterSummary = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/espenotterstad/iptables-log-tui/internal/nettools"
+)
+
+// RenderProbe renders a bordered box with the output of a ping or traceroute
+// run from the detail page. While running is true only the command line is
+// shown; afterwards the output, cut to the box, and why the tool failed if
+// it did. The newest lines are kept when the output is taller than height.
+func RenderProbe(r nettools.Result, running bool, width, height int) string {
+	var sb strings.Builder
+	sb.WriteString(StyleLabel.Render(r.Tool+" "+r.IP) + "\n\n")
+
+	// Leave room for the border, its padding, the title and the status line.
+	lineW := max(width-8, 10)
+	rows := max(height-8, 1)
+	if out := r.Output; out != "" {
+		lines := strings.Split(out, "\n")
+		if len(lines) > rows {
+			lines = lines[len(lines)-rows:]
+		}
+		for _, l := range lines {
+			sb.WriteString(ansi.Truncate(l, lineW, "…") + "\n")
+		}
+		sb.WriteByte('\n')
+	}
+
+	switch {
+	case running:
+		timeout := nettools.PingTimeout
+		if r.Tool == "traceroute" {
+			timeout = nettools.TraceTimeout
+		}
+		sb.WriteString(StyleMuted.Render(fmt.Sprintf("Running… (up to %s)", timeout)) + "\n")
+	case errors.Is(r.Err, nettools.ErrNeedsRoot):
+		sb.WriteString(StyleDrop.Render(r.Tool+" needs root or the CAP_NET_RAW capability here") + "\n")
+	case errors.Is(r.Err, nettools.ErrNotInstalled):
+		sb.WriteString(StyleDrop.Render(r.Err.Error()+" — install it to probe sources") + "\n")
+	case r.Err != nil:
+		sb.WriteString(StyleDrop.Render(r.Tool+": "+r.Err.Error()) + "\n")
+	default:
+		sb.WriteString(StyleAccept.Render("Done") + "\n")
+	}
+	sb.WriteString(StyleHelp.Render("[Esc] close"))
+	return StyleOverlayBorder.Render(sb.String())
+}
@@ -0,0 +1,46 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/x/ansi"
+	"github.com/espenotterstad/iptables-log-tui/internal/nettools"
+)
+
+func TestRenderProbe(t *testing.T) {
+	r := nettools.Result{Tool: "traceroute", IP: "203.0.113.5"}
+	if out := ansi.Strip(RenderProbe(r, true, 80, 30)); !strings.Contains(out, "traceroute 203.0.113.5") || !strings.Contains(out, "Running… (up to 30s)") {
+		t.Errorf("running:\n%s", out)
+	}
+
+	var hops []string
+	for i := 1; i <= 30; i++ {
+		hops = append(hops, fmt.Sprintf("%2d  192.0.2.%d  %d.0 ms", i, i, i))
+	}
+	r.Output = strings.Join(hops, "\n")
+	r.Err = fmt.Errorf("timed out after %s", nettools.TraceTimeout)
+	out := ansi.Strip(RenderProbe(r, false, 80, 20))
+	// The newest hops are kept when the output does not fit.
+	if !strings.Contains(out, "192.0.2.30 ") || strings.Contains(out, "192.0.2.1 ") {
+		t.Errorf("partial output not cut to the newest lines:\n%s", out)
+	}
+	if !strings.Contains(out, "traceroute: timed out after 30s") {
+		t.Errorf("missing the timeout:\n%s", out)
+	}
+
+	for _, tt := range []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("ping is %w", nettools.ErrNotInstalled), "ping is not installed — install it"},
+		{nettools.ErrNeedsRoot, "ping needs root or the CAP_NET_RAW capability"},
+		{nil, "Done"},
+	} {
+		out := ansi.Strip(RenderProbe(nettools.Result{Tool: "ping", IP: "203.0.113.5", Err: tt.err}, false, 80, 20))
+		if !strings.Contains(out, tt.want) {
+			t.Errorf("err %v: missing %q:\n%s", tt.err, tt.want, out)
+		}
+	}
+}
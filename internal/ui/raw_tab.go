@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+)
+
+// RawLine is a log line the parser rejected, kept for the Raw tab.
+type RawLine struct {
+	Time   time.Time // when it was read
+	Source string    // the file (or journal) it came from
+	Line   string
+	Err    string // why ParseLine rejected it
+}
+
+// RenderRawTab renders the rejected lines, newest first, cut to the terminal
+// width; cursor is the selected row in that order. The selected line's parse
+// error and source are shown below the list. kept is the buffer's capacity,
+// named in the empty-list hint.
+func RenderRawTab(lines []RawLine, cursor, width, height, kept int) string {
+	var sb strings.Builder
+	gutter := strings.Repeat(" ", gutterWidth)
+	lineW := max(width-gutterWidth-colTime, 1)
+
+	header := lipgloss.NewStyle().Bold(true).Foreground(ColorHeader)
+	sb.WriteString(gutter + header.Render(padCell("READ AT", colTime)+"LINE") + "\n")
+	sb.WriteString(StyleDivider.Render(strings.Repeat("─", width)) + "\n")
+
+	// Reserve the header, its divider, and the divider, error and source
+	// lines below the list.
+	rowsAvail := max(height-5, 1)
+	if len(lines) == 0 {
+		sb.WriteString(gutter + StyleMuted.Render("No unparsed lines yet.") + "\n")
+	}
+	start := scrollStart(len(lines), cursor, rowsAvail)
+	end := min(start+rowsAvail, len(lines))
+	for i := start; i < end; i++ {
+		l := lines[len(lines)-1-i]
+		row := padCell(l.Time.Format(time.TimeOnly), colTime) + ansi.Truncate(l.Line, lineW, "…")
+		if i == cursor {
+			prefix := lipgloss.NewStyle().Foreground(ColorStats).Render(arrowRune) +
+				strings.Repeat(" ", gutterWidth-lipgloss.Width(arrowRune))
+			sb.WriteString(prefix + StyleSelected.Render(row) + "\n")
+		} else {
+			sb.WriteString(gutter + StyleMuted.Render(row) + "\n")
+		}
+	}
+
+	out := sb.String()
+	for written := strings.Count(out, "\n"); written < height-3; written++ {
+		out += "\n"
+	}
+	out += StyleDivider.Render(strings.Repeat("─", width)) + "\n"
+	if cursor < len(lines) {
+		l := lines[len(lines)-1-cursor]
+		out += gutter + StyleLabel.Render("Error: ") + StyleDrop.Render(l.Err) + "\n"
+		out += gutter + StyleLabel.Render("From:  ") + l.Source + "\n"
+	} else {
+		out += gutter + StyleMuted.Render(fmt.Sprintf("The newest %d lines that fail to parse are kept here, with the reason.", kept)) + "\n\n"
+	}
+	return out
+}
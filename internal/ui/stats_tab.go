@@ -2,10 +2,15 @@ package ui
 
 import (
 	"fmt"
+	"net/netip"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/espenotterstad/iptables-log-tui/internal/alerts"
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
 	"github.com/espenotterstad/iptables-log-tui/internal/ports"
 )
 
@@ -16,7 +21,113 @@ type Stats struct {
 	ByProto   map[string]int
 	ByIface   map[string]int
 	BySrcIP   map[string]int
+	ByDstIP   map[string]int
 	ByDstPort map[string]int
+	// BySubnet counts sources by their /24 (IPv4) or /64 (IPv6) network, so
+	// a scan spread over one netblock shows up as a single entry.
+	BySubnet map[string]int
+	// Rate counts events per minute for the most recent RateMinutes minutes,
+	// and Bytes sums their LEN= over the same window.
+	Rate  Rate
+	Bytes Rate
+	// Noisy lists sources that crossed the --alert-rate threshold, highest
+	// peak first, and AlertRate is that threshold (0 when disabled).
+	Noisy     []alerts.Source
+	AlertRate int
+	// Offenders are the sources flagged in earlier sessions that have
+	// logged events in this one, busiest first.
+	Offenders []alerts.Sighting
+	// Unparsed counts lines that did not parse as log entries; a high count
+	// means the log format is not one the parser understands.
+	Unparsed int
+	// Anonymize masks the addresses and subnets shown, as the table does.
+	Anonymize bool
+}
+
+// RateMinutes is how many one-minute buckets Rate keeps.
+const RateMinutes = 60
+
+// Rate is a fixed-size ring of per-minute event counts. The newest bucket is
+// the minute of the newest timestamp seen, so replayed history renders the
+// same way as a live tail. Being an array it copies with Stats by value.
+type Rate struct {
+	buckets [RateMinutes]int
+	head    int64 // Unix minute of the newest bucket; 0 before the first event
+}
+
+// Add counts one event at t. Events older than the window are dropped and a
+// newer minute advances the window, clearing the buckets it skips.
+func (r *Rate) Add(t time.Time) {
+	r.AddN(t, 1)
+}
+
+// AddN adds n to the bucket for t, under the same rules as Add.
+func (r *Rate) AddN(t time.Time, n int) {
+	if t.IsZero() {
+		return
+	}
+	minute := t.Unix() / 60
+	switch {
+	case r.head == 0:
+		r.head = minute
+	case minute > r.head:
+		for i := r.head + 1; i <= minute && i-r.head <= RateMinutes; i++ {
+			r.buckets[i%RateMinutes] = 0
+		}
+		r.head = minute
+	case minute <= r.head-RateMinutes:
+		return
+	}
+	r.buckets[minute%RateMinutes] += n
+}
+
+// Counts returns the buckets oldest first.
+func (r Rate) Counts() []int {
+	out := make([]int, RateMinutes)
+	for i := range out {
+		m := r.head - RateMinutes + 1 + int64(i)
+		out[i] = r.buckets[((m%RateMinutes)+RateMinutes)%RateMinutes]
+	}
+	return out
+}
+
+var sparkBlocks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders counts as block characters scaled to the largest value.
+// Zero renders as the lowest block; any non-zero count is at least one step
+// above it so isolated events stay visible.
+func sparkline(counts []int) string {
+	hi := 0
+	for _, c := range counts {
+		if c > hi {
+			hi = c
+		}
+	}
+	top := len(sparkBlocks) - 1
+	var sb strings.Builder
+	for _, c := range counts {
+		idx := 0
+		if hi > 0 {
+			idx = (c*top + hi - 1) / hi
+		}
+		sb.WriteRune(sparkBlocks[idx])
+	}
+	return sb.String()
+}
+
+// HumanBytes formats n bytes with binary units, e.g. "512 B" or "1.5 MiB".
+func HumanBytes(n int) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d B", n)
+	}
+	v, unit := float64(n)/1024, "KiB"
+	for _, next := range []string{"MiB", "GiB"} {
+		if v < 1024 {
+			break
+		}
+		v, unit = v/1024, next
+	}
+	return fmt.Sprintf("%.1f %s", v, unit)
 }
 
 // NewStats creates an initialised Stats.
@@ -26,13 +137,66 @@ func NewStats() Stats {
 		ByProto:   make(map[string]int),
 		ByIface:   make(map[string]int),
 		BySrcIP:   make(map[string]int),
+		ByDstIP:   make(map[string]int),
 		ByDstPort: make(map[string]int),
+		BySubnet:  make(map[string]int),
+	}
+}
+
+// AnonymizePrefix masks the address of a prefix such as "203.0.113.0/24" with
+// parser.AnonymizeIP, keeping the length: "203.0.x.x/24". Anything else is
+// returned unchanged.
+func AnonymizePrefix(s string) string {
+	addr, bits, ok := strings.Cut(s, "/")
+	if !ok {
+		return s
 	}
+	return parser.AnonymizeIP(addr) + "/" + bits
+}
+
+// SourceSubnet returns the /24 (IPv4) or /64 (IPv6) network containing ip,
+// e.g. "203.0.113.0/24", or "" if ip does not parse.
+func SourceSubnet(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	addr = addr.Unmap()
+	bits := 64
+	if addr.Is4() {
+		bits = 24
+	}
+	p, _ := addr.Prefix(bits)
+	return p.String()
+}
+
+// Summary returns a one-line health check: total events, the share that
+// were dropped (DROP and REJECT together) and the noisiest source, e.g.
+// "1234 events · 82.3% dropped · top talker 203.0.113.5 (412)".
+func (s Stats) Summary() string {
+	if s.Total == 0 {
+		return "No events yet"
+	}
+	dropped := s.ByAction["DROP"] + s.ByAction["REJECT"]
+	out := fmt.Sprintf("%d events · %.1f%% dropped", s.Total, float64(dropped)*100/float64(s.Total))
+	if top := topN(s.BySrcIP, 1); len(top) > 0 {
+		out += fmt.Sprintf(" · top talker %s (%d)", s.ip(top[0].key), top[0].count)
+	}
+	return out
+}
+
+// ip returns ip as the Stats tab shows it: masked when s.Anonymize is set.
+func (s Stats) ip(ip string) string {
+	if s.Anonymize {
+		return parser.AnonymizeIP(ip)
+	}
+	return ip
 }
 
 // RenderStatsTab renders the Stats tab view.
 func RenderStatsTab(s Stats, width int) string {
 	var sb strings.Builder
+	sb.WriteString("\n  " + StyleLabel.Render(s.Summary()) + "\n")
 
 	section := func(title string) {
 		sb.WriteString("\n" + StyleLabel.Render(title) + "\n")
@@ -46,17 +210,73 @@ func RenderStatsTab(s Stats, width int) string {
 		))
 	}
 
+	// share renders a category as a proportional bar, e.g.
+	// "DROP  ████████░░ 80% (1234)". The bar takes whatever width is left
+	// after the label and the figures, within sensible bounds.
+	barW := width - 2 - 28 - 2 - len(" 100% ()") - len(strconv.Itoa(s.Total))
+	barW = max(10, min(barW, 40))
+	share := func(k string, count int) {
+		pct := 0
+		filled := 0
+		if s.Total > 0 {
+			pct = count * 100 / s.Total
+			filled = count * barW / s.Total
+		}
+		sb.WriteString(fmt.Sprintf("  %s  %s%s %s\n",
+			StyleStatLabel.Render(fmt.Sprintf("%-28s", k)),
+			StyleStatValue.Render(strings.Repeat("█", filled)),
+			StyleMuted.Render(strings.Repeat("░", barW-filled)),
+			StyleStatValue.Render(fmt.Sprintf("%3d%% (%d)", pct, count)),
+		))
+	}
+
 	section("Overview")
 	kv("Total events", fmt.Sprintf("%d", s.Total))
+	kv("Unparsed lines", fmt.Sprintf("%d", s.Unparsed))
+	// Many ports from one source suggests a scan; many sources hitting one
+	// port, a distributed attack.
+	kv("Unique source IPs", fmt.Sprintf("%d", len(s.BySrcIP)))
+	kv("Unique destination IPs", fmt.Sprintf("%d", len(s.ByDstIP)))
+	kv("Unique destination ports", fmt.Sprintf("%d", len(s.ByDstPort)))
+
+	section("Rate (last 60 min)")
+	// Packets and bytes side by side tell many tiny scan packets apart from
+	// a few large floods.
+	counts, bytes := s.Rate.Counts(), s.Bytes.Counts()
+	sb.WriteString("  " + StyleStatValue.Render(sparkline(counts)) +
+		"  " + StyleStatLabel.Render(fmt.Sprintf("peak %d pkt/min", slices.Max(counts))) + "\n")
+	sb.WriteString("  " + StyleStatValue.Render(sparkline(bytes)) +
+		"  " + StyleStatLabel.Render(fmt.Sprintf("peak %s/min", HumanBytes(slices.Max(bytes)))) + "\n")
+	sb.WriteString("  " + StyleMuted.Render(fmt.Sprintf("%-*s", RateMinutes-3, "-60m")+"now") + "\n")
+
+	if len(s.Noisy) > 0 {
+		section(fmt.Sprintf("Noisy Sources (>%d/min)", s.AlertRate))
+		for _, n := range s.Noisy {
+			sb.WriteString(fmt.Sprintf("  %s  %s\n",
+				StyleDrop.Render(fmt.Sprintf("%-28s", "! "+s.ip(n.IP))),
+				StyleStatValue.Render(fmt.Sprintf("peak %d/min", n.Peak)),
+			))
+		}
+	}
+
+	if len(s.Offenders) > 0 {
+		section("Known Offenders Seen This Session")
+		for _, o := range s.Offenders {
+			sb.WriteString(fmt.Sprintf("  %s  %s\n",
+				StyleDrop.Render(fmt.Sprintf("%-28s", "~ "+s.ip(o.IP))),
+				StyleStatValue.Render(fmt.Sprintf("%d events · flagged %s", o.Events, HumanAge(o.Flagged))),
+			))
+		}
+	}
 
 	section("By Action")
 	for _, item := range topN(s.ByAction, len(s.ByAction)) {
-		kv(item.key, fmt.Sprintf("%d", item.count))
+		share(item.key, item.count)
 	}
 
 	section("By Protocol")
 	for _, item := range topN(s.ByProto, len(s.ByProto)) {
-		kv(item.key, fmt.Sprintf("%d", item.count))
+		share(item.key, item.count)
 	}
 
 	section("By Interface")
@@ -66,7 +286,21 @@ func RenderStatsTab(s Stats, width int) string {
 
 	section("Top 10 Source IPs")
 	for i, ip := range topN(s.BySrcIP, 10) {
-		kv(fmt.Sprintf("%2d. %s", i+1, ip.key), fmt.Sprintf("%d", ip.count))
+		kv(fmt.Sprintf("%2d. %s", i+1, s.ip(ip.key)), fmt.Sprintf("%d", ip.count))
+	}
+
+	section("Top 10 Source Subnets (/24, /64)")
+	for i, n := range topN(s.BySubnet, 10) {
+		subnet := n.key
+		if s.Anonymize {
+			subnet = AnonymizePrefix(subnet)
+		}
+		kv(fmt.Sprintf("%2d. %s", i+1, subnet), fmt.Sprintf("%d", n.count))
+	}
+
+	section("Top 10 Destination IPs")
+	for i, ip := range topN(s.ByDstIP, 10) {
+		kv(fmt.Sprintf("%2d. %s", i+1, s.ip(ip.key)), fmt.Sprintf("%d", ip.count))
 	}
 
 	section("Top 10 Destination Ports")
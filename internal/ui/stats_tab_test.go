@@ -0,0 +1,190 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/espenotterstad/iptables-log-tui/internal/alerts"
+	"github.com/muesli/termenv"
+)
+
+func TestRateBucketsAndWindow(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	var r Rate
+	r.Add(base)
+	r.Add(base.Add(30 * time.Second))
+	r.Add(base.Add(2 * time.Minute))
+
+	c := r.Counts()
+	if len(c) != RateMinutes {
+		t.Fatalf("len(Counts) = %d, want %d", len(c), RateMinutes)
+	}
+	if c[RateMinutes-1] != 1 || c[RateMinutes-2] != 0 || c[RateMinutes-3] != 2 {
+		t.Errorf("tail of Counts = %v, want [... 2 0 1]", c[RateMinutes-3:])
+	}
+
+	// An event older than the window is ignored.
+	r.Add(base.Add(-2 * time.Hour))
+	if got := sum(r.Counts()); got != 3 {
+		t.Errorf("sum after stale event = %d, want 3", got)
+	}
+
+	// Jumping past the window clears every bucket.
+	r.Add(base.Add(3 * time.Hour))
+	if got := sum(r.Counts()); got != 1 {
+		t.Errorf("sum after jump = %d, want 1", got)
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	if got := sparkline([]int{0, 1, 8}); got != "▁▂█" {
+		t.Errorf("sparkline = %q, want %q", got, "▁▂█")
+	}
+	if got := sparkline([]int{0, 0}); got != "▁▁" {
+		t.Errorf("sparkline of zeros = %q, want %q", got, "▁▁")
+	}
+}
+
+func sum(xs []int) int {
+	n := 0
+	for _, x := range xs {
+		n += x
+	}
+	return n
+}
+
+func TestRenderStatsTabPlain(t *testing.T) {
+	prev := lipgloss.ColorProfile()
+	t.Cleanup(func() { lipgloss.SetColorProfile(prev) })
+	lipgloss.SetColorProfile(termenv.TrueColor)
+	ForcePlain()
+
+	s := NewStats()
+	s.Total = 3
+	s.ByAction["DROP"] = 2
+	s.ByAction["ACCEPT"] = 1
+	s.ByProto["TCP"] = 3
+	s.BySrcIP["203.0.113.5"] = 3
+	s.Rate.Add(time.Now())
+	if out := RenderStatsTab(s, 80); strings.Contains(out, "\x1b") {
+		t.Errorf("plain output contains escape sequences: %q", out)
+	}
+}
+
+func TestStatsSummary(t *testing.T) {
+	s := NewStats()
+	if got := s.Summary(); got != "No events yet" {
+		t.Errorf("empty: %q", got)
+	}
+	s.Total = 8
+	s.ByAction["DROP"] = 4
+	s.ByAction["REJECT"] = 1
+	s.ByAction["ACCEPT"] = 3
+	s.BySrcIP["203.0.113.5"] = 5
+	s.BySrcIP["198.51.100.7"] = 3
+	want := "8 events · 62.5% dropped · top talker 203.0.113.5 (5)"
+	if got := s.Summary(); got != want {
+		t.Errorf("Summary() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderStatsTabOffenders(t *testing.T) {
+	s := NewStats()
+	if out := ansi.Strip(RenderStatsTab(s, 80)); strings.Contains(out, "Known Offenders") {
+		t.Error("offenders section shown with none seen")
+	}
+	s.Offenders = []alerts.Sighting{{IP: "203.0.113.9", Events: 12, Flagged: time.Now().Add(-50 * time.Hour)}}
+	out := ansi.Strip(RenderStatsTab(s, 80))
+	if !strings.Contains(out, "Known Offenders Seen This Session") || !strings.Contains(out, "~ 203.0.113.9") ||
+		!strings.Contains(out, "12 events · flagged 2d ago") {
+		t.Errorf("offenders section missing or wrong:\n%s", out)
+	}
+}
+
+func TestRenderStatsTabAnonymized(t *testing.T) {
+	s := NewStats()
+	s.Total = 2
+	s.BySrcIP["203.0.113.5"] = 2
+	s.ByDstIP["2001:db8:0:1::20"] = 2
+	s.BySubnet["203.0.113.0/24"] = 2
+	s.Anonymize = true
+	out := ansi.Strip(RenderStatsTab(s, 80))
+	for _, want := range []string{"top talker 203.0.x.x (2)", " 1. 203.0.x.x", " 1. 203.0.x.x/24", " 1. 2001:db8:0:1:x:x:x:x"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+	for _, leaked := range []string{"203.0.113", "::20"} {
+		if strings.Contains(out, leaked) {
+			t.Errorf("shows %q", leaked)
+		}
+	}
+}
+
+func TestRenderStatsTabUniqueCounts(t *testing.T) {
+	s := NewStats()
+	s.BySrcIP["203.0.113.5"] = 2
+	s.ByDstIP["192.0.2.1"] = 1
+	s.ByDstIP["192.0.2.2"] = 1
+	s.ByDstPort["22"] = 2
+	out := ansi.Strip(RenderStatsTab(s, 80))
+	for label, want := range map[string]string{
+		"Unique source IPs":        "1",
+		"Unique destination IPs":   "2",
+		"Unique destination ports": "1",
+	} {
+		i := strings.Index(out, label)
+		if i < 0 {
+			t.Fatalf("missing %q", label)
+		}
+		line, _, _ := strings.Cut(out[i:], "\n")
+		if f := strings.Fields(line); f[len(f)-1] != want {
+			t.Errorf("%q, want %s", line, want)
+		}
+	}
+	if !strings.Contains(out, "Top 10 Destination IPs") || !strings.Contains(out, " 1. 192.0.2.1") {
+		t.Error("missing the top destination IPs")
+	}
+}
+
+func TestHumanBytes(t *testing.T) {
+	for n, want := range map[int]string{
+		0:                "0 B",
+		1023:             "1023 B",
+		1536:             "1.5 KiB",
+		5 * 1024 * 1024:  "5.0 MiB",
+		3 << 30:          "3.0 GiB",
+		2048 * (1 << 30): "2048.0 GiB",
+	} {
+		if got := HumanBytes(n); got != want {
+			t.Errorf("HumanBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestRateAddN(t *testing.T) {
+	base := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	var r Rate
+	r.AddN(base, 1500)
+	r.AddN(base.Add(10*time.Second), 60)
+	if got := r.Counts()[RateMinutes-1]; got != 1560 {
+		t.Errorf("bucket = %d, want 1560", got)
+	}
+}
+
+func TestSourceSubnet(t *testing.T) {
+	for ip, want := range map[string]string{
+		"203.0.113.77":         "203.0.113.0/24",
+		"::ffff:198.51.100.9":  "198.51.100.0/24",
+		"2001:db8:1:2:3:4:5:6": "2001:db8:1:2::/64",
+		"not-an-ip":            "",
+		"":                     "",
+	} {
+		if got := SourceSubnet(ip); got != want {
+			t.Errorf("SourceSubnet(%q) = %q, want %q", ip, got, want)
+		}
+	}
+}
@@ -1,82 +1,175 @@
 package ui
 
-import "github.com/charmbracelet/lipgloss"
+import (
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
 
 var (
 	// ColorDrop is used for DROP / BLOCK entries.
-	ColorDrop = lipgloss.Color("9") // bright red
+	ColorDrop lipgloss.Color
 	// ColorAccept is used for ACCEPT entries.
-	ColorAccept = lipgloss.Color("10") // bright green
+	ColorAccept lipgloss.Color
 	// ColorICMP is used for ICMP entries.
-	ColorICMP = lipgloss.Color("11") // bright yellow
+	ColorICMP lipgloss.Color
 	// ColorStats is used for counter values in the Stats tab.
-	ColorStats = lipgloss.Color("14") // bright cyan
+	ColorStats lipgloss.Color
 	// ColorMuted is used for de-emphasised text.
-	ColorMuted = lipgloss.Color("240")
+	ColorMuted lipgloss.Color
 	// ColorHeader is used for column header text.
-	ColorHeader = lipgloss.Color("15") // white
+	ColorHeader lipgloss.Color
+	// ColorText is used for ordinary secondary text such as ports and labels.
+	ColorText lipgloss.Color
+	// ColorHighlight is used for emphasised text: the active tab and the
+	// selected row.
+	ColorHighlight lipgloss.Color
+	// ColorSelectedBg is the background of the selected row.
+	ColorSelectedBg lipgloss.Color
+	// ColorTitle is used for the application title.
+	ColorTitle lipgloss.Color
+	// ColorFilter is used for active filter values.
+	ColorFilter lipgloss.Color
 
 	// StyleTabActive is applied to the currently selected tab label.
-	StyleTabActive = lipgloss.NewStyle().
-			Bold(true).
-			Underline(true).
-			Foreground(lipgloss.Color("15"))
+	StyleTabActive lipgloss.Style
 
 	// StyleTabInactive is applied to non-selected tab labels.
-	StyleTabInactive = lipgloss.NewStyle().
-				Foreground(ColorMuted)
+	StyleTabInactive lipgloss.Style
 
 	// StyleTitle is the application title in the top-right corner.
-	StyleTitle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("12"))
+	StyleTitle lipgloss.Style
 
 	// StyleDivider renders a full-width horizontal rule.
-	StyleDivider = lipgloss.NewStyle().
-			Foreground(ColorMuted)
+	StyleDivider lipgloss.Style
 
 	// StyleDrop styles a DROP row cell.
-	StyleDrop = lipgloss.NewStyle().Foreground(ColorDrop)
+	StyleDrop lipgloss.Style
 
 	// StyleAccept styles an ACCEPT row cell.
-	StyleAccept = lipgloss.NewStyle().Foreground(ColorAccept)
+	StyleAccept lipgloss.Style
 
 	// StyleICMP styles an ICMP row cell.
-	StyleICMP = lipgloss.NewStyle().Foreground(ColorICMP)
+	StyleICMP lipgloss.Style
 
 	// StyleSelected styles the selected / cursor row.
-	StyleSelected = lipgloss.NewStyle().
-			Bold(true).
-			Background(lipgloss.Color("236")).
-			Foreground(lipgloss.Color("15"))
+	StyleSelected lipgloss.Style
 
 	// StyleLabel is used for field names in the detail overlay.
-	StyleLabel = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(ColorStats)
+	StyleLabel lipgloss.Style
 
 	// StyleHelp is the footer help bar.
-	StyleHelp = lipgloss.NewStyle().
-			Foreground(ColorMuted)
+	StyleHelp lipgloss.Style
 
 	// StyleFilter is used to display active filter labels.
-	StyleFilter = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("13"))
+	StyleFilter lipgloss.Style
 
 	// StyleOverlayBorder is the border around the detail overlay.
-	StyleOverlayBorder = lipgloss.NewStyle().
-				Border(lipgloss.RoundedBorder()).
-				BorderForeground(ColorStats).
-				Padding(0, 1)
+	StyleOverlayBorder lipgloss.Style
 
 	// StyleStatValue renders stat counter values.
-	StyleStatValue = lipgloss.NewStyle().Foreground(ColorStats).Bold(true)
+	StyleStatValue lipgloss.Style
 
 	// StyleStatLabel renders stat counter labels.
-	StyleStatLabel = lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+	StyleStatLabel lipgloss.Style
+
+	// StyleMuted renders de-emphasised text.
+	StyleMuted lipgloss.Style
 )
 
+// LightTheme returns a palette for light-background terminals: darker
+// foregrounds so the bright defaults do not wash out, and a pale selection
+// bar that keeps the selected row readable.
+func LightTheme() Theme {
+	return Theme{
+		Drop:       "124", // dark red
+		Accept:     "28",  // dark green
+		ICMP:       "130", // dark orange
+		Stats:      "25",  // dark blue
+		Muted:      "244",
+		Header:     "232", // near black
+		Text:       "238",
+		Highlight:  "232",
+		SelectedBg: "253",
+		Title:      "19",
+		Filter:     "90",
+	}
+}
+
+func init() {
+	ApplyTheme(DefaultTheme())
+}
+
+// ApplyTheme sets the package colors from t and rebuilds every style derived
+// from them. It must be called before the program starts rendering.
+func ApplyTheme(t Theme) {
+	ColorDrop = lipgloss.Color(t.Drop)
+	ColorAccept = lipgloss.Color(t.Accept)
+	ColorICMP = lipgloss.Color(t.ICMP)
+	ColorStats = lipgloss.Color(t.Stats)
+	ColorMuted = lipgloss.Color(t.Muted)
+	ColorHeader = lipgloss.Color(t.Header)
+	ColorText = lipgloss.Color(t.Text)
+	ColorHighlight = lipgloss.Color(t.Highlight)
+	ColorSelectedBg = lipgloss.Color(t.SelectedBg)
+	ColorTitle = lipgloss.Color(t.Title)
+	ColorFilter = lipgloss.Color(t.Filter)
+
+	StyleTabActive = lipgloss.NewStyle().
+		Bold(true).
+		Underline(true).
+		Foreground(ColorHighlight)
+
+	StyleTabInactive = lipgloss.NewStyle().
+		Foreground(ColorMuted)
+
+	StyleTitle = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(ColorTitle)
+
+	StyleDivider = lipgloss.NewStyle().
+		Foreground(ColorMuted)
+
+	StyleDrop = lipgloss.NewStyle().Foreground(ColorDrop)
+
+	StyleAccept = lipgloss.NewStyle().Foreground(ColorAccept)
+
+	StyleICMP = lipgloss.NewStyle().Foreground(ColorICMP)
+
+	StyleSelected = lipgloss.NewStyle().
+		Bold(true).
+		Background(ColorSelectedBg).
+		Foreground(ColorHighlight)
+
+	StyleLabel = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(ColorStats)
+
+	StyleHelp = lipgloss.NewStyle().
+		Foreground(ColorMuted)
+
+	StyleFilter = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(ColorFilter)
+
+	StyleOverlayBorder = lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorStats).
+		Padding(0, 1)
+
+	StyleStatValue = lipgloss.NewStyle().Foreground(ColorStats).Bold(true)
+
+	StyleStatLabel = lipgloss.NewStyle().Foreground(ColorText)
+
+	StyleMuted = lipgloss.NewStyle().Foreground(ColorMuted)
+}
+
+// ForcePlain switches all rendering to the no-color ASCII profile, so styled
+// output carries no escape sequences. main calls it when stdout is not a
+// terminal, e.g. when piped through tee.
+func ForcePlain() {
+	lipgloss.SetColorProfile(termenv.Ascii)
+}
+
 // RowStyle returns the appropriate Lip Gloss style for a log entry row based
 // on its action and protocol, with an extra selected highlight if needed.
 func RowStyle(action, proto string, selected bool) lipgloss.Style {
@@ -87,17 +180,22 @@ func RowStyle(action, proto string, selected bool) lipgloss.Style {
 	case "ACCEPT":
 		base = StyleAccept
 	default:
-		if proto == "ICMP" {
+		if IsICMP(proto) {
 			base = StyleICMP
 		} else {
 			base = lipgloss.NewStyle()
 		}
 	}
-	if proto == "ICMP" && action != "DROP" && action != "ACCEPT" {
+	if IsICMP(proto) && action != "DROP" && action != "ACCEPT" {
 		base = StyleICMP
 	}
 	if selected {
-		base = base.Background(lipgloss.Color("236")).Bold(true)
+		base = base.Background(ColorSelectedBg).Bold(true)
 	}
 	return base
 }
+
+// IsICMP reports whether proto is ICMP for either IP version.
+func IsICMP(proto string) bool {
+	return proto == "ICMP" || proto == "ICMPv6"
+}
@@ -0,0 +1,85 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// Theme is the set of colors used by every style in this package. Values are
+// ANSI 256-color indices ("0"–"255") or hex RGB ("#rrggbb").
+type Theme struct {
+	Drop       string `json:"drop"`
+	Accept     string `json:"accept"`
+	ICMP       string `json:"icmp"`
+	Stats      string `json:"stats"`
+	Muted      string `json:"muted"`
+	Header     string `json:"header"`
+	Text       string `json:"text"`
+	Highlight  string `json:"highlight"`
+	SelectedBg string `json:"selected_bg"`
+	Title      string `json:"title"`
+	Filter     string `json:"filter"`
+}
+
+// DefaultTheme returns the built-in palette, tuned for dark terminals.
+func DefaultTheme() Theme {
+	return Theme{
+		Drop:       "9",  // bright red
+		Accept:     "10", // bright green
+		ICMP:       "11", // bright yellow
+		Stats:      "14", // bright cyan
+		Muted:      "240",
+		Header:     "15", // white
+		Text:       "252",
+		Highlight:  "15",
+		SelectedBg: "236",
+		Title:      "12",
+		Filter:     "13",
+	}
+}
+
+// LoadTheme reads a JSON theme file. Keys that are present override
+// DefaultTheme; absent keys keep their default. Unknown keys and values that
+// are not valid colors are reported as errors naming the offending key.
+func LoadTheme(path string) (Theme, error) {
+	t := DefaultTheme()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return t, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&t); err != nil {
+		return DefaultTheme(), fmt.Errorf("theme %s: %w", path, err)
+	}
+	if err := t.validate(); err != nil {
+		return DefaultTheme(), fmt.Errorf("theme %s: %w", path, err)
+	}
+	return t, nil
+}
+
+var hexColorRe = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+// validate checks that every color is an ANSI index or a hex RGB value.
+func (t Theme) validate() error {
+	fields := []struct{ key, val string }{
+		{"drop", t.Drop}, {"accept", t.Accept}, {"icmp", t.ICMP},
+		{"stats", t.Stats}, {"muted", t.Muted}, {"header", t.Header},
+		{"text", t.Text}, {"highlight", t.Highlight},
+		{"selected_bg", t.SelectedBg}, {"title", t.Title}, {"filter", t.Filter},
+	}
+	for _, f := range fields {
+		if hexColorRe.MatchString(f.val) {
+			continue
+		}
+		if n, err := strconv.Atoi(f.val); err == nil && n >= 0 && n <= 255 {
+			continue
+		}
+		return fmt.Errorf("%q: invalid color %q (want 0-255 or #rrggbb)", f.key, f.val)
+	}
+	return nil
+}
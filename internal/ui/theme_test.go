@@ -0,0 +1,52 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTheme(t *testing.T, body string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "theme.json")
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadThemeOverridesAndDefaults(t *testing.T) {
+	th, err := LoadTheme(writeTheme(t, `{"drop": "124", "selected_bg": "#eeeeee"}`))
+	if err != nil {
+		t.Fatalf("LoadTheme: %v", err)
+	}
+	if th.Drop != "124" || th.SelectedBg != "#eeeeee" {
+		t.Errorf("overrides not applied: %+v", th)
+	}
+	if th.Accept != DefaultTheme().Accept {
+		t.Errorf("Accept = %q, want default %q", th.Accept, DefaultTheme().Accept)
+	}
+}
+
+func TestLoadThemeErrors(t *testing.T) {
+	tests := []struct {
+		name, body, want string
+	}{
+		{"unknown key", `{"dorp": "1"}`, `"dorp"`},
+		{"out of range", `{"icmp": "256"}`, `"icmp"`},
+		{"bad hex", `{"title": "#12345"}`, `"title"`},
+		{"named color", `{"muted": "grey"}`, `"muted"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadTheme(writeTheme(t, tt.body))
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("error %q does not mention %s", err, tt.want)
+			}
+		})
+	}
+}
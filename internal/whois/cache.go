@@ -0,0 +1,118 @@
+package whois
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// DefaultCacheTTL is how long a cached whois result is considered fresh.
+const DefaultCacheTTL = 7 * 24 * time.Hour
+
+// cacheEntry is a Result together with the time it was fetched.
+type cacheEntry struct {
+	Result  Result    `json:"result"`
+	Fetched time.Time `json:"fetched"`
+}
+
+// Cache is an on-disk whois cache keyed by IP. Entries older than the TTL are
+// ignored by Get so the caller refreshes them. It is safe for concurrent use.
+type Cache struct {
+	mu      sync.Mutex
+	path    string
+	ttl     time.Duration
+	entries map[string]cacheEntry
+}
+
+// DefaultCachePath returns $XDG_CACHE_HOME/iptables-log-tui/whois.json (or the
+// platform equivalent reported by os.UserCacheDir).
+func DefaultCachePath() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "iptables-log-tui", "whois.json"), nil
+}
+
+// LoadCache reads the cache file at path. A missing file yields an empty
+// cache; any other read or decode error is returned alongside an empty cache
+// that is still usable (and will overwrite the bad file on save).
+func LoadCache(path string, ttl time.Duration) (*Cache, error) {
+	c := &Cache{path: path, ttl: ttl, entries: make(map[string]cacheEntry)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return c, nil
+	}
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(data, &c.entries); err != nil {
+		c.entries = make(map[string]cacheEntry)
+		return c, err
+	}
+	return c, nil
+}
+
+// SaveCache writes c back to its file, dropping stale entries. The write goes
+// through a temporary file and a rename so a crash never leaves a torn file.
+func SaveCache(c *Cache) error {
+	c.mu.Lock()
+	fresh := make(map[string]cacheEntry, len(c.entries))
+	for ip, e := range c.entries {
+		if c.fresh(e) {
+			fresh[ip] = e
+		}
+	}
+	c.mu.Unlock()
+
+	data, err := json.MarshalIndent(fresh, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o700); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), ".whois-*.json")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), c.path)
+}
+
+// Get returns the cached result for ip if present and not older than the TTL.
+func (c *Cache) Get(ip string) (Result, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[ip]
+	if !ok || !c.fresh(e) {
+		return Result{}, false
+	}
+	return e.Result, true
+}
+
+// Put stores r for ip with the current time. Empty results are not stored,
+// so a missing whois binary does not poison the cache for a whole TTL.
+func (c *Cache) Put(ip string, r Result) {
+	if r == (Result{}) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[ip] = cacheEntry{Result: r, Fetched: time.Now()}
+}
+
+func (c *Cache) fresh(e cacheEntry) bool {
+	return time.Since(e.Fetched) <= c.ttl
+}
@@ -0,0 +1,44 @@
+package whois
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCacheRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sub", "whois.json")
+	c, err := LoadCache(path, time.Hour)
+	if err != nil {
+		t.Fatalf("LoadCache on missing file: %v", err)
+	}
+	want := Result{Subnet: "203.0.113.0/24", NetName: "EXAMPLE", ASN: "AS64500", Org: "Example"}
+	c.Put("203.0.113.7", want)
+	c.Put("198.51.100.1", Result{}) // empty results are not stored
+	c.entries["192.0.2.1"] = cacheEntry{Result: want, Fetched: time.Now().Add(-2 * time.Hour)}
+	if err := SaveCache(c); err != nil {
+		t.Fatalf("SaveCache: %v", err)
+	}
+
+	c2, err := LoadCache(path, time.Hour)
+	if err != nil {
+		t.Fatalf("LoadCache: %v", err)
+	}
+	if got, ok := c2.Get("203.0.113.7"); !ok || got != want {
+		t.Errorf("Get: got %+v, %v; want %+v, true", got, ok, want)
+	}
+	if _, ok := c2.Get("198.51.100.1"); ok {
+		t.Error("empty result should not have been cached")
+	}
+	if _, ok := c2.Get("192.0.2.1"); ok {
+		t.Error("stale entry should not survive a save")
+	}
+}
+
+func TestCacheGetIgnoresStale(t *testing.T) {
+	c, _ := LoadCache(filepath.Join(t.TempDir(), "whois.json"), time.Minute)
+	c.entries["192.0.2.1"] = cacheEntry{Result: Result{Org: "Old"}, Fetched: time.Now().Add(-time.Hour)}
+	if _, ok := c.Get("192.0.2.1"); ok {
+		t.Error("Get returned an entry older than the TTL")
+	}
+}
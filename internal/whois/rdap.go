@@ -0,0 +1,240 @@
+package whois
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ianaBootstrap is where the IANA RDAP bootstrap registries (RFC 9224) live;
+// "ipv4.json" and "ipv6.json" map address blocks to RIR RDAP servers.
+const ianaBootstrap = "https://data.iana.org/rdap/"
+
+// rdapClient resolves an address to its RIR through the bootstrap registry
+// and queries that RIR's RDAP service. Each registry file is fetched once and
+// kept for the life of the process.
+type rdapClient struct {
+	http      *http.Client
+	bootstrap string // base URL of the bootstrap files
+	// wait, when set, is called with the host before every request.
+	wait func(host string)
+
+	mu       sync.Mutex
+	services map[string][]rdapService // "ipv4"/"ipv6" → parsed registry
+}
+
+// rdapService is one bootstrap entry: address blocks and their servers.
+type rdapService struct {
+	prefixes []netip.Prefix
+	urls     []string
+}
+
+var defaultRDAP = &rdapClient{
+	http:      &http.Client{Timeout: 10 * time.Second},
+	bootstrap: ianaBootstrap,
+}
+
+// LookupRDAP queries the Registration Data Access Protocol service of the
+// registry responsible for ip and maps the network's CIDR (or address range),
+// name (or handle), origin AS where the registry reports one, and registrant
+// into a Result. Unlike Lookup it needs no external binary and reports
+// failures as an error.
+func LookupRDAP(ip string) (Result, error) {
+	return defaultRDAP.lookup(ip)
+}
+
+func (c *rdapClient) lookup(ip string) (Result, error) {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return Result{}, err
+	}
+	base, err := c.server(addr)
+	if err != nil {
+		return Result{}, err
+	}
+	var nw rdapNetwork
+	if err := c.getJSON(base+"ip/"+addr.String(), &nw); err != nil {
+		return Result{}, err
+	}
+	return nw.result(), nil
+}
+
+// server returns the RDAP base URL (with a trailing slash) for addr, taken
+// from the most specific bootstrap block containing it. HTTPS is preferred.
+func (c *rdapClient) server(addr netip.Addr) (string, error) {
+	family := "ipv6"
+	if addr.Is4() || addr.Is4In6() {
+		family, addr = "ipv4", addr.Unmap()
+	}
+	services, err := c.registry(family)
+	if err != nil {
+		return "", err
+	}
+	best, bestBits := "", -1
+	for _, s := range services {
+		for _, p := range s.prefixes {
+			if p.Bits() > bestBits && p.Contains(addr) && len(s.urls) > 0 {
+				best, bestBits = s.urls[0], p.Bits()
+				for _, u := range s.urls {
+					if strings.HasPrefix(u, "https://") {
+						best = u
+						break
+					}
+				}
+			}
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("rdap: no registry for %s", addr)
+	}
+	if !strings.HasSuffix(best, "/") {
+		best += "/"
+	}
+	return best, nil
+}
+
+// registry returns the parsed bootstrap file for family, fetching it on
+// first use. A failed fetch is retried on the next call.
+func (c *rdapClient) registry(family string) ([]rdapService, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if s, ok := c.services[family]; ok {
+		return s, nil
+	}
+	var raw struct {
+		Services [][][]string `json:"services"`
+	}
+	if err := c.getJSON(c.bootstrap+family+".json", &raw); err != nil {
+		return nil, err
+	}
+	var services []rdapService
+	for _, entry := range raw.Services {
+		if len(entry) != 2 {
+			continue
+		}
+		var s rdapService
+		for _, cidr := range entry[0] {
+			if p, err := netip.ParsePrefix(cidr); err == nil {
+				s.prefixes = append(s.prefixes, p)
+			}
+		}
+		s.urls = entry[1]
+		services = append(services, s)
+	}
+	if c.services == nil {
+		c.services = make(map[string][]rdapService)
+	}
+	c.services[family] = services
+	return services, nil
+}
+
+// getJSON fetches url and decodes the JSON body into v.
+func (c *rdapClient) getJSON(url string, v any) error {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/rdap+json, application/json")
+	if c.wait != nil {
+		c.wait(req.URL.Host)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("rdap: %s: %s", url, resp.Status)
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// rdapNetwork is the subset of an RDAP IP network object (RFC 9083) that
+// maps onto Result.
+type rdapNetwork struct {
+	Handle       string `json:"handle"`
+	Name         string `json:"name"`
+	StartAddress string `json:"startAddress"`
+	EndAddress   string `json:"endAddress"`
+	CIDRs        []struct {
+		V4Prefix string `json:"v4prefix"`
+		V6Prefix string `json:"v6prefix"`
+		Length   int    `json:"length"`
+	} `json:"cidr0_cidrs"`
+	// OriginASNs is ARIN's extension; other registries omit it.
+	OriginASNs []int64      `json:"arin_originas0_originautnums"`
+	Entities   []rdapEntity `json:"entities"`
+}
+
+type rdapEntity struct {
+	Roles      []string          `json:"roles"`
+	VCardArray []json.RawMessage `json:"vcardArray"`
+	Entities   []rdapEntity      `json:"entities"`
+}
+
+// result maps n onto the whois fields.
+func (n rdapNetwork) result() Result {
+	var r Result
+	if len(n.CIDRs) > 0 {
+		c := n.CIDRs[0]
+		r.Subnet = fmt.Sprintf("%s%s/%d", c.V4Prefix, c.V6Prefix, c.Length)
+	} else if n.StartAddress != "" {
+		r.Subnet = n.StartAddress + " - " + n.EndAddress
+	}
+	r.NetName = n.Name
+	if r.NetName == "" {
+		r.NetName = n.Handle
+	}
+	if len(n.OriginASNs) > 0 {
+		r.ASN = fmt.Sprintf("AS%d", n.OriginASNs[0])
+	}
+	r.Org = registrant(n.Entities)
+	return r
+}
+
+// registrant returns the formatted name of the first registrant entity,
+// searching nested entities too, or "" if there is none.
+func registrant(entities []rdapEntity) string {
+	for _, e := range entities {
+		for _, role := range e.Roles {
+			if role == "registrant" {
+				if fn, err := e.fn(); err == nil && fn != "" {
+					return fn
+				}
+			}
+		}
+		if fn := registrant(e.Entities); fn != "" {
+			return fn
+		}
+	}
+	return ""
+}
+
+// fn extracts the "fn" (formatted name) property from a jCard (RFC 7095):
+// ["vcard", [["fn", {}, "text", "Example Org"], ...]].
+func (e rdapEntity) fn() (string, error) {
+	if len(e.VCardArray) != 2 {
+		return "", errors.New("rdap: malformed vcardArray")
+	}
+	var props [][]json.RawMessage
+	if err := json.Unmarshal(e.VCardArray[1], &props); err != nil {
+		return "", err
+	}
+	for _, p := range props {
+		var name string
+		if len(p) < 4 || json.Unmarshal(p[0], &name) != nil || name != "fn" {
+			continue
+		}
+		var value string
+		if err := json.Unmarshal(p[3], &value); err != nil {
+			return "", err
+		}
+		return value, nil
+	}
+	return "", nil
+}
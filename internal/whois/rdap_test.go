@@ -0,0 +1,59 @@
+package whois
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+const rdapNetworkJSON = `{
+  "objectClassName": "ip network",
+  "handle": "NET-203-0-113-0-1",
+  "name": "EXAMPLE-ARIN",
+  "startAddress": "203.0.113.0",
+  "endAddress": "203.0.113.255",
+  "cidr0_cidrs": [{"v4prefix": "203.0.113.0", "length": 24}],
+  "arin_originas0_originautnums": [64500],
+  "entities": [
+    {"roles": ["abuse"], "vcardArray": ["vcard", [["fn", {}, "text", "Abuse Desk"]]]},
+    {"roles": ["registrant"], "vcardArray": ["vcard", [["version", {}, "text", "4.0"], ["fn", {}, "text", "Example Networks, Inc."]]]}
+  ]
+}`
+
+func TestLookupRDAP(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bootstrap/ipv4.json":
+			fmt.Fprintf(w, `{"services": [
+				[["203.0.0.0/8"], ["http://wrong.example/"]],
+				[["203.0.113.0/24"], ["%s/rdap/"]]
+			]}`, srv.URL)
+		case "/rdap/ip/203.0.113.5":
+			fmt.Fprint(w, rdapNetworkJSON)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c := &rdapClient{http: srv.Client(), bootstrap: srv.URL + "/bootstrap/"}
+	got, err := c.lookup("203.0.113.5")
+	if err != nil {
+		t.Fatalf("lookup: %v", err)
+	}
+	want := Result{
+		Subnet:  "203.0.113.0/24",
+		NetName: "EXAMPLE-ARIN",
+		ASN:     "AS64500",
+		Org:     "Example Networks, Inc.",
+	}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := c.lookup("198.51.100.1"); err == nil {
+		t.Error("expected an error for an address outside every bootstrap block")
+	}
+}
@@ -0,0 +1,21 @@
+package whois
+
+import (
+	"context"
+	"net"
+	"strings"
+	"time"
+)
+
+// ReverseDNS returns the first PTR hostname for ip, without the trailing dot.
+// It gives up after 3 seconds and returns "" on any failure, including
+// NXDOMAIN — callers treat "" as "no name to show".
+func ReverseDNS(ip string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	names, err := net.DefaultResolver.LookupAddr(ctx, ip)
+	if err != nil || len(names) == 0 {
+		return ""
+	}
+	return strings.TrimSuffix(names[0], ".")
+}
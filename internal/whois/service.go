@@ -0,0 +1,90 @@
+package whois
+
+import (
+	"sync"
+	"time"
+)
+
+// Limits applied by a Service.
+const (
+	// MaxConcurrent is how many lookups may run at once.
+	MaxConcurrent = 3
+	// HostInterval is the minimum gap between two queries to the same
+	// server, so bursts of lookups do not trip registry rate limits.
+	HostInterval = time.Second
+)
+
+// defaultHost keys queries sent to whichever server the whois binary picks
+// by itself.
+const defaultHost = "(default)"
+
+// Service runs whois or RDAP lookups with bounded concurrency and per-server
+// pacing. Callers block in Lookup until a slot is free and the server may be
+// queried again. It is safe for concurrent use.
+type Service struct {
+	slots chan struct{}
+	gap   time.Duration
+	rdap  *rdapClient // nil unless RDAP is preferred
+	// referrals makes whois lookups follow a registry's referral to the
+	// server it names.
+	referrals bool
+
+	mu   sync.Mutex
+	next map[string]time.Time // host → earliest time of its next query
+}
+
+// NewService returns a Service limited to MaxConcurrent lookups and one query
+// per HostInterval to each server. With preferRDAP, LookupRDAP is tried first
+// and the whois binary is used only when it fails or returns nothing. With
+// followReferrals, whois referrals are followed as described at Lookup.
+func NewService(preferRDAP, followReferrals bool) *Service {
+	s := newService(MaxConcurrent, HostInterval)
+	s.referrals = followReferrals
+	if preferRDAP {
+		s.rdap = &rdapClient{http: defaultRDAP.http, bootstrap: ianaBootstrap, wait: s.wait}
+	}
+	return s
+}
+
+func newService(concurrent int, gap time.Duration) *Service {
+	return &Service{
+		slots: make(chan struct{}, concurrent),
+		gap:   gap,
+		next:  make(map[string]time.Time),
+	}
+}
+
+// Lookup returns the registration data for ip like the package-level Lookup,
+// waiting for a free slot first.
+func (s *Service) Lookup(ip string) Result {
+	s.slots <- struct{}{}
+	defer func() { <-s.slots }()
+
+	if s.rdap != nil {
+		if res, err := s.rdap.lookup(ip); err == nil && res != (Result{}) {
+			return res
+		}
+	}
+	return lookupWith(ip, s.referrals, func(ip, host string) (string, error) {
+		if host == "" {
+			s.wait(defaultHost)
+		} else {
+			s.wait(host)
+		}
+		return query(ip, host)
+	})
+}
+
+// wait blocks until host may be queried, reserving the following slot for
+// the next caller so concurrent waiters are spaced gap apart.
+func (s *Service) wait(host string) {
+	s.mu.Lock()
+	now := time.Now()
+	at := s.next[host]
+	if at.Before(now) {
+		at = now
+	}
+	s.next[host] = at.Add(s.gap)
+	s.mu.Unlock()
+	time.Sleep(time.Until(at))
+}
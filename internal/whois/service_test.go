@@ -0,0 +1,33 @@
+package whois
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestServiceWaitSpacesQueriesPerHost(t *testing.T) {
+	const gap = 40 * time.Millisecond
+	s := newService(MaxConcurrent, gap)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range 3 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.wait("whois.arin.net")
+		}()
+	}
+	wg.Wait()
+	if elapsed := time.Since(start); elapsed < 2*gap {
+		t.Errorf("3 queries to one host took %v, want at least %v", elapsed, 2*gap)
+	}
+
+	// Another server is not held back by the first one's schedule.
+	start = time.Now()
+	s.wait("whois.ripe.net")
+	if elapsed := time.Since(start); elapsed >= gap {
+		t.Errorf("first query to a new host waited %v", elapsed)
+	}
+}
@@ -3,6 +3,7 @@ package whois
 import (
 	"context"
 	"os/exec"
+	"regexp"
 	"strings"
 	"time"
 )
@@ -16,37 +17,123 @@ type Result struct {
 }
 
 // Lookup runs `whois <ip>` with a 10-second timeout and returns parsed fields.
-// Returns an empty Result if whois is not installed or produces no useful
-// output — callers treat an all-empty Result as "nothing to show".
-func Lookup(ip string) Result {
+// With followReferrals, when the registry answers with a referral to another
+// whois server (ARIN's ReferralServer, IANA/APNIC's refer) and the first
+// answer is missing fields, a second query is issued to the referred server
+// and its values take precedence. Returns an empty Result if whois is not
+// installed or produces no useful output — callers treat an all-empty Result
+// as "nothing to show".
+func Lookup(ip string, followReferrals bool) Result {
+	return lookupWith(ip, followReferrals, query)
+}
+
+// lookupWith is Lookup with the whois invocation supplied by the caller, so a
+// Service can pace queries per server.
+func lookupWith(ip string, followReferrals bool, query func(ip, host string) (string, error)) Result {
+	out, err := query(ip, "")
+	if err != nil {
+		return Result{}
+	}
+	res := parse(out)
+	if res.complete() || !followReferrals {
+		return res
+	}
+	if host := referral(out); host != "" {
+		if out2, err := query(ip, host); err == nil {
+			res = merge(parse(out2), res)
+		}
+	}
+	return res
+}
+
+// query runs whois for ip, optionally against a specific server host.
+func query(ip, host string) (string, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
-	out, err := exec.CommandContext(ctx, "whois", ip).Output()
+	args := []string{ip}
+	if host != "" {
+		args = []string{"-h", host, ip}
+	}
+	out, err := exec.CommandContext(ctx, "whois", args...).Output()
 	if err != nil {
-		return Result{}
+		return "", err
 	}
-	return parse(string(out))
+	return string(out), nil
 }
 
 // parse extracts known fields from whois output, tolerating differences between
-// RIPE, ARIN, APNIC, LACNIC, and AFRINIC formats.
+// RIPE, ARIN, APNIC, LACNIC, and AFRINIC formats. Keys are listed in order of
+// preference: a later key is only consulted when no earlier key has a value,
+// so e.g. RIPE's "org:" handle never shadows the "org-name:" further down,
+// and the free-form "descr:" is used only as a last resort.
 func parse(output string) Result {
 	lines := strings.Split(output, "\n")
 	return Result{
-		Subnet:  firstMatch(lines, "inetnum", "NetRange", "CIDR"),
-		NetName: firstMatch(lines, "netname", "NetName"),
-		ASN:     firstMatch(lines, "aut-num", "OriginAS"),
-		Org:     firstMatch(lines, "org-name", "OrgName", "Organization", "org"),
+		Subnet:  firstMatch(lines, "inetnum", "inet6num", "NetRange", "CIDR"),
+		NetName: firstMatch(lines, "netname", "NetName", "ownerid"),
+		ASN:     firstMatch(lines, "aut-num", "OriginAS", "origin"),
+		Org:     firstMatch(lines, "org-name", "OrgName", "Organization", "owner", "descr"),
+	}
+}
+
+// complete reports whether every field is populated.
+func (r Result) complete() bool {
+	return r.Subnet != "" && r.NetName != "" && r.ASN != "" && r.Org != ""
+}
+
+// merge returns primary with any empty fields filled from fallback.
+func merge(primary, fallback Result) Result {
+	if primary.Subnet == "" {
+		primary.Subnet = fallback.Subnet
+	}
+	if primary.NetName == "" {
+		primary.NetName = fallback.NetName
+	}
+	if primary.ASN == "" {
+		primary.ASN = fallback.ASN
+	}
+	if primary.Org == "" {
+		primary.Org = fallback.Org
+	}
+	return primary
+}
+
+// hostRe restricts referral targets to plain hostnames so that registry output
+// can never smuggle extra arguments into the second whois invocation.
+var hostRe = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9.-]*$`)
+
+// referral returns the whois server named by a ReferralServer (ARIN) or refer
+// (IANA, APNIC) line, or "" if there is none. Only whois:// referrals are
+// followed; rwhois and other schemes are ignored.
+func referral(output string) string {
+	lines := strings.Split(output, "\n")
+	v := firstMatch(lines, "ReferralServer", "refer", "whois")
+	if v == "" {
+		return ""
+	}
+	if i := strings.Index(v, "://"); i >= 0 {
+		if !strings.EqualFold(v[:i], "whois") {
+			return ""
+		}
+		v = v[i+3:]
+	}
+	v = strings.TrimSuffix(v, "/")
+	if i := strings.IndexByte(v, ':'); i >= 0 {
+		v = v[:i]
+	}
+	if !hostRe.MatchString(v) {
+		return ""
 	}
+	return v
 }
 
 // firstMatch returns the trimmed value of the first line whose key
-// (case-insensitive) matches one of the supplied keys.
+// (case-insensitive) matches the earliest possible entry in keys.
 // Returns "" if none match or all values are empty.
 func firstMatch(lines []string, keys ...string) string {
-	for _, line := range lines {
-		for _, key := range keys {
-			prefix := strings.ToLower(key) + ":"
+	for _, key := range keys {
+		prefix := strings.ToLower(key) + ":"
+		for _, line := range lines {
 			lower := strings.ToLower(line)
 			if strings.HasPrefix(lower, prefix) {
 				val := strings.TrimSpace(line[len(key)+1:])
@@ -0,0 +1,201 @@
+package whois
+
+import "testing"
+
+const ripeOutput = `% This is the RIPE Database query service.
+% Information related to '198.51.100.0 - 198.51.100.255'
+
+inetnum:        198.51.100.0 - 198.51.100.255
+netname:        EXAMPLE-NET
+descr:          Example hosting customer
+country:        NL
+org:            ORG-EX1-RIPE
+admin-c:        EX1-RIPE
+status:         ASSIGNED PA
+
+organisation:   ORG-EX1-RIPE
+org-name:       Example Hosting B.V.
+org-type:       LIR
+
+% Information related to '198.51.100.0/24AS64496'
+
+route:          198.51.100.0/24
+origin:         AS64496
+`
+
+const arinOutput = `#
+# ARIN WHOIS data and services are subject to the Terms of Use
+#
+
+NetRange:       203.0.113.0 - 203.0.113.255
+CIDR:           203.0.113.0/24
+NetName:        EXAMPLE-ARIN
+OriginAS:       AS64500
+Organization:   Example Networks, Inc. (EXNET)
+
+OrgName:        Example Networks, Inc.
+OrgId:          EXNET
+`
+
+const arinReferralOutput = `NetRange:       192.0.2.0 - 192.0.2.255
+CIDR:           192.0.2.0/24
+NetName:        RIPE-CIDR-BLOCK
+OriginAS:
+OrgName:        RIPE Network Coordination Centre
+ReferralServer:  whois://whois.ripe.net
+`
+
+const apnicOutput = `% [whois.apnic.net]
+% Whois data copyright terms    http://www.apnic.net/db/dbcopyright.html
+
+inetnum:        100.64.10.0 - 100.64.10.255
+netname:        EXAMPLE-AP
+descr:          Example Telecom Ltd
+descr:          Tokyo
+country:        JP
+
+route:          100.64.10.0/24
+origin:         AS64510
+descr:          Example Telecom route
+`
+
+const lacnicOutput = `% Joint Whois - whois.lacnic.net
+
+inetnum:     200.160.0.0/20
+status:      allocated
+aut-num:     AS64520
+owner:       Empresa Exemplo Ltda
+ownerid:     BR-EXEM-LACNIC
+responsible: Fulano de Tal
+country:     BR
+`
+
+const afrinicOutput = `% This is the AfriNIC Whois server.
+
+inetnum:        196.0.0.0 - 196.0.0.255
+netname:        EXAMPLE-AF
+descr:          Example Internet Services
+country:        ZA
+org:            ORG-EIS1-AFRINIC
+status:         ASSIGNED PA
+
+organisation:   ORG-EIS1-AFRINIC
+org-name:       Example Internet Services (Pty) Ltd
+`
+
+func TestParseRIRFormats(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   Result
+	}{
+		{
+			name:   "ripe prefers org-name over org handle",
+			output: ripeOutput,
+			want: Result{
+				Subnet:  "198.51.100.0 - 198.51.100.255",
+				NetName: "EXAMPLE-NET",
+				ASN:     "AS64496",
+				Org:     "Example Hosting B.V.",
+			},
+		},
+		{
+			name:   "arin",
+			output: arinOutput,
+			want: Result{
+				Subnet:  "203.0.113.0 - 203.0.113.255",
+				NetName: "EXAMPLE-ARIN",
+				ASN:     "AS64500",
+				Org:     "Example Networks, Inc.",
+			},
+		},
+		{
+			name:   "apnic falls back to descr for org",
+			output: apnicOutput,
+			want: Result{
+				Subnet:  "100.64.10.0 - 100.64.10.255",
+				NetName: "EXAMPLE-AP",
+				ASN:     "AS64510",
+				Org:     "Example Telecom Ltd",
+			},
+		},
+		{
+			name:   "lacnic owner and ownerid",
+			output: lacnicOutput,
+			want: Result{
+				Subnet:  "200.160.0.0/20",
+				NetName: "BR-EXEM-LACNIC",
+				ASN:     "AS64520",
+				Org:     "Empresa Exemplo Ltda",
+			},
+		},
+		{
+			name:   "afrinic",
+			output: afrinicOutput,
+			want: Result{
+				Subnet:  "196.0.0.0 - 196.0.0.255",
+				NetName: "EXAMPLE-AF",
+				Org:     "Example Internet Services (Pty) Ltd",
+			},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := parse(tc.output); got != tc.want {
+				t.Errorf("parse:\n got  %+v\n want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestReferral(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   string
+	}{
+		{"arin whois referral", arinReferralOutput, "whois.ripe.net"},
+		{"iana refer", "refer:        whois.apnic.net\n", "whois.apnic.net"},
+		{"port is dropped", "ReferralServer: whois://whois.example.net:43\n", "whois.example.net"},
+		{"rwhois ignored", "ReferralServer: rwhois://rwhois.example.net:4321\n", ""},
+		{"option-like host rejected", "refer: -hwhois.evil.example\n", ""},
+		{"none", arinOutput, ""},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := referral(tc.output); got != tc.want {
+				t.Errorf("referral: got %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMergeReferredOverridesFallback(t *testing.T) {
+	got := merge(parse(ripeOutput), parse(arinReferralOutput))
+	if got.Org != "Example Hosting B.V." {
+		t.Errorf("Org: got %q, want referred value", got.Org)
+	}
+	if got.ASN != "AS64496" {
+		t.Errorf("ASN: got %q, want %q", got.ASN, "AS64496")
+	}
+}
+
+func TestLookupFollowsReferralsOnlyWhenAsked(t *testing.T) {
+	for _, follow := range []bool{false, true} {
+		var hosts []string
+		res := lookupWith("198.51.100.7", follow, func(_, host string) (string, error) {
+			hosts = append(hosts, host)
+			if host == "whois.ripe.net" {
+				return ripeOutput, nil
+			}
+			return arinReferralOutput, nil
+		})
+		wantHosts, wantOrg := 1, parse(arinReferralOutput).Org
+		if follow {
+			wantHosts, wantOrg = 2, "Example Hosting B.V."
+		}
+		if len(hosts) != wantHosts || res.Org != wantOrg {
+			t.Errorf("follow=%v: queried %q, Org %q; want %d queries, Org %q", follow, hosts, res.Org, wantHosts, wantOrg)
+		}
+	}
+}
@@ -4,112 +4,492 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"net"
 	"os"
 	"os/exec"
+	"os/user"
 	"path/filepath"
+	"slices"
+	"strings"
 	"syscall"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/espenotterstad/iptables-log-tui/internal/alerts"
 	"github.com/espenotterstad/iptables-log-tui/internal/classifier"
+	"github.com/espenotterstad/iptables-log-tui/internal/config"
+	"github.com/espenotterstad/iptables-log-tui/internal/geoip"
+	"github.com/espenotterstad/iptables-log-tui/internal/keymap"
+	"github.com/espenotterstad/iptables-log-tui/internal/metrics"
 	"github.com/espenotterstad/iptables-log-tui/internal/model"
+	"github.com/espenotterstad/iptables-log-tui/internal/parser"
+	"github.com/espenotterstad/iptables-log-tui/internal/ports"
 	"github.com/espenotterstad/iptables-log-tui/internal/tailer"
+	"github.com/espenotterstad/iptables-log-tui/internal/ui"
+	"github.com/espenotterstad/iptables-log-tui/internal/whois"
 )
 
-// checkAndElevate re-execs the binary under sudo if the log file is
+// checkAndElevate re-execs the binary under sudo if any of the log files is
 // unreadable due to permissions. It is a no-op if already running as root
-// or if the error is not permission-related.
-func checkAndElevate(logFile string, history bool) {
+// or if no error is permission-related.
+func checkAndElevate(logFiles []string) {
 	if os.Getuid() == 0 {
 		return
 	}
-	f, err := os.Open(logFile)
-	if err == nil {
-		f.Close()
-		return
+	denied := ""
+	for _, logFile := range logFiles {
+		f, err := os.Open(logFile)
+		if err == nil {
+			f.Close()
+			continue
+		}
+		if errors.Is(err, os.ErrPermission) {
+			denied = logFile
+			break
+		}
 	}
-	if !errors.Is(err, os.ErrPermission) {
+	if denied == "" {
 		return
 	}
 	sudoPath, lookErr := exec.LookPath("sudo")
 	if lookErr != nil {
 		fmt.Fprintf(os.Stderr,
 			"iptables-log-tui: permission denied reading %s\n"+
-				"  Fix: sudo usermod -aG adm $USER  (then log out/in)\n", logFile)
+				"  Fix: sudo usermod -aG adm $USER  (then log out/in)\n", denied)
 		os.Exit(1)
 	}
 	// Resolve symlinks immediately after the permission check so the elevated
-	// process opens the same inode, closing the TOCTOU race window. If
+	// process opens the same inodes, closing the TOCTOU race window. If
 	// resolution fails we fall back to the original path.
-	if resolved, resolveErr := filepath.EvalSymlinks(logFile); resolveErr == nil {
-		logFile = resolved
-	}
-	fmt.Fprintf(os.Stderr, "iptables-log-tui: permission denied reading %s — re-running with sudo\n", logFile)
-	// Build args explicitly from known values rather than forwarding os.Args,
-	// so the resolved path is what sudo receives.
-	args := []string{sudoPath, os.Args[0], "--file=" + logFile}
-	if history {
-		args = append(args, "--history")
+	resolved := make([]string, len(logFiles))
+	for i, logFile := range logFiles {
+		resolved[i] = logFile
+		if r, resolveErr := filepath.EvalSymlinks(logFile); resolveErr == nil {
+			resolved[i] = r
+		}
 	}
+	fmt.Fprintf(os.Stderr, "iptables-log-tui: permission denied reading %s — re-running with sudo\n", denied)
+	// Build args explicitly from the parsed flags rather than forwarding
+	// os.Args, so the resolved paths are what sudo receives.
+	args := []string{sudoPath, os.Args[0], "--file=" + strings.Join(resolved, ",")}
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name != "file" {
+			args = append(args, "--"+f.Name+"="+f.Value.String())
+		}
+	})
 	if execErr := syscall.Exec(sudoPath, args, os.Environ()); execErr != nil {
 		fmt.Fprintf(os.Stderr, "iptables-log-tui: exec sudo: %v\n", execErr)
 		os.Exit(1)
 	}
 }
 
-// resolveLogFile returns path if non-empty. Otherwise it probes the
+// loadConfig reads the config file at path, or at the default location when
+// path is empty (where a missing file is fine), and applies its settings to
+// every flag not given on the command line. It returns the key bindings with
+// the file's [keys] applied, and exits on any error, including two actions
+// bound to the same key.
+func loadConfig(path string) keymap.Keymap {
+	explicit := path != ""
+	if !explicit {
+		var err error
+		if path, err = config.DefaultPath(); err != nil {
+			return keymap.Default()
+		}
+	}
+	cfg, err := config.Load(path)
+	if errors.Is(err, os.ErrNotExist) && !explicit {
+		return keymap.Default()
+	}
+	var keys keymap.Keymap
+	if err == nil {
+		err = cfg.ApplyFlags(flag.CommandLine)
+	}
+	if err == nil {
+		if keys, err = keymap.New(cfg.Keys); err != nil {
+			err = fmt.Errorf("%s: [keys]: %v", path, err)
+		}
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "iptables-log-tui: config: %v\n", err)
+		os.Exit(1)
+	}
+	// Record the path as if given on the command line, so a sudo re-exec
+	// (whose home directory differs) reads the same file.
+	_ = flag.Set("config", path)
+	return keys
+}
+
+// fileList is a repeatable --file flag that also accepts comma-separated
+// paths.
+type fileList []string
+
+func (f *fileList) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *fileList) Set(v string) error {
+	for _, p := range strings.Split(v, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			*f = append(*f, p)
+		}
+	}
+	return nil
+}
+
+// tabFlag is the --tab flag, checked with model.ParseTab when set so a typo
+// in the flag or the config file is reported like any other bad value.
+type tabFlag string
+
+func (t *tabFlag) String() string {
+	return string(*t)
+}
+
+func (t *tabFlag) Set(v string) error {
+	if _, err := model.ParseTab(v); err != nil {
+		return err
+	}
+	*t = tabFlag(v)
+	return nil
+}
+
+// index returns the tab to start on.
+func (t tabFlag) index() int {
+	i, _ := model.ParseTab(string(t))
+	return i
+}
+
+// stdinPath is the --file value that selects standard input.
+const stdinPath = "-"
+
+// checkStdin exits with a message if stdin is a terminal, since reading log
+// lines from it only makes sense when something is piped in.
+func checkStdin() {
+	fi, err := os.Stdin.Stat()
+	if err == nil && fi.Mode()&os.ModeCharDevice != 0 {
+		fmt.Fprintf(os.Stderr,
+			"iptables-log-tui: --file=- reads from stdin, but stdin is a terminal\n"+
+				"  Pipe log lines in, e.g.: journalctl -k -f | iptables-log-tui --file=-\n")
+		os.Exit(1)
+	}
+}
+
+// journalSource is the source name used for --journald in place of a path.
+const journalSource = "journalctl -k"
+
+// journalGroups are the groups whose members may read the system journal,
+// kernel messages included, without root.
+var journalGroups = []string{"systemd-journal", "adm", "wheel"}
+
+// checkJournal exits with a message if journalctl is missing, or if the
+// current user is neither root nor in one of journalGroups, since journalctl
+// would then silently show no kernel messages. Elevation is left to the user.
+func checkJournal() {
+	if _, err := exec.LookPath("journalctl"); err != nil {
+		fmt.Fprintf(os.Stderr, "iptables-log-tui: --journald needs journalctl, which is not in PATH\n")
+		os.Exit(1)
+	}
+	if os.Getuid() == 0 {
+		return
+	}
+	if u, err := user.Current(); err == nil {
+		gids, _ := u.GroupIds()
+		for _, gid := range gids {
+			if g, err := user.LookupGroupId(gid); err == nil && slices.Contains(journalGroups, g.Name) {
+				return
+			}
+		}
+	}
+	fmt.Fprintf(os.Stderr,
+		"iptables-log-tui: --journald cannot read kernel messages as this user\n"+
+			"  Fix: sudo usermod -aG systemd-journal $USER  (then log out/in), or run with sudo\n")
+	os.Exit(1)
+}
+
+// journalArgs returns the journalctl arguments for --journald: kernel
+// messages, followed, in the short-iso format the parser understands. With
+// history the whole current boot is replayed first, otherwise only new
+// messages are shown.
+func journalArgs(history bool) []string {
+	args := []string{"-k", "-f", "-o", "short-iso", "-q"}
+	if history {
+		return append(args, "--no-tail")
+	}
+	return append(args, "-n", "0")
+}
+
+// resolveLogFiles returns paths if non-empty. Otherwise it probes the
 // well-known default locations in order and returns the first one found.
-// It exits with a message if no file can be located.
-func resolveLogFile(path string) string {
-	if path != "" {
-		return path
+// It exits with a message if no file can be located, or if stdin is mixed
+// with other files.
+func resolveLogFiles(paths []string) []string {
+	if len(paths) > 1 && slices.Contains(paths, stdinPath) {
+		fmt.Fprintf(os.Stderr, "iptables-log-tui: --file=- (stdin) cannot be combined with other files\n")
+		os.Exit(1)
+	}
+	if len(paths) > 0 {
+		return paths
 	}
 	for _, candidate := range []string{"/var/log/ufw.log", "/var/log/iptables.log"} {
 		if _, err := os.Stat(candidate); err == nil {
-			return candidate
+			return []string{candidate}
 		}
 	}
 	fmt.Fprintf(os.Stderr,
 		"iptables-log-tui: no log file found (tried /var/log/ufw.log, /var/log/iptables.log)\n"+
 			"  Use --file to specify a path.\n")
 	os.Exit(1)
-	return "" // unreachable
+	return nil // unreachable
+}
+
+// effectivePath returns the absolute, symlink-resolved form of path, falling
+// back to the cleanest form available if resolution fails.
+func effectivePath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		path = resolved
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return path
+}
+
+// forward relays lines, the missing-file notice, skipped rotated files and
+// the first fatal error from t to the program, tagging each with source.
+func forward(p *tea.Program, t *tailer.Tailer, source string) {
+	for {
+		select {
+		case line, ok := <-t.Lines:
+			if !ok {
+				return
+			}
+			p.Send(model.NewLineMsg{Line: line, Source: source})
+		case err, ok := <-t.Errors:
+			if !ok {
+				return
+			}
+			p.Send(model.TailerErrMsg{Err: err})
+			return
+		case <-t.Waiting:
+			p.Send(model.TailerWaitingMsg{Source: source})
+		case err := <-t.Skipped:
+			p.Send(model.TailerSkippedMsg{Err: err})
+		}
+	}
 }
 
 func main() {
-	logFile := flag.String("file", "", "path to the log file (default: auto-detect /var/log/ufw.log or /var/log/iptables.log)")
+	var logFiles fileList
+	flag.Var(&logFiles, "file", "path to a log file; repeat or comma-separate to watch several (default: auto-detect /var/log/ufw.log or /var/log/iptables.log)")
 	history := flag.Bool("history", false, "read file from the beginning (include historical entries)")
+	startTab := tabFlag("logs")
+	flag.Var(&startTab, "tab", "tab to start on: logs, stats, filters, conns or raw")
+	journald := flag.Bool("journald", false, "read kernel messages from the systemd journal via journalctl instead of a file (--file is ignored)")
+	whoisTTL := flag.Duration("whois-ttl", whois.DefaultCacheTTL, "how long cached whois results stay fresh (0 disables the on-disk cache)")
+	rdap := flag.Bool("rdap", false, "look up network owners over RDAP (HTTPS), falling back to the whois binary on error")
+	whoisReferrals := flag.Bool("whois-referrals", false, "follow a whois registry's referral to the server it names when the first answer is incomplete")
+	themeFile := flag.String("theme", "", "path to a JSON color theme (default: built-in dark palette)")
+	maxEntries := flag.Int("max-entries", 50000, "keep at most this many entries for the table, dropping the oldest (0 = unbounded)")
+	alertRate := flag.Int("alert-rate", 60, "flag source IPs logging more than this many events per minute (0 disables)")
+	offenderTTL := flag.Duration("offender-ttl", alerts.DefaultOffenderTTL, "how long sources flagged by --alert-rate are remembered across sessions (0 disables the watchlist)")
+	servicesFile := flag.String("services", "", "extra /etc/services-format file whose port names override the built-in ones")
+	blocklistFile := flag.String("blocklist", "", "file of known-bad CIDRs, one per line; matching sources are marked in the table")
+	geoipFile := flag.String("geoip", "", "path to a MaxMind .mmdb database for country/city lookups (default: disabled)")
+	pollInterval := flag.Duration("poll-interval", tailer.DefaultPollInterval, "how often to check the log file when inotify is unavailable (minimum 50ms)")
+	dropsOnly := flag.Bool("drops-only", false, "start with the action filter set to DROP and REJECT (clear it with c)")
+	anonymize := flag.Bool("anonymize", false, "mask the host part of IP addresses on screen and in exports (toggle at runtime with M)")
+	lineFormat := flag.String("format", "", "regex with named groups (src, dst required; ts, prefix, in, out, proto, spt, dpt optional) for lines in a custom format")
+	metricsAddr := flag.String("metrics-addr", "", "serve Prometheus metrics on this address at /metrics, e.g. :9100 (default: disabled)")
+	configFile := flag.String("config", "", "path to a TOML file with flag defaults and key bindings (default: $XDG_CONFIG_HOME/iptables-log-tui/config.toml if it exists)")
 	flag.Parse()
-	file := resolveLogFile(*logFile)
-	checkAndElevate(file, *history)
+	keys := loadConfig(*configFile)
+
+	// Piped or redirected output (e.g. | tee) should not carry escape codes.
+	if fi, err := os.Stdout.Stat(); err == nil && fi.Mode()&os.ModeCharDevice == 0 {
+		ui.ForcePlain()
+	}
+
+	// Check the theme before any sudo prompt so a typo fails fast.
+	theme := ui.DefaultTheme()
+	if *themeFile != "" {
+		var err error
+		if theme, err = ui.LoadTheme(*themeFile); err != nil {
+			fmt.Fprintf(os.Stderr, "iptables-log-tui: %v\n", err)
+			os.Exit(1)
+		}
+		ui.ApplyTheme(theme)
+	}
+	if *lineFormat != "" {
+		if err := parser.UseCustomFormat(*lineFormat); err != nil {
+			fmt.Fprintf(os.Stderr, "iptables-log-tui: --format: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	files := []string{journalSource}
+	if !*journald {
+		files = resolveLogFiles(logFiles)
+	}
+	// Built up front so a bad --poll-interval fails before any sudo prompt.
+	tails := make([]*tailer.Tailer, len(files))
+	for i := range files {
+		var err error
+		if tails[i], err = tailer.NewWithInterval(*pollInterval); err != nil {
+			fmt.Fprintf(os.Stderr, "iptables-log-tui: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	fromStdin := files[0] == stdinPath
+	switch {
+	case *journald:
+		// journalctl does its own access checks; there is no file to
+		// re-exec for, so only explain how to get access.
+		checkJournal()
+	case fromStdin:
+		// Nothing to elevate for, and --history has no meaning for a pipe.
+		checkStdin()
+	default:
+		checkAndElevate(files)
+	}
+
+	// A missing or unreadable cache just means every lookup goes to the
+	// network; it is rewritten on exit either way.
+	var whoisCache *whois.Cache
+	if *whoisTTL > 0 {
+		if path, err := whois.DefaultCachePath(); err == nil {
+			whoisCache, _ = whois.LoadCache(path, *whoisTTL)
+		}
+	}
+
+	// Sources flagged in earlier sessions; like the whois cache, a bad file
+	// only means starting afresh, and it is rewritten on exit.
+	var watchlist *alerts.Watchlist
+	if *offenderTTL > 0 && *alertRate > 0 {
+		if path, err := alerts.DefaultWatchlistPath(); err == nil {
+			watchlist, _ = alerts.LoadWatchlist(path, *offenderTTL)
+		}
+	}
+
+	// Port names: embedded IANA list, then the system's /etc/services, then
+	// the user's own file, each layer overriding the one before.
+	_ = ports.LoadExtra("/etc/services")
+	if *servicesFile != "" {
+		if err := ports.LoadExtra(*servicesFile); err != nil {
+			fmt.Fprintf(os.Stderr, "iptables-log-tui: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Filter presets are saved next to the config file. Without a config
+	// directory they can still be listed from nowhere, just not saved.
+	presetsPath, _ := ui.DefaultPresetsPath()
+	var presets []ui.Preset
+	if presetsPath != "" {
+		var err error
+		if presets, err = ui.LoadPresets(presetsPath); err != nil {
+			fmt.Fprintf(os.Stderr, "iptables-log-tui: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var badNets *classifier.Blocklist
+	if *blocklistFile != "" {
+		var err error
+		if badNets, err = classifier.LoadBlocklist(*blocklistFile); err != nil {
+			fmt.Fprintf(os.Stderr, "iptables-log-tui: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	var geoDB *geoip.DB
+	if *geoipFile != "" {
+		var err error
+		if geoDB, err = geoip.Open(*geoipFile); err != nil {
+			fmt.Fprintf(os.Stderr, "iptables-log-tui: opening GeoIP database: %v\n", err)
+			os.Exit(1)
+		}
+		defer geoDB.Close()
+	}
+
+	// Bind now so a busy port fails before the TUI takes over the screen.
+	// Serve errors after that have nowhere to go and stop the endpoint only.
+	var met *metrics.Metrics
+	if *metricsAddr != "" {
+		ln, err := net.Listen("tcp", *metricsAddr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "iptables-log-tui: metrics endpoint: %v\n", err)
+			os.Exit(1)
+		}
+		met = metrics.New()
+		go met.Serve(ln)
+	}
 
 	cls := classifier.New()
-	t := tailer.New()
-
-	m := model.New(t, cls.Categorize)
-
-	p := tea.NewProgram(m, tea.WithAltScreen())
-
-	// Start the tailer and forward new lines to the Bubble Tea program.
-	t.Start(file, *history)
-	go func() {
-		for {
-			select {
-			case line, ok := <-t.Lines:
-				if !ok {
-					return
-				}
-				p.Send(model.NewLineMsg(line))
-			case err, ok := <-t.Errors:
-				if !ok {
-					return
-				}
-				p.Send(model.TailerErrMsg{Err: err})
-				return
-			}
+	sources := make([]string, len(files))
+	for i, file := range files {
+		switch {
+		case *journald:
+			sources[i] = journalSource
+		case fromStdin:
+			sources[i] = "(stdin)"
+		default:
+			sources[i] = effectivePath(file)
 		}
-	}()
+	}
+
+	m := model.New(tails, cls.Categorize, model.Options{
+		LogPath:  strings.Join(sources, ", "),
+		Elevated: os.Geteuid() == 0,
+		Sudo:     os.Getenv("SUDO_UID") != "",
+
+		WhoisCache:     whoisCache,
+		RDAP:           *rdap,
+		WhoisReferrals: *whoisReferrals,
+		MaxEntries:     *maxEntries,
+		AlertRate:      *alertRate,
+		Watchlist:      watchlist,
+		Blocklist:      badNets,
+		GeoIP:          geoDB,
+		Theme:          theme,
+		Metrics:        met,
+		Keys:           &keys,
+		Tab:            startTab.index(),
+		Anonymize:      *anonymize,
+		DropsOnly:      *dropsOnly,
+
+		Presets:     presets,
+		PresetsPath: presetsPath,
+	})
+
+	progOpts := []tea.ProgramOption{tea.WithAltScreen(), tea.WithMouseCellMotion()}
+	if fromStdin {
+		// stdin carries log data, so keyboard input must come from the TTY.
+		progOpts = append(progOpts, tea.WithInputTTY())
+	}
+	p := tea.NewProgram(m, progOpts...)
 
-	if _, err := p.Run(); err != nil {
+	// Start the tailers and forward new lines to the Bubble Tea program.
+	for i, t := range tails {
+		switch {
+		case *journald:
+			t.StartCommand("journalctl", journalArgs(*history)...)
+		case fromStdin:
+			t.StartReader(os.Stdin)
+		default:
+			t.Start(files[i], *history)
+		}
+		go forward(p, t, sources[i])
+	}
+
+	_, err := p.Run()
+	if whoisCache != nil {
+		if saveErr := whois.SaveCache(whoisCache); saveErr != nil {
+			fmt.Fprintf(os.Stderr, "iptables-log-tui: saving whois cache: %v\n", saveErr)
+		}
+	}
+	if watchlist != nil {
+		if saveErr := alerts.SaveWatchlist(watchlist); saveErr != nil {
+			fmt.Fprintf(os.Stderr, "iptables-log-tui: saving offender watchlist: %v\n", saveErr)
+		}
+	}
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "iptables-log-tui: %v\n", err)
 		os.Exit(1)
 	}